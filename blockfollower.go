@@ -0,0 +1,265 @@
+package tonapi
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/go-faster/errors"
+	"github.com/tonkeeper/tongo/ton"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// blockFollowerConcurrency is the number of blocks whose transactions are fetched in parallel.
+	blockFollowerConcurrency = 8
+	// blockFollowerMaxBlocks limits the number of shard blocks committed by a single masterchain block,
+	// walking back further means the shard snapshots are inconsistent.
+	blockFollowerMaxBlocks = 10_000
+	// masterchainShard is the shard of masterchain blocks.
+	masterchainShard = 0x8000000000000000
+)
+
+// FollowedBlock is a block delivered by BlockFollower together with its transactions.
+type FollowedBlock struct {
+	ID    ton.BlockID
+	Block BlockchainBlock
+	// MasterchainSeqno is the seqno of the masterchain block that committed this block.
+	MasterchainSeqno uint32
+	Transactions     []Transaction
+}
+
+// BlockFollowerFunc processes a block delivered by BlockFollower.
+type BlockFollowerFunc func(ctx context.Context, block FollowedBlock) error
+
+// BlockFollower delivers every block of the blockchain strictly in order.
+//
+// It follows the masterchain seqno by seqno and expands each masterchain block into the shard blocks
+// it commits: the shard blocks created since the previous masterchain block, in the order of their seqno
+// so a block always comes after the blocks it is built on, followed by the masterchain block itself.
+// Shard blocks missing from GetBlockchainMasterchainBlocks are found by walking back the prev_refs
+// of the shards' last blocks down to the previous masterchain block's shard snapshot.
+// A block is delivered only with all its transactions.
+//
+// The seqno of the last fully delivered masterchain block is kept in a CheckpointStore,
+// see MasterchainConsumer. When the handler fails, delivery resumes from the failed block,
+// a restart resumes from the first block of the masterchain block that was in progress.
+type BlockFollower struct {
+	handler  BlockFollowerFunc
+	consumer *MasterchainConsumer
+	expander *blockExpander
+
+	// pending holds the blocks of a masterchain block whose delivery failed midway.
+	pending      []FollowedBlock
+	pendingSeqno uint32
+}
+
+// NewBlockFollower returns a follower delivering blocks to the handler.
+// The options are the ones of MasterchainConsumer, WithConsumerStart sets the last processed masterchain seqno.
+func NewBlockFollower(client *Client, store CheckpointStore, name string, handler BlockFollowerFunc, opts ...ConsumerOption) *BlockFollower {
+	f := &BlockFollower{handler: handler, expander: newBlockExpander(client)}
+	f.consumer = NewMasterchainConsumer(client, store, name, f.handleMasterchain, opts...)
+	return f
+}
+
+// Run delivers blocks until the context is canceled or the checkpoint store fails.
+func (f *BlockFollower) Run(ctx context.Context) error {
+	return f.consumer.Run(ctx)
+}
+
+func (f *BlockFollower) handleMasterchain(ctx context.Context, seqno uint32) error {
+	if f.pending == nil || f.pendingSeqno != seqno {
+		blocks, err := f.expander.expand(ctx, seqno)
+		if err != nil {
+			return err
+		}
+		f.pending, f.pendingSeqno = blocks, seqno
+	}
+	for len(f.pending) > 0 {
+		if err := f.handler(ctx, f.pending[0]); err != nil {
+			return errors.Wrapf(err, "handle block %v", f.pending[0].ID)
+		}
+		f.pending = f.pending[1:]
+	}
+	f.pending = nil
+	return nil
+}
+
+// blockExpander expands masterchain blocks into the blocks they commit.
+type blockExpander struct {
+	client *Client
+
+	// snapshots caches the shards' last blocks of recent masterchain blocks.
+	mu        sync.Mutex
+	snapshots map[uint32][]ton.BlockID
+}
+
+func newBlockExpander(client *Client) *blockExpander {
+	return &blockExpander{client: client, snapshots: map[uint32][]ton.BlockID{}}
+}
+
+// expand returns the blocks committed by the masterchain block with their transactions,
+// shard blocks ordered by seqno and the masterchain block last.
+func (e *blockExpander) expand(ctx context.Context, seqno uint32) ([]FollowedBlock, error) {
+	previous, err := e.snapshot(ctx, seqno-1)
+	if err != nil {
+		return nil, err
+	}
+	current, err := e.snapshot(ctx, seqno)
+	if err != nil {
+		return nil, err
+	}
+	res, err := e.client.GetBlockchainMasterchainBlocks(ctx, GetBlockchainMasterchainBlocksParams{MasterchainSeqno: int32(seqno)})
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[ton.BlockID]BlockchainBlock, len(res.Blocks))
+	for _, block := range res.Blocks {
+		id, err := blockID(block)
+		if err != nil {
+			return nil, err
+		}
+		known[id] = block
+	}
+	masterchainID := ton.BlockID{Workchain: -1, Shard: masterchainShard, Seqno: seqno}
+	masterchainBlock, ok := known[masterchainID]
+	if !ok {
+		if masterchainBlock, err = e.block(ctx, masterchainID); err != nil {
+			return nil, err
+		}
+	}
+
+	// walk back from the current shard snapshot to the previous one.
+	committed := map[ton.BlockID]struct{}{}
+	for _, id := range previous {
+		committed[id] = struct{}{}
+	}
+	floor := map[int32]uint32{}
+	for _, id := range previous {
+		if s, ok := floor[id.Workchain]; !ok || id.Seqno < s {
+			floor[id.Workchain] = id.Seqno
+		}
+	}
+	var blocks []FollowedBlock
+	visited := map[ton.BlockID]struct{}{}
+	queue := slices.Clone(current)
+	for len(queue) > 0 {
+		id := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if _, ok := visited[id]; ok {
+			continue
+		}
+		if _, ok := committed[id]; ok {
+			continue
+		}
+		if s, ok := floor[id.Workchain]; ok && id.Seqno <= s {
+			return nil, errors.Errorf("block %v is not built on the shards of masterchain block %v", id, seqno-1)
+		}
+		if len(visited) >= blockFollowerMaxBlocks {
+			return nil, errors.Errorf("masterchain block %v commits too many shard blocks", seqno)
+		}
+		visited[id] = struct{}{}
+		block, ok := known[id]
+		if !ok {
+			// the block is missing from GetBlockchainMasterchainBlocks, fill the gap.
+			if block, err = e.block(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		blocks = append(blocks, FollowedBlock{ID: id, Block: block, MasterchainSeqno: seqno})
+		for _, ref := range block.PrevRefs {
+			prev, err := ton.ParseBlockID(ref)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse prev ref %q of block %v", ref, id)
+			}
+			queue = append(queue, prev)
+		}
+	}
+	slices.SortFunc(blocks, func(a, b FollowedBlock) int {
+		if a.ID.Seqno != b.ID.Seqno {
+			return cmp.Compare(a.ID.Seqno, b.ID.Seqno)
+		}
+		if a.ID.Workchain != b.ID.Workchain {
+			return cmp.Compare(a.ID.Workchain, b.ID.Workchain)
+		}
+		return cmp.Compare(a.ID.Shard, b.ID.Shard)
+	})
+	blocks = append(blocks, FollowedBlock{ID: masterchainID, Block: masterchainBlock, MasterchainSeqno: seqno})
+	if err := e.transactions(ctx, blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// snapshot returns the last blocks of the shards at the masterchain block.
+func (e *blockExpander) snapshot(ctx context.Context, seqno uint32) ([]ton.BlockID, error) {
+	e.mu.Lock()
+	ids, ok := e.snapshots[seqno]
+	e.mu.Unlock()
+	if ok {
+		return ids, nil
+	}
+	res, err := e.client.GetBlockchainMasterchainShards(ctx, GetBlockchainMasterchainShardsParams{MasterchainSeqno: int32(seqno)})
+	if err != nil {
+		return nil, err
+	}
+	ids = make([]ton.BlockID, 0, len(res.Shards))
+	for _, shard := range res.Shards {
+		id, err := ton.ParseBlockID(shard.LastKnownBlockID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse shard block id %q", shard.LastKnownBlockID)
+		}
+		ids = append(ids, id)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.snapshots[seqno] = ids
+	for cached := range e.snapshots {
+		if cached+2*blockFollowerConcurrency < seqno {
+			delete(e.snapshots, cached)
+		}
+	}
+	return ids, nil
+}
+
+func (e *blockExpander) block(ctx context.Context, id ton.BlockID) (BlockchainBlock, error) {
+	block, err := e.client.GetBlockchainBlock(ctx, GetBlockchainBlockParams{BlockID: id.String()})
+	if err != nil {
+		return BlockchainBlock{}, errors.Wrapf(err, "get block %v", id)
+	}
+	return *block, nil
+}
+
+// transactions fetches the transactions of the blocks in parallel.
+// A block with fewer transactions than its tx_quantity is an error, the data is not complete yet.
+func (e *blockExpander) transactions(ctx context.Context, blocks []FollowedBlock) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(blockFollowerConcurrency)
+	for i := range blocks {
+		if blocks[i].Block.TxQuantity == 0 {
+			continue
+		}
+		g.Go(func() error {
+			res, err := e.client.GetBlockchainBlockTransactions(ctx, GetBlockchainBlockTransactionsParams{BlockID: blocks[i].ID.String()})
+			if err != nil {
+				return errors.Wrapf(err, "get transactions of block %v", blocks[i].ID)
+			}
+			if len(res.Transactions) != blocks[i].Block.TxQuantity {
+				return errors.Errorf("block %v has %v transactions out of %v", blocks[i].ID, len(res.Transactions), blocks[i].Block.TxQuantity)
+			}
+			blocks[i].Transactions = res.Transactions
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+func blockID(block BlockchainBlock) (ton.BlockID, error) {
+	shard, err := strconv.ParseUint(block.Shard, 16, 64)
+	if err != nil {
+		return ton.BlockID{}, errors.Wrapf(err, "parse shard %q", block.Shard)
+	}
+	return ton.BlockID{Workchain: block.WorkchainID, Shard: shard, Seqno: uint32(block.Seqno)}, nil
+}
@@ -0,0 +1,115 @@
+package tonapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func TestBlockFollower(t *testing.T) {
+	shardBlock := func(seqno int32) BlockchainBlock {
+		return BlockchainBlock{
+			WorkchainID: 0,
+			Shard:       "8000000000000000",
+			Seqno:       seqno,
+			TxQuantity:  1,
+			PrevRefs:    []string{fmt.Sprintf("(0,8000000000000000,%d)", seqno-1)},
+		}
+	}
+	masterchainBlock := func(seqno int32) BlockchainBlock {
+		return BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: seqno}
+	}
+	// shard blocks committed by masterchain blocks 9 to 12, block 101 is missing from the masterchain blocks of 10.
+	tips := map[string]int32{"9": 100, "10": 102, "11": 102, "12": 104}
+	committed := map[string][]BlockchainBlock{
+		"10": {shardBlock(102), masterchainBlock(10)},
+		"11": {masterchainBlock(11)},
+		"12": {shardBlock(103), shardBlock(104)},
+	}
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Path)
+		mu.Unlock()
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/blockchain/"), "/")
+		var data []byte
+		switch {
+		case r.URL.Path == "/v2/blockchain/masterchain-head":
+			data, _ = (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: 12}).MarshalJSON()
+		case parts[0] == "masterchain" && parts[2] == "shards":
+			data, _ = (&BlockchainBlockShards{Shards: []BlockchainBlockShardsShardsItem{{
+				LastKnownBlockID: fmt.Sprintf("(0,8000000000000000,%d)", tips[parts[1]]),
+			}}}).MarshalJSON()
+		case parts[0] == "masterchain" && parts[2] == "blocks":
+			data, _ = (&BlockchainBlocks{Blocks: append([]BlockchainBlock{}, committed[parts[1]]...)}).MarshalJSON()
+		case parts[0] == "blocks" && len(parts) == 2:
+			id := ton.MustParseBlockID(parts[1])
+			block := shardBlock(int32(id.Seqno))
+			if id.Workchain == -1 {
+				block = masterchainBlock(int32(id.Seqno))
+			}
+			data, _ = block.MarshalJSON()
+		case parts[0] == "blocks" && parts[2] == "transactions":
+			id := ton.MustParseBlockID(parts[1])
+			data, _ = (&Transactions{Transactions: []Transaction{consumerTestTransaction(int64(id.Seqno))}}).MarshalJSON()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	store := NewMemoryCheckpointStore()
+
+	var delivered []string
+	failed := false
+	follower := NewBlockFollower(client, store, "follower", func(ctx context.Context, block FollowedBlock) error {
+		if block.ID.Seqno == 103 && !failed {
+			failed = true
+			return errors.New("database is down")
+		}
+		if block.ID.Workchain == 0 {
+			require.Len(t, block.Transactions, 1)
+			require.Equal(t, int64(block.ID.Seqno), block.Transactions[0].Lt)
+		}
+		delivered = append(delivered, block.ID.String()+"@"+strconv.Itoa(int(block.MasterchainSeqno)))
+		return nil
+	}, WithConsumerStart(9))
+	ctx := context.Background()
+
+	// the handler fails on block 103, the masterchain checkpoint stays at 11.
+	require.ErrorContains(t, follower.consumer.consume(ctx), "database is down")
+	position, _, _ := store.Load(ctx, follower.consumer.CheckpointKey())
+	require.Equal(t, uint64(11), position)
+
+	// delivery resumes from the failed block without expanding the masterchain block again.
+	mu.Lock()
+	requests = nil
+	mu.Unlock()
+	require.NoError(t, follower.consumer.consume(ctx))
+	require.Equal(t, []string{
+		"(0,8000000000000000,101)@10",
+		"(0,8000000000000000,102)@10",
+		"(-1,8000000000000000,10)@10",
+		"(-1,8000000000000000,11)@11",
+		"(0,8000000000000000,103)@12",
+		"(0,8000000000000000,104)@12",
+		"(-1,8000000000000000,12)@12",
+	}, delivered)
+	require.Equal(t, []string{"/v2/blockchain/masterchain-head"}, requests)
+	position, _, _ = store.Load(ctx, follower.consumer.CheckpointKey())
+	require.Equal(t, uint64(12), position)
+}
@@ -0,0 +1,347 @@
+package tonapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-faster/errors"
+)
+
+// CheckpointStore persists the positions of event consumers, like the last processed lt of an account
+// or the last processed masterchain seqno. Implementations must be safe for concurrent use.
+type CheckpointStore interface {
+	// Load returns the position saved under the key, ok is false if nothing was saved yet.
+	Load(ctx context.Context, key string) (position uint64, ok bool, err error)
+	// Save stores the position under the key. Once it returns, the position must survive a restart.
+	Save(ctx context.Context, key string, position uint64) error
+}
+
+// MemoryCheckpointStore is an in-memory CheckpointStore for tests and consumers that don't need to resume.
+type MemoryCheckpointStore struct {
+	mu        sync.RWMutex
+	positions map[string]uint64
+}
+
+// NewMemoryCheckpointStore returns an empty MemoryCheckpointStore.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{positions: map[string]uint64{}}
+}
+
+func (s *MemoryCheckpointStore) Load(ctx context.Context, key string) (uint64, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	position, ok := s.positions[key]
+	return position, ok, nil
+}
+
+func (s *MemoryCheckpointStore) Save(ctx context.Context, key string, position uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.positions[key] = position
+	return nil
+}
+
+// FileCheckpointStore keeps checkpoints in a JSON file.
+// Every Save rewrites the file atomically, so the store suits a moderate number of keys saved at a moderate rate.
+// Use LogCheckpointStore for frequent saves.
+type FileCheckpointStore struct {
+	mu        sync.Mutex
+	path      string
+	positions map[string]uint64
+}
+
+// NewFileCheckpointStore opens a FileCheckpointStore, loading the file if it exists.
+func NewFileCheckpointStore(path string) (*FileCheckpointStore, error) {
+	s := &FileCheckpointStore{path: path, positions: map[string]uint64{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.positions); err != nil {
+		return nil, errors.Wrapf(err, "decode %v", path)
+	}
+	return s, nil
+}
+
+func (s *FileCheckpointStore) Load(ctx context.Context, key string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	position, ok := s.positions[key]
+	return position, ok, nil
+}
+
+func (s *FileCheckpointStore) Save(ctx context.Context, key string, position uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, existed := s.positions[key]
+	s.positions[key] = position
+	data, err := json.MarshalIndent(s.positions, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(s.path, data)
+	}
+	if err != nil {
+		// keep the in-memory state consistent with the file.
+		if existed {
+			s.positions[key] = previous
+		} else {
+			delete(s.positions, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic replaces a file with the data so that readers see either the old or the new content.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+const (
+	// checkpointLogMagic starts every LogCheckpointStore file.
+	checkpointLogMagic = "TONCKPT1"
+	// checkpointLogCompactMin is the number of records below which the log is never compacted.
+	checkpointLogCompactMin = 4096
+)
+
+// LogCheckpointStore is an embedded CheckpointStore that appends every Save to a log file
+// and keeps the latest positions in memory. Each record is checksummed and synced to disk,
+// a record torn by a crash is discarded on open. The log is compacted into a new file
+// once it holds mostly outdated records.
+//
+// Only one process may open the file at a time.
+type LogCheckpointStore struct {
+	mu        sync.Mutex
+	path      string
+	file      checkpointLogFile
+	positions map[string]uint64
+	// records is the number of records in the log file.
+	records int
+	// size is the offset of the end of the last complete record.
+	size int64
+	// err is set when a failed write could not be rolled back, the store refuses writes after that.
+	err error
+}
+
+// checkpointLogFile is the part of *os.File used by LogCheckpointStore.
+type checkpointLogFile interface {
+	io.Writer
+	Sync() error
+	Truncate(size int64) error
+	Seek(offset int64, whence int) (int64, error)
+	Close() error
+}
+
+// OpenLogCheckpointStore opens or creates a LogCheckpointStore. Call Close to release the file.
+func OpenLogCheckpointStore(path string) (*LogCheckpointStore, error) {
+	s := &LogCheckpointStore{path: path, positions: map[string]uint64{}}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.replay(file); err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "open %v", path)
+	}
+	s.file = file
+	return s, nil
+}
+
+// replay reads the records of the log, truncates a torn tail and positions the file for appending.
+func (s *LogCheckpointStore) replay(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		if _, err := file.WriteString(checkpointLogMagic); err != nil {
+			return err
+		}
+		s.size = int64(len(checkpointLogMagic))
+		return file.Sync()
+	}
+	r := bufio.NewReader(file)
+	magic := make([]byte, len(checkpointLogMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != checkpointLogMagic {
+		return errors.New("not a checkpoint log")
+	}
+	offset := int64(len(magic))
+	for {
+		key, position, n, err := readCheckpointRecord(r)
+		if err != nil {
+			// everything after the last valid record is a write interrupted by a crash.
+			if err := file.Truncate(offset); err != nil {
+				return err
+			}
+			break
+		}
+		s.positions[key] = position
+		s.records++
+		offset += n
+	}
+	s.size = offset
+	_, err = file.Seek(offset, io.SeekStart)
+	return err
+}
+
+// A record is the length of its payload, the CRC-32 of the payload and the payload itself,
+// which is the length of the key, the key and the position, all integers being uvarints
+// except the checksum.
+func appendCheckpointRecord(buf []byte, key string, position uint64) []byte {
+	payload := binary.AppendUvarint(nil, uint64(len(key)))
+	payload = append(payload, key...)
+	payload = binary.AppendUvarint(payload, position)
+	buf = binary.AppendUvarint(buf, uint64(len(payload)))
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(payload))
+	return append(buf, payload...)
+}
+
+func readCheckpointRecord(r *bufio.Reader) (key string, position uint64, n int64, err error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if size > 1<<20 {
+		return "", 0, 0, errors.New("record too large")
+	}
+	data := make([]byte, 4+size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", 0, 0, err
+	}
+	payload := data[4:]
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(data) {
+		return "", 0, 0, errors.New("checksum mismatch")
+	}
+	keyLen, k := binary.Uvarint(payload)
+	if k <= 0 || uint64(len(payload)-k) < keyLen {
+		return "", 0, 0, errors.New("malformed record")
+	}
+	key = string(payload[k : k+int(keyLen)])
+	position, p := binary.Uvarint(payload[k+int(keyLen):])
+	if p <= 0 {
+		return "", 0, 0, errors.New("malformed record")
+	}
+	return key, position, int64(len(binary.AppendUvarint(nil, size))) + int64(len(data)), nil
+}
+
+func (s *LogCheckpointStore) Load(ctx context.Context, key string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	position, ok := s.positions[key]
+	return position, ok, nil
+}
+
+func (s *LogCheckpointStore) Save(ctx context.Context, key string, position uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if s.file == nil {
+		return errors.New("checkpoint store is closed")
+	}
+	record := appendCheckpointRecord(nil, key, position)
+	if err := s.append(record); err != nil {
+		return err
+	}
+	s.size += int64(len(record))
+	s.positions[key] = position
+	s.records++
+	if s.records >= checkpointLogCompactMin && s.records > 4*len(s.positions) {
+		// the position is saved already, a failed compaction is retried on the next save.
+		_ = s.compact()
+	}
+	return nil
+}
+
+// append writes a record to the end of the log. If the write or the sync fails,
+// the log is truncated back to its last complete record, so later records are not lost behind a torn one.
+func (s *LogCheckpointStore) append(record []byte) error {
+	_, err := s.file.Write(record)
+	if err == nil {
+		err = s.file.Sync()
+	}
+	if err == nil {
+		return nil
+	}
+	if terr := s.file.Truncate(s.size); terr != nil {
+		s.err = errors.Wrap(terr, "checkpoint log is corrupted by a failed write")
+		return err
+	}
+	if _, serr := s.file.Seek(s.size, io.SeekStart); serr != nil {
+		s.err = errors.Wrap(serr, "checkpoint log is corrupted by a failed write")
+	}
+	return err
+}
+
+// compact rewrites the log with a single record per key.
+func (s *LogCheckpointStore) compact() error {
+	buf := []byte(checkpointLogMagic)
+	for key, position := range s.positions {
+		buf = appendCheckpointRecord(buf, key, position)
+	}
+	if err := writeFileAtomic(s.path, buf); err != nil {
+		return errors.Wrap(err, "compact checkpoint log")
+	}
+	// the old file is unlinked now, records appended to it would be lost.
+	s.file.Close()
+	s.file = nil
+	file, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		s.err = errors.Wrap(err, "reopen compacted checkpoint log")
+		return err
+	}
+	s.file = file
+	s.records = len(s.positions)
+	s.size = int64(len(buf))
+	return nil
+}
+
+// Close closes the log file.
+func (s *LogCheckpointStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
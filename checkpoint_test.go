@@ -0,0 +1,172 @@
+package tonapi
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointStores(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	stores := map[string]func() CheckpointStore{
+		"memory": func() CheckpointStore { return NewMemoryCheckpointStore() },
+		"file": func() CheckpointStore {
+			s, err := NewFileCheckpointStore(filepath.Join(dir, "checkpoints.json"))
+			require.NoError(t, err)
+			return s
+		},
+		"log": func() CheckpointStore {
+			s, err := OpenLogCheckpointStore(filepath.Join(dir, "checkpoints.log"))
+			require.NoError(t, err)
+			t.Cleanup(func() { s.Close() })
+			return s
+		},
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := open()
+			_, ok, err := s.Load(ctx, "a")
+			require.NoError(t, err)
+			require.False(t, ok)
+			require.NoError(t, s.Save(ctx, "a", 1))
+			require.NoError(t, s.Save(ctx, "b", 2))
+			require.NoError(t, s.Save(ctx, "a", 3))
+			position, ok, err := s.Load(ctx, "a")
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, uint64(3), position)
+
+			if name == "memory" {
+				return
+			}
+			if closer, ok := s.(*LogCheckpointStore); ok {
+				require.NoError(t, closer.Close())
+			}
+			reopened := open()
+			position, ok, err = reopened.Load(ctx, "a")
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, uint64(3), position)
+			position, _, _ = reopened.Load(ctx, "b")
+			require.Equal(t, uint64(2), position)
+		})
+	}
+}
+
+func TestLogCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "checkpoints.log")
+	s, err := OpenLogCheckpointStore(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Save(ctx, "a", 1))
+	require.NoError(t, s.Save(ctx, "a", 2))
+	require.NoError(t, s.Close())
+	require.Error(t, s.Save(ctx, "a", 3))
+
+	// a record torn by a crash is dropped and the log stays writable.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write(appendCheckpointRecord(nil, "a", 100)[:5])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s, err = OpenLogCheckpointStore(path)
+	require.NoError(t, err)
+	position, _, _ := s.Load(ctx, "a")
+	require.Equal(t, uint64(2), position)
+	info2, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, info.Size(), info2.Size())
+
+	// frequent saves of a few keys are compacted.
+	for i := uint64(0); i < checkpointLogCompactMin; i++ {
+		require.NoError(t, s.Save(ctx, "b", i))
+	}
+	require.Less(t, s.records, checkpointLogCompactMin)
+	require.NoError(t, s.Save(ctx, "a", 5))
+	require.NoError(t, s.Close())
+
+	s, err = OpenLogCheckpointStore(path)
+	require.NoError(t, err)
+	defer s.Close()
+	position, _, _ = s.Load(ctx, "a")
+	require.Equal(t, uint64(5), position)
+	position, _, _ = s.Load(ctx, "b")
+	require.Equal(t, uint64(checkpointLogCompactMin-1), position)
+
+	_, err = OpenLogCheckpointStore(filepath.Join(t.TempDir(), "missing", "x"))
+	require.Error(t, err)
+	other := filepath.Join(t.TempDir(), "other")
+	require.NoError(t, os.WriteFile(other, []byte("{}"), 0o644))
+	_, err = OpenLogCheckpointStore(other)
+	require.ErrorContains(t, err, "not a checkpoint log")
+}
+
+// faultyLogFile fails the next write after writing part of the record, or the next sync.
+type faultyLogFile struct {
+	checkpointLogFile
+	failWrite, failSync bool
+}
+
+func (f *faultyLogFile) Write(p []byte) (int, error) {
+	if f.failWrite {
+		f.failWrite = false
+		n, _ := f.checkpointLogFile.Write(p[:len(p)/2])
+		return n, errors.New("disk full")
+	}
+	return f.checkpointLogFile.Write(p)
+}
+
+func (f *faultyLogFile) Sync() error {
+	if f.failSync {
+		f.failSync = false
+		return errors.New("io error")
+	}
+	return f.checkpointLogFile.Sync()
+}
+
+func TestLogCheckpointStoreFailures(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "checkpoints.log")
+	s, err := OpenLogCheckpointStore(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Save(ctx, "a", 1))
+
+	// failed writes and syncs are rolled back, the records saved after them survive a restart.
+	faulty := &faultyLogFile{checkpointLogFile: s.file, failWrite: true}
+	s.file = faulty
+	require.ErrorContains(t, s.Save(ctx, "b", 1), "disk full")
+	require.NoError(t, s.Save(ctx, "c", 1))
+	faulty.failSync = true
+	require.ErrorContains(t, s.Save(ctx, "d", 1), "io error")
+	require.NoError(t, s.Save(ctx, "e", 1))
+	_, ok, _ := s.Load(ctx, "b")
+	require.False(t, ok)
+
+	// a failed compaction does not fail the save that triggered it.
+	s.path = filepath.Join(dir, "missing", "checkpoints.log")
+	s.records = checkpointLogCompactMin
+	require.NoError(t, s.Save(ctx, "a", 2))
+	require.NoError(t, s.Close())
+
+	s, err = OpenLogCheckpointStore(path)
+	require.NoError(t, err)
+	defer s.Close()
+	for key, expected := range map[string]uint64{"a": 2, "c": 1, "e": 1} {
+		position, ok, _ := s.Load(ctx, key)
+		require.True(t, ok, key)
+		require.Equal(t, expected, position, key)
+	}
+	for _, key := range []string{"b", "d"} {
+		_, ok, _ := s.Load(ctx, key)
+		require.False(t, ok, key)
+	}
+}
@@ -0,0 +1,103 @@
+// Command tonapi-webhook relays tonapi streaming events to HTTP endpoints.
+//
+// Usage:
+//
+//	tonapi-webhook [-listen :8080] [-testnet] [-token token] [-api-token token] [-store file]
+//
+// Subscribers are registered with the REST API described in the webhook package:
+//
+//	curl -X POST localhost:8080/subscribers -d '{"url": "https://example.com/hook", "stream": "transactions", "accounts": ["EQ..."]}'
+//
+// The token defaults to the TONAPI_TOKEN environment variable and the API token to WEBHOOK_API_TOKEN.
+// Subscribers and dead letters are kept in memory unless -store names a JSON file to persist them to.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/tonkeeper/tonapi-go"
+	"github.com/tonkeeper/tonapi-go/webhook"
+)
+
+const (
+	tokenEnv    = "TONAPI_TOKEN"
+	apiTokenEnv = "WEBHOOK_API_TOKEN"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "tonapi-webhook: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("tonapi-webhook", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	listen := flags.String("listen", ":8080", "address of the REST API")
+	testnet := flags.Bool("testnet", false, "use the testnet")
+	token := flags.String("token", os.Getenv(tokenEnv), "tonapi token, defaults to $"+tokenEnv)
+	endpoint := flags.String("endpoint", "", "tonapi endpoint, overrides -testnet")
+	apiToken := flags.String("api-token", os.Getenv(apiTokenEnv), "bearer token protecting the REST API, defaults to $"+apiTokenEnv)
+	attempts := flags.Int("attempts", 8, "delivery attempts before an event goes to the dead-letter store")
+	maxBackoff := flags.Duration("max-backoff", 5*time.Minute, "maximum delay between delivery attempts")
+	storePath := flags.String("store", "", "file persisting subscribers and dead letters, kept in memory if empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logger := slog.New(slog.NewTextHandler(stderr, nil))
+	streamingOpts := []tonapi.StreamingOption{tonapi.WithStreamingToken(*token), tonapi.WithStreamingStructuredLogger(logger)}
+	if *testnet {
+		streamingOpts = append(streamingOpts, tonapi.WithStreamingTestnet())
+	}
+	if *endpoint != "" {
+		streamingOpts = append(streamingOpts, tonapi.WithStreamingEndpoint(*endpoint))
+	}
+	relayOpts := []webhook.Option{
+		webhook.WithLogger(logger),
+		webhook.WithAPIToken(*apiToken),
+		webhook.WithRetries(*attempts, time.Second, *maxBackoff),
+	}
+	if *storePath != "" {
+		store, err := webhook.NewFileStore(*storePath)
+		if err != nil {
+			return err
+		}
+		relayOpts = append(relayOpts, webhook.WithStore(store))
+	}
+	relay := webhook.NewRelay(tonapi.NewStreamingAPI(streamingOpts...), relayOpts...)
+	server := &http.Server{Addr: *listen, Handler: relay.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return relay.Run(ctx)
+	})
+	g.Go(func() error {
+		logger.Info("listening", "address", *listen)
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	g.Go(func() error {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	})
+	return g.Wait()
+}
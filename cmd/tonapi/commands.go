@@ -0,0 +1,473 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "account", args: "<address>", short: "show balance, status and interfaces of an account", run: accountCommand},
+		{name: "events", args: "[-limit n] [-before lt] <address>", short: "list recent events of an account", run: eventsCommand},
+		{name: "trace", args: "<trace id or transaction hash>", short: "show a trace as a tree of transactions", run: traceCommand},
+		{name: "tx", args: "<transaction hash>", short: "show a transaction", run: txCommand},
+		{name: "jetton", args: "<master address> | balances <address>", short: "show a jetton or jetton balances of an account", run: jettonCommand},
+		{name: "nft", args: "<item address> | items [-collection address] <address>", short: "show an NFT item or NFT items of an account", run: nftCommand},
+		{name: "dns", args: "<domain> | reverse <address>", short: "resolve a domain or list domains of an account", run: dnsCommand},
+		{name: "rates", args: "[-currencies usd,eur] <token>...", short: "show prices of TON (\"ton\") and jettons", run: ratesCommand},
+		{name: "emulate", args: "[-ignore-signature] <boc | ->", short: "emulate an external message and show its actions", run: emulateCommand},
+		{name: "send", args: "<boc | ->", short: "send an external message", run: sendCommand},
+		{name: "get-method", args: "<address> <method> [args...]", short: "run a get method of an account", run: getMethodCommand},
+		{name: "stream", args: "[-ws] [-workchain n] [-operations ops] transactions|traces|mempool|blocks [address...]", short: "tail events from the streaming API until interrupted", run: streamCommand, long: true},
+	}
+}
+
+// parseFlags parses the flags of a command and returns the positional arguments.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	return flags.Args(), nil
+}
+
+func accountCommand(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	account, err := a.client.GetAccount(ctx, tonapi.GetAccountParams{AccountID: normalizeAddress(args[0])})
+	if err != nil {
+		return err
+	}
+	return a.print(account, func(w io.Writer) {
+		row(w, "address", account.Address)
+		row(w, "friendly", a.friendlyAddress(account.Address, !account.IsWallet))
+		if name, ok := account.Name.Get(); ok {
+			row(w, "name", name)
+		}
+		row(w, "balance", formatTon(account.Balance))
+		for _, ec := range account.ExtraBalance {
+			row(w, "", formatJetton(ec.Amount, ec.Preview.Decimals, ec.Preview.Symbol))
+		}
+		row(w, "status", account.Status)
+		row(w, "wallet", account.IsWallet)
+		row(w, "interfaces", strings.Join(account.Interfaces, ", "))
+		row(w, "last activity", formatTime(account.LastActivity))
+		if account.IsScam.Value {
+			row(w, "scam", true)
+		}
+	})
+}
+
+func eventsCommand(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("events", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "number of events, at most 100")
+	before := flags.Int64("before", 0, "show events before the logical time")
+	args, err := parseFlags(flags, args)
+	if err != nil || len(args) != 1 {
+		return errUsage
+	}
+	params := tonapi.GetAccountEventsParams{AccountID: normalizeAddress(args[0]), Limit: *limit}
+	if *before > 0 {
+		params.BeforeLt = tonapi.NewOptInt64(*before)
+	}
+	events, err := a.client.GetAccountEvents(ctx, params)
+	if err != nil {
+		return err
+	}
+	return a.print(events, func(w io.Writer) {
+		row(w, "TIME", "EVENT", "ACTION", "STATUS", "DESCRIPTION")
+		for _, event := range events.Events {
+			for i, action := range event.Actions {
+				id, ts := "", ""
+				if i == 0 {
+					id, ts = event.EventID, formatTime(event.Timestamp)
+					if event.InProgress {
+						id += " (in progress)"
+					}
+				}
+				row(w, ts, id, action.Type, action.Status, action.SimplePreview.Description)
+			}
+		}
+		if events.NextFrom != 0 {
+			fmt.Fprintf(w, "\nmore: -before %v\n", events.NextFrom)
+		}
+	})
+}
+
+func traceCommand(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	trace, err := a.client.GetTrace(ctx, tonapi.GetTraceParams{TraceID: args[0]})
+	if err != nil {
+		return err
+	}
+	return a.print(trace, func(w io.Writer) {
+		row(w, "TRANSACTION", "ACCOUNT", "OPERATION", "VALUE", "SUCCESS")
+		var walk func(trace tonapi.Trace, depth int)
+		walk = func(trace tonapi.Trace, depth int) {
+			tx := trace.Transaction
+			op, value := "", ""
+			if msg, ok := tx.InMsg.Get(); ok {
+				op = msg.DecodedOpName.Or(msg.OpCode.Value)
+				value = formatTon(msg.Value)
+			}
+			row(w, strings.Repeat("  ", depth)+tx.Hash, a.accountName(tx.Account), op, value, tx.Success)
+			for _, child := range trace.Children {
+				walk(child, depth+1)
+			}
+		}
+		walk(*trace, 0)
+	})
+}
+
+func txCommand(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	tx, err := a.client.GetBlockchainTransaction(ctx, tonapi.GetBlockchainTransactionParams{TransactionID: args[0]})
+	if err != nil {
+		return err
+	}
+	return a.print(tx, func(w io.Writer) {
+		row(w, "hash", tx.Hash)
+		row(w, "lt", tx.Lt)
+		row(w, "time", formatTime(tx.Utime))
+		row(w, "account", a.accountName(tx.Account))
+		row(w, "block", tx.Block)
+		row(w, "type", tx.TransactionType)
+		row(w, "success", tx.Success)
+		if phase, ok := tx.ComputePhase.Get(); ok && !phase.Skipped {
+			row(w, "exit code", phase.ExitCode.Value)
+		}
+		row(w, "fees", formatTon(tx.TotalFees))
+		row(w, "end balance", formatTon(tx.EndBalance))
+		if msg, ok := tx.InMsg.Get(); ok {
+			row(w, "in", describeMessage(a, msg))
+		}
+		for _, msg := range tx.OutMsgs {
+			row(w, "out", describeMessage(a, msg))
+		}
+	})
+}
+
+func describeMessage(a *app, msg tonapi.Message) string {
+	var parts []string
+	if source, ok := msg.Source.Get(); ok {
+		parts = append(parts, "from "+a.accountName(source))
+	}
+	if destination, ok := msg.Destination.Get(); ok {
+		parts = append(parts, "to "+a.accountName(destination))
+	}
+	parts = append(parts, formatTon(msg.Value))
+	if op := msg.DecodedOpName.Or(msg.OpCode.Value); op != "" {
+		parts = append(parts, op)
+	}
+	return strings.Join(parts, " ")
+}
+
+func jettonCommand(ctx context.Context, a *app, args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "balances":
+		balances, err := a.client.GetAccountJettonsBalances(ctx, tonapi.GetAccountJettonsBalancesParams{AccountID: normalizeAddress(args[1])})
+		if err != nil {
+			return err
+		}
+		return a.print(balances, func(w io.Writer) {
+			row(w, "JETTON", "BALANCE", "MASTER", "VERIFICATION")
+			for _, b := range balances.Balances {
+				row(w, b.Jetton.Name, formatJetton(b.Balance, b.Jetton.Decimals, b.Jetton.Symbol),
+					a.friendlyAddress(b.Jetton.Address, true), b.Jetton.Verification)
+			}
+		})
+	case len(args) == 1:
+		info, err := a.client.GetJettonInfo(ctx, tonapi.GetJettonInfoParams{AccountID: normalizeAddress(args[0])})
+		if err != nil {
+			return err
+		}
+		return a.print(info, func(w io.Writer) {
+			meta := info.Metadata
+			decimals, _ := strconv.Atoi(meta.Decimals)
+			row(w, "name", meta.Name)
+			row(w, "symbol", meta.Symbol)
+			row(w, "master", a.friendlyAddress(meta.Address, true))
+			row(w, "decimals", meta.Decimals)
+			row(w, "total supply", formatJetton(info.TotalSupply, decimals, meta.Symbol))
+			row(w, "holders", info.HoldersCount)
+			row(w, "mintable", info.Mintable)
+			if admin, ok := info.Admin.Get(); ok {
+				row(w, "admin", a.accountName(admin))
+			}
+			row(w, "verification", info.Verification)
+		})
+	}
+	return errUsage
+}
+
+func nftCommand(ctx context.Context, a *app, args []string) error {
+	if len(args) > 0 && args[0] == "items" {
+		flags := flag.NewFlagSet("nft items", flag.ContinueOnError)
+		collection := flags.String("collection", "", "only items of the collection")
+		args, err := parseFlags(flags, args[1:])
+		if err != nil || len(args) != 1 {
+			return errUsage
+		}
+		params := tonapi.GetAccountNftItemsParams{AccountID: normalizeAddress(args[0])}
+		if *collection != "" {
+			params.Collection = tonapi.NewOptString(normalizeAddress(*collection))
+		}
+		items, err := a.client.GetAccountNftItems(ctx, params)
+		if err != nil {
+			return err
+		}
+		return a.print(items, func(w io.Writer) {
+			row(w, "ITEM", "NAME", "COLLECTION", "TRUST")
+			for _, item := range items.NftItems {
+				row(w, a.friendlyAddress(item.Address, true), nftName(item), item.Collection.Value.Name, item.Trust)
+			}
+		})
+	}
+	if len(args) != 1 {
+		return errUsage
+	}
+	item, err := a.client.GetNftItemByAddress(ctx, tonapi.GetNftItemByAddressParams{AccountID: normalizeAddress(args[0])})
+	if err != nil {
+		return err
+	}
+	return a.print(item, func(w io.Writer) {
+		row(w, "address", a.friendlyAddress(item.Address, true))
+		row(w, "name", nftName(*item))
+		row(w, "index", item.Index)
+		if collection, ok := item.Collection.Get(); ok {
+			row(w, "collection", collection.Name+" "+a.friendlyAddress(collection.Address, true))
+		}
+		if owner, ok := item.Owner.Get(); ok {
+			row(w, "owner", a.accountName(owner))
+		}
+		if dns, ok := item.DNS.Get(); ok {
+			row(w, "dns", dns)
+		}
+		if sale, ok := item.Sale.Get(); ok {
+			row(w, "on sale", formatJetton(sale.Price.Value, sale.Price.Decimals, sale.Price.TokenName)+" at "+a.accountName(sale.Market))
+		}
+		row(w, "trust", item.Trust)
+	})
+}
+
+// nftName returns the name from the metadata of an item.
+func nftName(item tonapi.NftItem) string {
+	var name string
+	if raw, ok := item.Metadata["name"]; ok {
+		json.Unmarshal(raw, &name)
+	}
+	return name
+}
+
+func dnsCommand(ctx context.Context, a *app, args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "reverse":
+		names, err := a.client.AccountDnsBackResolve(ctx, tonapi.AccountDnsBackResolveParams{AccountID: normalizeAddress(args[1])})
+		if err != nil {
+			return err
+		}
+		return a.print(names, func(w io.Writer) {
+			for _, domain := range names.Domains {
+				row(w, domain)
+			}
+		})
+	case len(args) == 1:
+		record, err := a.client.DnsResolve(ctx, tonapi.DnsResolveParams{DomainName: args[0]})
+		if err != nil {
+			return err
+		}
+		return a.print(record, func(w io.Writer) {
+			if wallet, ok := record.Wallet.Get(); ok {
+				row(w, "wallet", wallet.Address)
+				row(w, "friendly", a.friendlyAddress(wallet.Address, !wallet.IsWallet))
+			}
+			for _, site := range record.Sites {
+				row(w, "site", site)
+			}
+			if storage, ok := record.Storage.Get(); ok {
+				row(w, "storage", storage)
+			}
+			if resolver, ok := record.NextResolver.Get(); ok {
+				row(w, "next resolver", resolver)
+			}
+		})
+	}
+	return errUsage
+}
+
+func ratesCommand(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("rates", flag.ContinueOnError)
+	currencies := flags.String("currencies", "usd", "comma separated currencies")
+	args, err := parseFlags(flags, args)
+	if err != nil || len(args) == 0 {
+		return errUsage
+	}
+	tokens := make([]string, 0, len(args))
+	for _, token := range args {
+		tokens = append(tokens, normalizeAddress(token))
+	}
+	currencyList := strings.Split(strings.ToLower(*currencies), ",")
+	rates, err := a.client.GetRates(ctx, tonapi.GetRatesParams{Tokens: tokens, Currencies: currencyList})
+	if err != nil {
+		return err
+	}
+	return a.print(rates, func(w io.Writer) {
+		row(w, "TOKEN", "CURRENCY", "PRICE", "24H", "7D")
+		for _, token := range tokens {
+			tokenRates, ok := findRates(rates.Rates, token)
+			if !ok {
+				continue
+			}
+			for _, currency := range currencyList {
+				price, ok := lookup(tokenRates.Prices.Value, currency)
+				if !ok {
+					continue
+				}
+				diff24h, _ := lookup(tokenRates.Diff24h.Value, currency)
+				diff7d, _ := lookup(tokenRates.Diff7d.Value, currency)
+				row(w, token, currency, price, diff24h, diff7d)
+			}
+		}
+	})
+}
+
+// findRates looks up the rates of a token by name, case-insensitively, or by address in any form.
+func findRates(rates tonapi.GetRatesOKRates, token string) (tonapi.TokenRates, bool) {
+	for key, r := range rates {
+		if strings.EqualFold(key, token) || normalizeAddress(key) == token {
+			return r, true
+		}
+	}
+	return tonapi.TokenRates{}, false
+}
+
+func lookup[V any](m map[string]V, key string) (V, bool) {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// readBoc returns the argument or, if it is "-", the trimmed standard input.
+func (a *app) readBoc(arg string) (string, error) {
+	if arg != "-" {
+		return arg, nil
+	}
+	data, err := io.ReadAll(a.stdin)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(data)), nil
+}
+
+func emulateCommand(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("emulate", flag.ContinueOnError)
+	ignoreSignature := flags.Bool("ignore-signature", false, "emulate messages with invalid signatures")
+	args, err := parseFlags(flags, args)
+	if err != nil || len(args) != 1 {
+		return errUsage
+	}
+	boc, err := a.readBoc(args[0])
+	if err != nil {
+		return err
+	}
+	params := tonapi.EmulateMessageToEventParams{}
+	if *ignoreSignature {
+		params.IgnoreSignatureCheck = tonapi.NewOptBool(true)
+	}
+	event, err := a.client.EmulateMessageToEvent(ctx, &tonapi.EmulateMessageToEventReq{Boc: boc}, params)
+	if err != nil {
+		return err
+	}
+	return a.print(event, func(w io.Writer) {
+		row(w, "ACTION", "STATUS", "DESCRIPTION")
+		for _, action := range event.Actions {
+			row(w, action.Type, action.Status, action.SimplePreview.Description)
+		}
+		if len(event.ValueFlow) > 0 {
+			row(w)
+			row(w, "ACCOUNT", "TON", "FEES")
+			for _, flow := range event.ValueFlow {
+				row(w, a.accountName(flow.Account), formatTon(flow.Gram), formatTon(flow.Fees))
+			}
+		}
+	})
+}
+
+func sendCommand(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	boc, err := a.readBoc(args[0])
+	if err != nil {
+		return err
+	}
+	if err := a.client.SendBlockchainMessage(ctx, &tonapi.SendBlockchainMessageReq{Boc: tonapi.NewOptString(boc)}); err != nil {
+		return err
+	}
+	return a.print(map[string]bool{"sent": true}, func(w io.Writer) {
+		row(w, "sent")
+	})
+}
+
+func getMethodCommand(ctx context.Context, a *app, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	result, err := a.client.ExecGetMethodForBlockchainAccount(ctx, tonapi.ExecGetMethodForBlockchainAccountParams{
+		AccountID:  normalizeAddress(args[0]),
+		MethodName: args[1],
+		Args:       args[2:],
+	})
+	if err != nil {
+		return err
+	}
+	return a.print(result, func(w io.Writer) {
+		row(w, "success", result.Success)
+		row(w, "exit code", result.ExitCode)
+		if len(result.Decoded) > 0 {
+			var decoded bytes.Buffer
+			if json.Indent(&decoded, result.Decoded, "", "  ") == nil {
+				row(w, "decoded", decoded.String())
+			}
+		}
+		for i, record := range result.Stack {
+			row(w, fmt.Sprintf("stack[%d]", i), stackValue(record))
+		}
+	})
+}
+
+// stackValue formats a TVM stack record as a single value.
+func stackValue(record tonapi.TvmStackRecord) string {
+	switch record.Type {
+	case tonapi.TvmStackRecordTypeNum:
+		return record.Num.Value
+	case tonapi.TvmStackRecordTypeCell:
+		return "cell " + record.Cell.Value
+	case tonapi.TvmStackRecordTypeTuple:
+		values := make([]string, 0, len(record.Tuple))
+		for _, r := range record.Tuple {
+			values = append(values, stackValue(r))
+		}
+		return "(" + strings.Join(values, ", ") + ")"
+	}
+	return string(record.Type)
+}
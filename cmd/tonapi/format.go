@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tonkeeper/tongo/ton"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// tonDecimals is the number of decimals of the native coin.
+const tonDecimals = 9
+
+// normalizeAddress converts an address in any form to the raw form.
+// Other values, like DNS names, are returned as is since tonapi resolves them.
+func normalizeAddress(address string) string {
+	account, err := ton.ParseAccountID(address)
+	if err != nil {
+		return address
+	}
+	return account.ToRaw()
+}
+
+// friendlyAddress returns the user-friendly form of a raw address, or the address as is if it cannot be parsed.
+func (a *app) friendlyAddress(address string, bounceable bool) string {
+	account, err := ton.ParseAccountID(address)
+	if err != nil {
+		return address
+	}
+	return account.ToHuman(bounceable, a.testnet)
+}
+
+// accountName returns the name of an account if known and its user-friendly address otherwise.
+func (a *app) accountName(account tonapi.AccountAddress) string {
+	if name, ok := account.Name.Get(); ok && name != "" {
+		return name
+	}
+	return a.friendlyAddress(account.Address, !account.IsWallet)
+}
+
+// formatUnits formats an amount in the smallest units as a decimal number of whole units.
+func formatUnits(amount *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return amount.String()
+	}
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+func formatTon(nanotons int64) string {
+	return formatUnits(big.NewInt(nanotons), tonDecimals) + " TON"
+}
+
+// formatJetton formats a jetton amount given as a decimal string.
+func formatJetton(amount string, decimals int, symbol string) string {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return amount + " " + symbol
+	}
+	return formatUnits(value, decimals) + " " + symbol
+}
+
+func formatTime(unix int64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
+
+// print writes v as indented JSON in JSON mode and calls table otherwise.
+func (a *app) print(v any, table func(w io.Writer)) error {
+	if a.json {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(a.out, "%s\n", data)
+		return err
+	}
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// row writes tab separated values terminated by a new line.
+func row(w io.Writer, values ...any) {
+	for i, v := range values {
+		if i > 0 {
+			io.WriteString(w, "\t")
+		}
+		fmt.Fprint(w, v)
+	}
+	io.WriteString(w, "\n")
+}
@@ -0,0 +1,140 @@
+// Command tonapi is a command-line client of tonapi.io for ad-hoc investigations.
+//
+// Usage:
+//
+//	tonapi [-testnet] [-token token] [-json] <command> [arguments]
+//
+// The token defaults to the TONAPI_TOKEN environment variable.
+// Run "tonapi help" to list the commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// tokenEnv is the environment variable with the default API token.
+const tokenEnv = "TONAPI_TOKEN"
+
+// errUsage is returned by commands called with wrong arguments.
+var errUsage = errors.New("invalid arguments")
+
+// command is a subcommand of the tool.
+type command struct {
+	name  string
+	args  string
+	short string
+	run   func(ctx context.Context, a *app, args []string) error
+	// long running commands are not limited by the -timeout flag.
+	long bool
+}
+
+// app is the state shared by commands.
+type app struct {
+	client    *tonapi.Client
+	streaming *tonapi.StreamingAPI
+	stdin     io.Reader
+	out       io.Writer
+	json      bool
+	testnet   bool
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("tonapi", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	testnet := flags.Bool("testnet", false, "use the testnet")
+	token := flags.String("token", os.Getenv(tokenEnv), "API token, defaults to $"+tokenEnv)
+	endpoint := flags.String("endpoint", "", "API endpoint, overrides -testnet")
+	jsonOutput := flags.Bool("json", false, "print raw JSON instead of tables")
+	timeout := flags.Duration("timeout", 30*time.Second, "request timeout")
+	flags.Usage = func() { usage(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 || flags.Arg(0) == "help" {
+		usage(stderr, flags)
+		return 2
+	}
+	cmd, ok := findCommand(flags.Arg(0))
+	if !ok {
+		fmt.Fprintf(stderr, "tonapi: unknown command %q\n", flags.Arg(0))
+		usage(stderr, flags)
+		return 2
+	}
+
+	url := tonapi.TonApiURL
+	streamingOpts := []tonapi.StreamingOption{tonapi.WithStreamingToken(*token)}
+	if *testnet {
+		url = tonapi.TestnetTonApiURL
+		streamingOpts = append(streamingOpts, tonapi.WithStreamingTestnet())
+	}
+	if *endpoint != "" {
+		url = strings.TrimSuffix(*endpoint, "/")
+		streamingOpts = append(streamingOpts, tonapi.WithStreamingEndpoint(url))
+	}
+	client, err := tonapi.NewClient(url, tonapi.WithToken(*token))
+	if err != nil {
+		fmt.Fprintf(stderr, "tonapi: %v\n", err)
+		return 1
+	}
+	a := &app{
+		client:    client,
+		streaming: tonapi.NewStreamingAPI(streamingOpts...),
+		stdin:     stdin,
+		out:       stdout,
+		json:      *jsonOutput,
+		testnet:   *testnet,
+	}
+	if !cmd.long {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	if err := cmd.run(ctx, a, flags.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "usage: tonapi %v %v\n", cmd.name, cmd.args)
+			return 2
+		}
+		if errors.Is(err, context.Canceled) && cmd.long {
+			return 0
+		}
+		fmt.Fprintf(stderr, "tonapi: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintf(w, "usage: tonapi [flags] <command> [arguments]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12v %v\n", cmd.name, cmd.short)
+		fmt.Fprintf(w, "  %-12v   tonapi %v %v\n", "", cmd.name, cmd.args)
+	}
+	fmt.Fprintf(w, "\nflags:\n")
+	flags.PrintDefaults()
+}
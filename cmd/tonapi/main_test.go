@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+
+	"github.com/tonkeeper/tonapi-go"
+	"github.com/tonkeeper/tonapi-go/tonapitest"
+)
+
+func TestRun(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	srv := tonapitest.NewServer(t)
+	srv.Handle(tonapi.GetAccountOperation, func(r *tonapitest.Request) (any, error) {
+		return &tonapi.Account{Address: r.PathParam("account_id"), Balance: 1_500_000_000, Status: tonapi.AccountStatusActive, IsWallet: true}, nil
+	})
+	srv.Respond(tonapi.ExecGetMethodForBlockchainAccountOperation, &tonapi.MethodExecutionResult{
+		Success: true,
+		Stack:   []tonapi.TvmStackRecord{{Type: tonapi.TvmStackRecordTypeNum, Num: tonapi.NewOptString("0x2a")}},
+	})
+	ctx := context.Background()
+
+	exec := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := run(ctx, append([]string{"-endpoint", srv.URL}, args...), strings.NewReader(""), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	// friendly addresses are normalized to the raw form.
+	code, out, _ := exec("account", account.ToHuman(true, false))
+	require.Equal(t, 0, code)
+	require.Contains(t, out, "1.5 TON")
+	require.Contains(t, out, account.ToHuman(false, false))
+	require.Equal(t, account.ToRaw(), srv.Requests(tonapi.GetAccountOperation)[0].PathParam("account_id"))
+
+	code, out, _ = exec("-json", "account", account.ToRaw())
+	require.Equal(t, 0, code)
+	var decoded tonapi.Account
+	require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	require.Equal(t, int64(1_500_000_000), decoded.Balance)
+
+	code, out, _ = exec("get-method", account.ToRaw(), "get_seqno", "1", "2")
+	require.Equal(t, 0, code)
+	require.Contains(t, out, "0x2a")
+	require.Equal(t, []string{"1", "2"}, srv.Requests(tonapi.ExecGetMethodForBlockchainAccountOperation)[0].Query["args"])
+
+	code, _, errOut := exec("account")
+	require.Equal(t, 2, code)
+	require.Contains(t, errOut, "usage: tonapi account")
+
+	code, _, errOut = exec("events", account.ToRaw())
+	require.Equal(t, 1, code)
+	require.Contains(t, errOut, "501")
+
+	code, _, _ = exec("unknown")
+	require.Equal(t, 2, code)
+}
+
+func TestStream(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	srv := tonapitest.NewServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stdout syncBuffer
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"-endpoint", srv.URL, "-json", "stream", "transactions", account.ToHuman(true, false)}, nil, &stdout, &stdout)
+	}()
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 1 }, 5*time.Second, 10*time.Millisecond)
+	srv.PublishTransaction(tonapi.TransactionEventData{AccountID: account, Lt: 7, TxHash: "ab"})
+	require.Eventually(t, func() bool { return strings.Contains(stdout.String(), `"tx_hash":"ab"`) }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.Equal(t, 0, <-done)
+}
+
+func TestFormatUnits(t *testing.T) {
+	require.Equal(t, "0", formatUnits(big.NewInt(0), 9))
+	require.Equal(t, "0.000000001", formatUnits(big.NewInt(1), 9))
+	require.Equal(t, "-1.25", formatUnits(big.NewInt(-1_250_000_000), 9))
+	require.Equal(t, "42", formatUnits(big.NewInt(42), 0))
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// streamCommand prints events of the streaming API, one line per event, until the context is done.
+func streamCommand(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("stream", flag.ContinueOnError)
+	ws := flags.Bool("ws", false, "use the websocket instead of SSE")
+	workchain := flags.Int("workchain", 0, "workchain of blocks, all workchains if not set")
+	operations := flags.String("operations", "", "comma separated operations of transactions, e.g. JettonTransfer,0x0f8a7ea5")
+	args, err := parseFlags(flags, args)
+	if err != nil || len(args) == 0 {
+		return errUsage
+	}
+	var blocksWorkchain *int
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "workchain" {
+			blocksWorkchain = workchain
+		}
+	})
+	var ops []string
+	if *operations != "" {
+		ops = strings.Split(*operations, ",")
+	}
+	stream, accounts := args[0], make([]string, 0, len(args)-1)
+	for _, account := range args[1:] {
+		accounts = append(accounts, normalizeAddress(account))
+	}
+	if (stream == "transactions" || stream == "traces") && len(accounts) == 0 {
+		return errUsage
+	}
+
+	var mu sync.Mutex
+	emit := func(event any, line string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if a.json {
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(a.out, "%s\n", data)
+			return
+		}
+		fmt.Fprintf(a.out, "%v %v\n", time.Now().UTC().Format(time.RFC3339), line)
+	}
+	onTransaction := func(data tonapi.TransactionEventData) {
+		emit(data, fmt.Sprintf("transaction %v lt=%v hash=%v", a.friendlyAddress(data.AccountID.ToRaw(), true), data.Lt, data.TxHash))
+	}
+	onTrace := func(data tonapi.TraceEventData) {
+		emit(data, fmt.Sprintf("trace %v accounts=%v", data.Hash, len(data.AccountIDs)))
+	}
+	onMempool := func(data tonapi.MempoolEventData) {
+		emit(data, fmt.Sprintf("mempool message of %v bytes, involved accounts=%v", len(data.BOC), len(data.InvolvedAccounts)))
+	}
+	onBlock := func(data tonapi.BlockEventData) {
+		emit(data, fmt.Sprintf("block (%v,%v,%v) root_hash=%v", data.Workchain, data.Shard, data.Seqno, data.RootHash))
+	}
+
+	if *ws {
+		return a.streaming.WebsocketHandleRequests(ctx, func(w tonapi.Websocket) error {
+			switch stream {
+			case "transactions":
+				w.SetTransactionHandler(onTransaction)
+				return w.SubscribeToTransactions(accounts, ops)
+			case "traces":
+				w.SetTraceHandler(onTrace)
+				return w.SubscribeToTraces(accounts)
+			case "mempool":
+				w.SetMempoolHandler(onMempool)
+				return w.SubscribeToMempool(accounts)
+			case "blocks":
+				w.SetBlockHandler(onBlock)
+				return w.SubscribeToBlocks(blocksWorkchain)
+			}
+			return errUsage
+		})
+	}
+	switch stream {
+	case "transactions":
+		return a.streaming.SubscribeToTransactions(ctx, accounts, ops, onTransaction)
+	case "traces":
+		return a.streaming.SubscribeToTraces(ctx, accounts, onTrace)
+	case "mempool":
+		return a.streaming.SubscribeToMempool(ctx, accounts, onMempool)
+	case "blocks":
+		return a.streaming.SubscribeToBlocks(ctx, blocksWorkchain, onBlock)
+	}
+	return errUsage
+}
@@ -0,0 +1,302 @@
+package tonapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+const (
+	consumerPageSize     = 100
+	consumerPollInterval = 10 * time.Second
+)
+
+// TransactionConsumerFunc processes a transaction of a consumed account.
+type TransactionConsumerFunc func(ctx context.Context, tx Transaction) error
+
+// MasterchainConsumerFunc processes a masterchain block.
+type MasterchainConsumerFunc func(ctx context.Context, seqno uint32) error
+
+// consumerConfig holds the options shared by AccountConsumer and MasterchainConsumer.
+type consumerConfig struct {
+	streaming *StreamingAPI
+	logger    StructuredLogger
+	poll      time.Duration
+	start     uint64
+	hasStart  bool
+}
+
+// ConsumerOption configures an AccountConsumer or a MasterchainConsumer.
+type ConsumerOption func(c *consumerConfig)
+
+// WithConsumerStreaming configures a consumer to wake up on streaming events
+// instead of waiting for the next poll.
+func WithConsumerStreaming(streaming *StreamingAPI) ConsumerOption {
+	return func(c *consumerConfig) {
+		c.streaming = streaming
+	}
+}
+
+// WithConsumerLogger configures a consumer to report handler and API errors to the given logger.
+func WithConsumerLogger(logger StructuredLogger) ConsumerOption {
+	return func(c *consumerConfig) {
+		c.logger = logger
+	}
+}
+
+// WithConsumerPollInterval configures how often a consumer checks for new data
+// and retries after a failure. The default is 10 seconds.
+func WithConsumerPollInterval(interval time.Duration) ConsumerOption {
+	return func(c *consumerConfig) {
+		c.poll = interval
+	}
+}
+
+// WithConsumerStart configures the position a consumer starts after when there is no checkpoint yet:
+// a logical time for AccountConsumer and a masterchain seqno for MasterchainConsumer.
+// By default, AccountConsumer starts from the first transaction of an account
+// and MasterchainConsumer from the current masterchain head.
+func WithConsumerStart(position uint64) ConsumerOption {
+	return func(c *consumerConfig) {
+		c.start = position
+		c.hasStart = true
+	}
+}
+
+func newConsumerConfig(opts []ConsumerOption) consumerConfig {
+	cfg := consumerConfig{logger: noopLogger{}, poll: consumerPollInterval}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return cfg
+}
+
+// checkpointError marks failures of a CheckpointStore, which stop a consumer
+// because it cannot continue without losing its position.
+type checkpointError struct {
+	err error
+}
+
+func (e *checkpointError) Error() string { return "checkpoint: " + e.err.Error() }
+func (e *checkpointError) Unwrap() error { return e.err }
+
+// AccountConsumer processes transactions of accounts in lt order.
+// It keeps the lt of the last processed transaction of every account in a CheckpointStore
+// under "<name>/<raw account>" and resumes from it on restart.
+// A checkpoint is saved only after the handler succeeds, a failed transaction is retried
+// after the poll interval, so the handler sees a transaction again if the process stops before the save.
+type AccountConsumer struct {
+	client   *Client
+	store    CheckpointStore
+	name     string
+	accounts []ton.AccountID
+	handler  TransactionConsumerFunc
+	consumerConfig
+}
+
+// NewAccountConsumer returns a consumer of the given accounts' transactions.
+// The name distinguishes checkpoints of different consumers sharing a store.
+func NewAccountConsumer(client *Client, store CheckpointStore, name string, accounts []ton.AccountID, handler TransactionConsumerFunc, opts ...ConsumerOption) *AccountConsumer {
+	return &AccountConsumer{
+		client:         client,
+		store:          store,
+		name:           name,
+		accounts:       accounts,
+		handler:        handler,
+		consumerConfig: newConsumerConfig(opts),
+	}
+}
+
+// CheckpointKey returns the key of the account's checkpoint.
+func (c *AccountConsumer) CheckpointKey(account ton.AccountID) string {
+	return c.name + "/" + account.ToRaw()
+}
+
+// Run processes transactions until the context is canceled or the checkpoint store fails.
+func (c *AccountConsumer) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wake := make(chan struct{}, 1)
+	if c.streaming != nil {
+		accounts := make([]string, 0, len(c.accounts))
+		for _, account := range c.accounts {
+			accounts = append(accounts, account.ToRaw())
+		}
+		go c.listen(ctx, wake, func(ctx context.Context, notify func()) error {
+			return c.streaming.SubscribeToTransactions(ctx, accounts, nil, func(TransactionEventData) { notify() })
+		})
+	}
+	for {
+		for _, account := range c.accounts {
+			if err := c.consume(ctx, account); err != nil {
+				var cpErr *checkpointError
+				if errors.As(err, &cpErr) {
+					return err
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				c.logger.Error("account consumer failed", "consumer", c.name, "account", account.ToRaw(), "error", err)
+			}
+		}
+		if err := c.wait(ctx, wake); err != nil {
+			return err
+		}
+	}
+}
+
+// consume processes new transactions of the account.
+func (c *AccountConsumer) consume(ctx context.Context, account ton.AccountID) error {
+	key := c.CheckpointKey(account)
+	lt, ok, err := c.store.Load(ctx, key)
+	if err != nil {
+		return &checkpointError{err}
+	}
+	if !ok {
+		lt = c.start
+	}
+	for {
+		res, err := c.client.GetBlockchainAccountTransactions(ctx, GetBlockchainAccountTransactionsParams{
+			AccountID: account.ToRaw(),
+			AfterLt:   NewOptInt64(int64(lt)),
+			Limit:     NewOptInt32(consumerPageSize),
+			SortOrder: NewOptGetBlockchainAccountTransactionsSortOrder(GetBlockchainAccountTransactionsSortOrderAsc),
+		})
+		if err != nil {
+			return err
+		}
+		for _, tx := range res.Transactions {
+			if uint64(tx.Lt) <= lt {
+				continue
+			}
+			if err := c.handler(ctx, tx); err != nil {
+				return errors.Wrapf(err, "handle transaction %v", tx.Hash)
+			}
+			lt = uint64(tx.Lt)
+			if err := c.store.Save(ctx, key, lt); err != nil {
+				return &checkpointError{err}
+			}
+		}
+		if len(res.Transactions) < consumerPageSize {
+			return nil
+		}
+	}
+}
+
+// MasterchainConsumer processes masterchain blocks one by one in order.
+// It keeps the seqno of the last processed block in a CheckpointStore under "<name>/masterchain"
+// and resumes from it on restart. Like AccountConsumer, it saves the checkpoint only after the handler succeeds.
+type MasterchainConsumer struct {
+	client  *Client
+	store   CheckpointStore
+	name    string
+	handler MasterchainConsumerFunc
+	consumerConfig
+}
+
+// NewMasterchainConsumer returns a consumer of masterchain blocks.
+func NewMasterchainConsumer(client *Client, store CheckpointStore, name string, handler MasterchainConsumerFunc, opts ...ConsumerOption) *MasterchainConsumer {
+	return &MasterchainConsumer{
+		client:         client,
+		store:          store,
+		name:           name,
+		handler:        handler,
+		consumerConfig: newConsumerConfig(opts),
+	}
+}
+
+// CheckpointKey returns the key of the consumer's checkpoint.
+func (c *MasterchainConsumer) CheckpointKey() string {
+	return c.name + "/masterchain"
+}
+
+// Run processes blocks until the context is canceled or the checkpoint store fails.
+func (c *MasterchainConsumer) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wake := make(chan struct{}, 1)
+	if c.streaming != nil {
+		masterchain := -1
+		go c.listen(ctx, wake, func(ctx context.Context, notify func()) error {
+			return c.streaming.SubscribeToBlocks(ctx, &masterchain, func(BlockEventData) { notify() })
+		})
+	}
+	for {
+		if err := c.consume(ctx); err != nil {
+			var cpErr *checkpointError
+			if errors.As(err, &cpErr) {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			c.logger.Error("masterchain consumer failed", "consumer", c.name, "error", err)
+		}
+		if err := c.wait(ctx, wake); err != nil {
+			return err
+		}
+	}
+}
+
+// consume processes blocks up to the current masterchain head.
+func (c *MasterchainConsumer) consume(ctx context.Context) error {
+	key := c.CheckpointKey()
+	seqno, ok, err := c.store.Load(ctx, key)
+	if err != nil {
+		return &checkpointError{err}
+	}
+	head, err := c.client.GetBlockchainMasterchainHead(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if c.hasStart {
+			seqno = c.start
+		} else {
+			seqno = uint64(head.Seqno) - 1
+		}
+	}
+	for next := seqno + 1; next <= uint64(head.Seqno); next++ {
+		if err := c.handler(ctx, uint32(next)); err != nil {
+			return errors.Wrapf(err, "handle masterchain block %v", next)
+		}
+		if err := c.store.Save(ctx, key, next); err != nil {
+			return &checkpointError{err}
+		}
+	}
+	return nil
+}
+
+// listen keeps a streaming subscription open and signals wake on every event.
+func (c *consumerConfig) listen(ctx context.Context, wake chan<- struct{}, subscribe func(ctx context.Context, notify func()) error) {
+	notify := func() {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	for ctx.Err() == nil {
+		if err := subscribe(ctx, notify); err != nil && ctx.Err() == nil {
+			c.logger.Error("consumer streaming connection failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(c.poll):
+		}
+	}
+}
+
+// wait blocks until a streaming event, the poll interval or the end of the context.
+func (c *consumerConfig) wait(ctx context.Context, wake <-chan struct{}) error {
+	timer := time.NewTimer(c.poll)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-wake:
+	case <-timer.C:
+	}
+	return nil
+}
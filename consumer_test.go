@@ -0,0 +1,129 @@
+package tonapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func TestAccountConsumer(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	var mu sync.Mutex
+	var transactions []Transaction
+	for lt := int64(1); lt <= 250; lt++ {
+		transactions = append(transactions, consumerTestTransaction(lt))
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v2/blockchain/accounts/"+account.ToRaw()+"/transactions", r.URL.Path)
+		require.Equal(t, "asc", r.URL.Query().Get("sort_order"))
+		afterLt, _ := strconv.ParseInt(r.URL.Query().Get("after_lt"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		mu.Lock()
+		page := Transactions{Transactions: []Transaction{}}
+		for _, tx := range transactions {
+			if tx.Lt > afterLt && len(page.Transactions) < limit {
+				page.Transactions = append(page.Transactions, tx)
+			}
+		}
+		mu.Unlock()
+		data, _ := page.MarshalJSON()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	store := NewMemoryCheckpointStore()
+
+	// the handler fails on lt 150 once, the checkpoint stays at the last successful transaction.
+	var handled []int64
+	failed := false
+	handler := func(ctx context.Context, tx Transaction) error {
+		if tx.Lt == 150 && !failed {
+			failed = true
+			return errors.New("database is down")
+		}
+		handled = append(handled, tx.Lt)
+		return nil
+	}
+	consumer := NewAccountConsumer(client, store, "indexer", []ton.AccountID{account}, handler, WithConsumerStart(100))
+	ctx := context.Background()
+	require.ErrorContains(t, consumer.consume(ctx, account), "database is down")
+	position, _, _ := store.Load(ctx, "indexer/"+account.ToRaw())
+	require.Equal(t, uint64(149), position)
+
+	require.NoError(t, consumer.consume(ctx, account))
+	require.Len(t, handled, 150)
+	require.Equal(t, int64(101), handled[0])
+	require.Equal(t, int64(250), handled[len(handled)-1])
+
+	// a restarted consumer resumes from the checkpoint and Run picks up new transactions.
+	mu.Lock()
+	transactions = append(transactions, consumerTestTransaction(251))
+	mu.Unlock()
+	handled = nil
+	ctx, cancel := context.WithCancel(ctx)
+	consumer = NewAccountConsumer(client, store, "indexer", []ton.AccountID{account}, func(ctx context.Context, tx Transaction) error {
+		handled = append(handled, tx.Lt)
+		cancel()
+		return nil
+	}, WithConsumerPollInterval(time.Millisecond))
+	require.ErrorIs(t, consumer.Run(ctx), context.Canceled)
+	require.Equal(t, []int64{251}, handled)
+	position, _, _ = store.Load(context.Background(), consumer.CheckpointKey(account))
+	require.Equal(t, uint64(251), position)
+}
+
+func consumerTestTransaction(lt int64) Transaction {
+	return Transaction{
+		Hash:            strconv.FormatInt(lt, 10),
+		Lt:              lt,
+		OrigStatus:      AccountStatusActive,
+		EndStatus:       AccountStatusActive,
+		TransactionType: TransactionTypeTransOrd,
+	}
+}
+
+func TestMasterchainConsumer(t *testing.T) {
+	var mu sync.Mutex
+	head := int32(10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v2/blockchain/masterchain-head", r.URL.Path)
+		mu.Lock()
+		data, _ := (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: head}).MarshalJSON()
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	store := NewMemoryCheckpointStore()
+	ctx := context.Background()
+
+	// without a checkpoint the consumer starts from the head.
+	var handled []uint32
+	consumer := NewMasterchainConsumer(client, store, "blocks", func(ctx context.Context, seqno uint32) error {
+		handled = append(handled, seqno)
+		return nil
+	})
+	require.NoError(t, consumer.consume(ctx))
+	require.Equal(t, []uint32{10}, handled)
+
+	mu.Lock()
+	head = 13
+	mu.Unlock()
+	require.NoError(t, consumer.consume(ctx))
+	require.Equal(t, []uint32{10, 11, 12, 13}, handled)
+	position, ok, _ := store.Load(ctx, consumer.CheckpointKey())
+	require.True(t, ok)
+	require.Equal(t, uint64(13), position)
+}
@@ -0,0 +1,182 @@
+package tonapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	ht "github.com/ogen-go/ogen/http"
+)
+
+// maxLoggedBodySize limits the size of request and response bodies written by the debug logger.
+const maxLoggedBodySize = 4096
+
+// redactedFields lists JSON fields whose values are replaced by the debug logger.
+// They contain serialized messages and state inits which are large and may carry signed payloads.
+var redactedFields = map[string]struct{}{
+	"boc":        {},
+	"batch":      {},
+	"payload":    {},
+	"state_init": {},
+	"body":       {},
+}
+
+// DebugLogger logs every HTTP request and response of a Client at the debug level.
+// The bearer token, the "token" query parameter and BOC payloads are redacted, bodies are truncated to 4KB.
+// If the logger has an Enabled method like *slog.Logger, nothing is buffered while the debug level is disabled.
+//
+//	debug := tonapi.NewDebugLogger(slog.Default())
+//	client, err := tonapi.NewClient(tonapi.TonApiURL, tonapi.WithToken(token), tonapi.WithClient(debug.Wrap(http.DefaultClient)))
+type DebugLogger struct {
+	logger StructuredLogger
+}
+
+// NewDebugLogger returns a new DebugLogger writing to the logger.
+func NewDebugLogger(logger StructuredLogger) *DebugLogger {
+	return &DebugLogger{logger: logger}
+}
+
+// Wrap returns an HTTP client logging requests sent with next. Use it with WithClient.
+func (d *DebugLogger) Wrap(next ht.Client) ht.Client {
+	return &debugLoggingClient{logger: d.logger, next: next}
+}
+
+type debugLoggingClient struct {
+	logger StructuredLogger
+	next   ht.Client
+}
+
+func (c *debugLoggingClient) enabled(ctx context.Context) bool {
+	if l, ok := c.logger.(interface {
+		Enabled(context.Context, slog.Level) bool
+	}); ok {
+		return l.Enabled(ctx, slog.LevelDebug)
+	}
+	return true
+}
+
+func (c *debugLoggingClient) Do(req *http.Request) (*http.Response, error) {
+	if !c.enabled(req.Context()) {
+		return c.next.Do(req)
+	}
+	operation := customRequestOperation
+	if route, ok := matchOperation(req.Method, req.URL.Path); ok {
+		operation = string(route.Operation)
+	}
+	reqBody := ""
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			reqBody = redactBody(data)
+		}
+	}
+	c.logger.Debug("tonapi request",
+		"operation", operation,
+		"method", req.Method,
+		"url", redactURL(req.URL),
+		"authorization", redactAuthorization(req.Header.Get("Authorization")),
+		"body", reqBody,
+	)
+
+	start := time.Now()
+	resp, err := c.next.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		c.logger.Debug("tonapi request failed", "operation", operation, "duration", elapsed, "error", err)
+		return resp, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		c.logger.Debug("tonapi response body read failed", "operation", operation, "error", err)
+		return resp, nil
+	}
+	c.logger.Debug("tonapi response",
+		"operation", operation,
+		"status", resp.StatusCode,
+		"duration", elapsed,
+		"body", redactBody(data),
+	)
+	return resp, nil
+}
+
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	if !query.Has("token") {
+		return u.String()
+	}
+	query.Set("token", maskToken(query.Get("token")))
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+func redactAuthorization(value string) string {
+	if value == "" {
+		return ""
+	}
+	if token, ok := bearerToken(&http.Request{Header: http.Header{"Authorization": {value}}}); ok {
+		return "Bearer " + maskToken(token)
+	}
+	return "<redacted>"
+}
+
+// redactBody returns a JSON body with BOC payloads replaced and truncated to maxLoggedBodySize.
+func redactBody(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	// numbers are kept as json.Number, so large integers like amounts and lt are logged as is.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(data))
+	}
+	var redacted bytes.Buffer
+	encoder := json.NewEncoder(&redacted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(redactValue(value)); err == nil {
+		data = bytes.TrimSuffix(redacted.Bytes(), []byte("\n"))
+	}
+	if len(data) > maxLoggedBodySize {
+		return string(data[:maxLoggedBodySize]) + fmt.Sprintf("...<%d bytes more>", len(data)-maxLoggedBodySize)
+	}
+	return string(data)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if _, ok := redactedFields[key]; ok {
+				v[key] = redactedPlaceholder(item)
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func redactedPlaceholder(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("<redacted %d chars>", len(v))
+	case []any:
+		return fmt.Sprintf("<redacted %d items>", len(v))
+	}
+	return "<redacted>"
+}
@@ -0,0 +1,93 @@
+package tonapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"lt":18446744073709551615,"state_init":"te6ccgEBAQEAAgAAAA==","nested":[{"payload":"abc"}]}`))
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client, err := NewClient(srv.URL, WithToken("secret-token-1234"), WithClient(NewDebugLogger(logger).Wrap(http.DefaultClient)))
+	require.NoError(t, err)
+	_, err = client.Request(context.Background(), http.MethodPost, "v2/custom", map[string][]string{"token": {"query-token-5678"}}, []byte(`{"boc":"te6ccgEBAQEAAgAAAA==","amount":123456789012345678901234567890}`))
+	require.NoError(t, err)
+	// the response does not match the schema, only the logged request matters.
+	_, _ = client.GetBlockchainMasterchainHead(context.Background())
+
+	logged := out.String()
+	require.NotContains(t, logged, "secret-token")
+	require.Contains(t, logged, "Bearer *************1234")
+	require.NotContains(t, logged, "query-token")
+	require.Contains(t, logged, "token=%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A5678")
+	require.NotContains(t, logged, "te6cc")
+	require.Contains(t, logged, "123456789012345678901234567890")
+	require.Contains(t, logged, "18446744073709551615")
+
+	// nothing is logged above the debug level.
+	out.Reset()
+	quiet := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	client, err = NewClient(srv.URL, &Security{}, WithClient(NewDebugLogger(quiet).Wrap(http.DefaultClient)))
+	require.NoError(t, err)
+	_, err = client.Request(context.Background(), http.MethodGet, "v2/custom", nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, out.String())
+}
+
+func TestRedactBody(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "empty", body: "", want: ""},
+		{name: "not json", body: "te6cc", want: "<5 bytes>"},
+		{name: "boc", body: `{"boc":"te6cc"}`, want: `{"boc":"<redacted 5 chars>"}`},
+		{name: "batch", body: `{"batch":["a","b"]}`, want: `{"batch":"<redacted 2 items>"}`},
+		{name: "nested", body: `{"messages":[{"body":{"x":1},"lt":1}]}`, want: `{"messages":[{"body":"<redacted>","lt":1}]}`},
+		{name: "big numbers", body: `{"amount":123456789012345678901234567890,"fee":0.1}`, want: `{"amount":123456789012345678901234567890,"fee":0.1}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, redactBody([]byte(tt.body)))
+		})
+	}
+	long := redactBody([]byte(`"` + strings.Repeat("a", maxLoggedBodySize) + `"`))
+	require.True(t, strings.HasSuffix(long, "...<2 bytes more>"))
+}
+
+func TestRedactAuthorization(t *testing.T) {
+	require.Equal(t, "", redactAuthorization(""))
+	require.Equal(t, "Bearer ****5678", redactAuthorization("Bearer 12345678"))
+	require.Equal(t, "Bearer ***", redactAuthorization("bearer abc"))
+	require.Equal(t, "<redacted>", redactAuthorization("Basic dXNlcjpwYXNz"))
+}
+
+func TestErrorfLogger(t *testing.T) {
+	var logged errorfRecorder
+	logger := errorfLogger{logger: &logged}
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn", "stream", "traces")
+	logger.Error("failed", "error", "boom")
+	require.Equal(t, errorfRecorder{"failed error=boom"}, logged)
+}
+
+type errorfRecorder []string
+
+func (r *errorfRecorder) Errorf(format string, args ...interface{}) {
+	*r = append(*r, fmt.Sprintf(format, args...))
+}
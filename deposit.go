@@ -0,0 +1,374 @@
+package tonapi
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+// DepositRejection is the reason a transfer to a deposit wallet is not credited.
+type DepositRejection string
+
+const (
+	// DepositScam is a transfer in an event marked as scam or of a blacklisted jetton.
+	DepositScam DepositRejection = "scam"
+	// DepositFlawed is a jetton transfer whose received amount differs from the sent one.
+	DepositFlawed DepositRejection = "flawed"
+	// DepositFailed is a transfer that did not succeed, for example a bounced one.
+	DepositFailed DepositRejection = "failed"
+	// DepositMissingMemo is a transfer without a comment to a wallet requiring a memo.
+	DepositMissingMemo DepositRejection = "missing_memo"
+	// DepositUnsupportedJetton is a transfer of a jetton not listed with WithDepositJettons.
+	DepositUnsupportedJetton DepositRejection = "unsupported_jetton"
+)
+
+// Deposit is an incoming transfer to a deposit wallet.
+type Deposit struct {
+	// ID is "<event id>:<action index>". It is stable across restarts, use it to store deposits idempotently.
+	ID      string
+	EventID string
+	Lt      int64
+	Time    time.Time
+	Wallet  ton.AccountID
+	// Sender is the raw address of the account the asset comes from, the owner for jetton transfers.
+	Sender string
+	// Asset is "TON", a jetton master address or "extra:<id>" for extra currencies, as in LedgerRow.
+	Asset  string
+	Symbol string
+	// Amount is in the smallest units of the asset.
+	Amount   *big.Int
+	Decimals int
+	// Memo is the comment of the transfer.
+	Memo string
+	// Rejection is set for transfers passed to the handler of WithDepositRejectHandler.
+	Rejection DepositRejection
+}
+
+// DepositFunc processes a deposit. Deposits are delivered in lt order per wallet
+// and may be delivered again after a restart, see Deposit.ID.
+type DepositFunc func(ctx context.Context, d Deposit) error
+
+// DepositWatcher detects TON, jetton and extra currency deposits to a set of wallets.
+//
+// It reads the events of every wallet with GetAccountEvents in lt order and reports the transfers received by the wallet
+// once the event's trace is finished and the masterchain has advanced by the configured number of blocks.
+// Transfers from events marked as scam, flawed jetton transfers, failed transfers and,
+// for wallets requiring a memo, transfers without a comment are not credited.
+//
+// Both deposit schemes are supported: a wallet per user, where the wallet identifies the user,
+// and a single wallet where the memo identifies the user. A wallet requires a memo
+// if WithDepositMemoRequired is set or its account has the memo_required flag.
+//
+// The lt of the last processed event of every wallet is kept in a CheckpointStore under "<name>/<raw wallet>",
+// like AccountConsumer, and saved after the handlers of all its deposits succeed.
+type DepositWatcher struct {
+	client        *Client
+	store         CheckpointStore
+	name          string
+	wallets       []ton.AccountID
+	handler       DepositFunc
+	reject        DepositFunc
+	confirmations uint32
+	memoRequired  bool
+	jettons       map[ton.AccountID]struct{}
+	consumerConfig
+
+	// memo caches the memo_required flag of the wallets.
+	memo map[ton.AccountID]bool
+	// completed holds the masterchain seqno at which the trace of an event was first seen finished.
+	completed map[string]uint32
+}
+
+// DepositOption configures a DepositWatcher.
+type DepositOption func(w *DepositWatcher)
+
+// WithDepositConfirmations configures the number of masterchain blocks that must follow
+// the finished trace of a deposit before it is reported. The default is 0.
+func WithDepositConfirmations(blocks uint32) DepositOption {
+	return func(w *DepositWatcher) {
+		w.confirmations = blocks
+	}
+}
+
+// WithDepositMemoRequired configures a watcher to reject transfers without a comment to any of its wallets.
+func WithDepositMemoRequired() DepositOption {
+	return func(w *DepositWatcher) {
+		w.memoRequired = true
+	}
+}
+
+// WithDepositJettons configures a watcher to accept only the jettons with the given masters.
+// By default, any jetton that is not blacklisted is accepted.
+func WithDepositJettons(masters ...ton.AccountID) DepositOption {
+	return func(w *DepositWatcher) {
+		w.jettons = map[ton.AccountID]struct{}{}
+		for _, master := range masters {
+			w.jettons[master] = struct{}{}
+		}
+	}
+}
+
+// WithDepositRejectHandler configures a watcher to pass transfers that are not credited to the handler,
+// for example to refund them. The reason is in Deposit.Rejection.
+func WithDepositRejectHandler(handler DepositFunc) DepositOption {
+	return func(w *DepositWatcher) {
+		w.reject = handler
+	}
+}
+
+// WithDepositConsumerOptions configures a watcher with the options of AccountConsumer:
+// WithConsumerStreaming wakes the watcher up on new transactions of the wallets,
+// WithConsumerPollInterval sets how often it checks for new events, unfinished traces and confirmations
+// and WithConsumerStart sets the lt it starts after when a wallet has no checkpoint yet.
+// By default, a watcher starts from the first event of a wallet and polls every 10 seconds.
+func WithDepositConsumerOptions(opts ...ConsumerOption) DepositOption {
+	return func(w *DepositWatcher) {
+		for _, o := range opts {
+			o(&w.consumerConfig)
+		}
+	}
+}
+
+// NewDepositWatcher returns a watcher reporting deposits to the given wallets to the handler.
+// The name distinguishes checkpoints of different watchers sharing a store.
+func NewDepositWatcher(client *Client, store CheckpointStore, name string, wallets []ton.AccountID, handler DepositFunc, opts ...DepositOption) *DepositWatcher {
+	w := &DepositWatcher{
+		client:         client,
+		store:          store,
+		name:           name,
+		wallets:        wallets,
+		handler:        handler,
+		consumerConfig: newConsumerConfig(nil),
+		memo:           map[ton.AccountID]bool{},
+		completed:      map[string]uint32{},
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// CheckpointKey returns the key of the wallet's checkpoint.
+func (w *DepositWatcher) CheckpointKey(wallet ton.AccountID) string {
+	return w.name + "/" + wallet.ToRaw()
+}
+
+// Run watches the wallets until the context is canceled or the checkpoint store fails.
+func (w *DepositWatcher) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wake := make(chan struct{}, 1)
+	if w.streaming != nil {
+		accounts := make([]string, 0, len(w.wallets))
+		for _, wallet := range w.wallets {
+			accounts = append(accounts, wallet.ToRaw())
+		}
+		go w.listen(ctx, wake, func(ctx context.Context, notify func()) error {
+			return w.streaming.SubscribeToTransactions(ctx, accounts, nil, func(TransactionEventData) { notify() })
+		})
+	}
+	for {
+		for _, wallet := range w.wallets {
+			if err := w.consume(ctx, wallet); err != nil {
+				var cpErr *checkpointError
+				if errors.As(err, &cpErr) {
+					return err
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				w.logger.Error("deposit watcher failed", "watcher", w.name, "wallet", wallet.ToRaw(), "error", err)
+			}
+		}
+		if err := w.wait(ctx, wake); err != nil {
+			return err
+		}
+	}
+}
+
+// consume reports deposits of the wallet's new events up to the first event that is not finished or confirmed.
+func (w *DepositWatcher) consume(ctx context.Context, wallet ton.AccountID) error {
+	memoRequired, err := w.walletMemoRequired(ctx, wallet)
+	if err != nil {
+		return err
+	}
+	key := w.CheckpointKey(wallet)
+	lt, ok, err := w.store.Load(ctx, key)
+	if err != nil {
+		return &checkpointError{err}
+	}
+	if !ok {
+		lt = w.start
+	}
+	var head *uint32
+	for {
+		page, err := w.client.GetAccountEvents(ctx, GetAccountEventsParams{
+			AccountID: wallet.ToRaw(),
+			AfterLt:   NewOptInt64(int64(lt)),
+			Limit:     accountEventsPageSize,
+			SortOrder: NewOptGetAccountEventsSortOrder(GetAccountEventsSortOrderAsc),
+		})
+		if err != nil {
+			return err
+		}
+		blocked := false
+		for _, event := range page.Events {
+			if uint64(event.Lt) <= lt {
+				continue
+			}
+			if event.InProgress {
+				return nil
+			}
+			confirmed, err := w.confirmed(ctx, event.EventID, &head)
+			if err != nil {
+				return err
+			}
+			// keep marking the finished events behind an unconfirmed one, so they are confirmed together.
+			if blocked || !confirmed {
+				blocked = true
+				continue
+			}
+			for _, d := range w.deposits(wallet, memoRequired, event) {
+				handler := w.handler
+				if d.Rejection != "" {
+					handler = w.reject
+				}
+				if handler == nil {
+					continue
+				}
+				if err := handler(ctx, d); err != nil {
+					return errors.Wrapf(err, "handle deposit %v", d.ID)
+				}
+			}
+			delete(w.completed, event.EventID)
+			lt = uint64(event.Lt)
+			if err := w.store.Save(ctx, key, lt); err != nil {
+				return &checkpointError{err}
+			}
+		}
+		if blocked || len(page.Events) < accountEventsPageSize {
+			return nil
+		}
+	}
+}
+
+// walletMemoRequired reports whether deposits to the wallet must have a memo.
+func (w *DepositWatcher) walletMemoRequired(ctx context.Context, wallet ton.AccountID) (bool, error) {
+	if w.memoRequired {
+		return true, nil
+	}
+	if required, ok := w.memo[wallet]; ok {
+		return required, nil
+	}
+	account, err := w.client.GetAccount(ctx, GetAccountParams{AccountID: wallet.ToRaw()})
+	if err != nil {
+		return false, errors.Wrapf(err, "get account %v", wallet.ToRaw())
+	}
+	w.memo[wallet] = account.MemoRequired.Value
+	return account.MemoRequired.Value, nil
+}
+
+// confirmed reports whether the masterchain has advanced by the required number of blocks
+// since the event was first seen finished. The head is fetched at most once per consume call.
+func (w *DepositWatcher) confirmed(ctx context.Context, eventID string, head **uint32) (bool, error) {
+	if w.confirmations == 0 {
+		return true, nil
+	}
+	if *head == nil {
+		block, err := w.client.GetBlockchainMasterchainHead(ctx)
+		if err != nil {
+			return false, err
+		}
+		seqno := uint32(block.Seqno)
+		*head = &seqno
+	}
+	seen, ok := w.completed[eventID]
+	if !ok {
+		w.completed[eventID] = **head
+		return false, nil
+	}
+	return **head >= seen+w.confirmations, nil
+}
+
+// deposits returns the transfers of the event received by the wallet.
+func (w *DepositWatcher) deposits(wallet ton.AccountID, memoRequired bool, event AccountEvent) []Deposit {
+	var res []Deposit
+	for i, action := range event.Actions {
+		var rejection DepositRejection
+		var assets []actionAsset
+		switch action.Type {
+		case ActionTypeTonTransfer, ActionTypeJettonTransfer, ActionTypeExtraCurrencyTransfer:
+			assets = actionAssets(action)
+		case ActionTypeFlawedJettonTransfer:
+			a := action.FlawedJettonTransfer.Value
+			assets = actionAssets(Action{Type: ActionTypeJettonTransfer, JettonTransfer: NewOptJettonTransferAction(JettonTransferAction{
+				Sender:    a.Sender,
+				Recipient: a.Recipient,
+				Amount:    a.ReceivedAmount,
+				Comment:   a.Comment,
+				Jetton:    a.Jetton,
+			})})
+			rejection = DepositFlawed
+		}
+		for _, asset := range assets {
+			if !sameAccount(asset.Recipient, wallet) || sameAccount(asset.Sender, wallet) {
+				continue
+			}
+			d := Deposit{
+				ID:        event.EventID + ":" + strconv.Itoa(i),
+				EventID:   event.EventID,
+				Lt:        event.Lt,
+				Time:      time.Unix(event.Timestamp, 0).UTC(),
+				Wallet:    wallet,
+				Sender:    asset.Sender,
+				Symbol:    asset.Symbol,
+				Amount:    asset.Amount,
+				Decimals:  asset.Decimals,
+				Memo:      asset.Comment,
+				Rejection: rejection,
+			}
+			switch {
+			case asset.Token == gramToken:
+				d.Asset = "TON"
+			case asset.Token == "":
+				d.Asset = "extra:" + strconv.Itoa(int(asset.CurrencyID))
+			default:
+				d.Asset = asset.Token
+			}
+			if d.Rejection == "" {
+				d.Rejection = w.check(action, d, event.IsScam, memoRequired)
+			}
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// check returns the reason the deposit is not credited, if any.
+func (w *DepositWatcher) check(action Action, d Deposit, scam, memoRequired bool) DepositRejection {
+	if scam {
+		return DepositScam
+	}
+	if action.Status != ActionStatusOk {
+		return DepositFailed
+	}
+	if action.Type == ActionTypeJettonTransfer {
+		if action.JettonTransfer.Value.Jetton.Verification == JettonVerificationTypeBlacklist {
+			return DepositScam
+		}
+		if w.jettons != nil {
+			master, err := ton.ParseAccountID(d.Asset)
+			if _, ok := w.jettons[master]; err != nil || !ok {
+				return DepositUnsupportedJetton
+			}
+		}
+	}
+	if memoRequired && d.Memo == "" {
+		return DepositMissingMemo
+	}
+	return ""
+}
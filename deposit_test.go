@@ -0,0 +1,170 @@
+package tonapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func TestDepositWatcher(t *testing.T) {
+	wallet := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	user := AccountAddress{Address: "0:2222222222222222222222222222222222222222222222222222222222222222"}
+	usdt := JettonPreview{
+		Address:      "0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe",
+		Symbol:       "USD₮",
+		Decimals:     6,
+		Verification: JettonVerificationTypeWhitelist,
+	}
+	fake := JettonPreview{Address: "0:3333333333333333333333333333333333333333333333333333333333333333", Symbol: "USD₮", Verification: JettonVerificationTypeNone}
+	recipient := AccountAddress{Address: wallet.ToRaw()}
+	tonTransfer := func(amount int64, comment string) Action {
+		a := TonTransferAction{Sender: user, Recipient: recipient, Amount: amount}
+		if comment != "" {
+			a.Comment = NewOptString(comment)
+		}
+		return Action{Type: ActionTypeTonTransfer, Status: ActionStatusOk, TonTransfer: NewOptTonTransferAction(a)}
+	}
+	jettonTransfer := func(jetton JettonPreview, comment string) Action {
+		return Action{Type: ActionTypeJettonTransfer, Status: ActionStatusOk, JettonTransfer: NewOptJettonTransferAction(JettonTransferAction{
+			Sender:    NewOptAccountAddress(user),
+			Recipient: NewOptAccountAddress(recipient),
+			Amount:    "2500000",
+			Comment:   NewOptString(comment),
+			Jetton:    jetton,
+		})}
+	}
+	event := func(id string, lt int64, actions ...Action) AccountEvent {
+		return AccountEvent{EventID: id, Lt: lt, Timestamp: 1_700_000_000 + lt, Account: recipient, Actions: actions}
+	}
+	scam := event("scam", 30, tonTransfer(1, "user1"))
+	scam.IsScam = true
+	bounced := tonTransfer(7, "user1")
+	bounced.Status = ActionStatusFailed
+	pending := event("pending", 80, tonTransfer(3, "user3"))
+	pending.InProgress = true
+
+	var mu sync.Mutex
+	head := int32(100)
+	events := []AccountEvent{
+		event("ton", 10, tonTransfer(1_000_000_000, "user1"), tonTransfer(5, "")),
+		event("jetton", 20, jettonTransfer(usdt, "user2")),
+		scam,
+		event("flawed", 40, Action{Type: ActionTypeFlawedJettonTransfer, Status: ActionStatusOk, FlawedJettonTransfer: NewOptFlawedJettonTransferAction(FlawedJettonTransferAction{
+			Sender:         NewOptAccountAddress(user),
+			Recipient:      NewOptAccountAddress(recipient),
+			SentAmount:     "100",
+			ReceivedAmount: "90",
+			Comment:        NewOptString("user2"),
+			Jetton:         usdt,
+		})}),
+		event("fake", 50, jettonTransfer(fake, "user2")),
+		event("bounced", 60, bounced),
+		event("extra", 70, Action{Type: ActionTypeExtraCurrencyTransfer, Status: ActionStatusOk, ExtraCurrencyTransfer: NewOptExtraCurrencyTransferAction(ExtraCurrencyTransferAction{
+			Sender:    user,
+			Recipient: recipient,
+			Amount:    "42",
+			Comment:   NewOptString("user3"),
+			Currency:  EcPreview{ID: 239, Symbol: "FMS", Decimals: 5},
+		})}),
+		pending,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var data []byte
+		switch {
+		case r.URL.Path == "/v2/blockchain/masterchain-head":
+			data, _ = (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: head}).MarshalJSON()
+		case r.URL.Path == "/v2/accounts/"+wallet.ToRaw()+"/events":
+			require.Equal(t, "asc", r.URL.Query().Get("sort_order"))
+			afterLt, _ := strconv.ParseInt(r.URL.Query().Get("after_lt"), 10, 64)
+			var page AccountEvents
+			for _, e := range events {
+				if e.Lt > afterLt {
+					page.Events = append(page.Events, e)
+				}
+			}
+			data, _ = page.MarshalJSON()
+		case r.URL.Path == "/v2/accounts/"+wallet.ToRaw():
+			data, _ = (&Account{Address: wallet.ToRaw(), Status: AccountStatusActive, MemoRequired: NewOptBool(true)}).MarshalJSON()
+		default:
+			t.Errorf("unexpected request %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore()
+
+	var credited, rejected []Deposit
+	newWatcher := func() *DepositWatcher {
+		return NewDepositWatcher(client, store, "deposits", []ton.AccountID{wallet}, func(ctx context.Context, d Deposit) error {
+			credited = append(credited, d)
+			return nil
+		}, WithDepositConfirmations(2), WithDepositJettons(ton.MustParseAccountID(usdt.Address)), WithDepositRejectHandler(func(ctx context.Context, d Deposit) error {
+			rejected = append(rejected, d)
+			return nil
+		}))
+	}
+	watcher := newWatcher()
+
+	// finished events wait for two masterchain blocks.
+	require.NoError(t, watcher.consume(ctx, wallet))
+	require.Empty(t, credited)
+	mu.Lock()
+	head = 102
+	mu.Unlock()
+	require.NoError(t, watcher.consume(ctx, wallet))
+
+	// the memo is required by the account, the pending event stops the watcher.
+	require.Len(t, credited, 3)
+	require.Equal(t, "ton:0", credited[0].ID)
+	require.Equal(t, "TON", credited[0].Asset)
+	require.Equal(t, int64(1_000_000_000), credited[0].Amount.Int64())
+	require.Equal(t, "user1", credited[0].Memo)
+	require.Equal(t, user.Address, credited[0].Sender)
+	require.Equal(t, usdt.Address, credited[1].Asset)
+	require.Equal(t, "user2", credited[1].Memo)
+	require.Equal(t, "extra:239", credited[2].Asset)
+	require.Equal(t, "user3", credited[2].Memo)
+	reasons := map[string]DepositRejection{}
+	for _, d := range rejected {
+		reasons[d.ID] = d.Rejection
+	}
+	require.Equal(t, map[string]DepositRejection{
+		"ton:1":     DepositMissingMemo,
+		"scam:0":    DepositScam,
+		"flawed:0":  DepositFlawed,
+		"fake:0":    DepositUnsupportedJetton,
+		"bounced:0": DepositFailed,
+	}, reasons)
+	position, _, _ := store.Load(ctx, watcher.CheckpointKey(wallet))
+	require.Equal(t, uint64(70), position)
+
+	// the pending trace finishes and is confirmed two blocks later, a restarted watcher continues from the checkpoint.
+	mu.Lock()
+	events[len(events)-1].InProgress = false
+	mu.Unlock()
+	credited, rejected = nil, nil
+	watcher = newWatcher()
+	require.NoError(t, watcher.consume(ctx, wallet))
+	require.Empty(t, credited)
+	mu.Lock()
+	head = 104
+	mu.Unlock()
+	require.NoError(t, watcher.consume(ctx, wallet))
+	require.Len(t, credited, 1)
+	require.Equal(t, "pending:0", credited[0].ID)
+	require.Empty(t, rejected)
+}
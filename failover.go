@@ -0,0 +1,430 @@
+package tonapi
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	ht "github.com/ogen-go/ogen/http"
+)
+
+// Endpoint describes a single tonapi-compatible server,
+// either tonapi.io or a self-hosted opentonapi instance.
+type Endpoint struct {
+	// URL is the base URL of the server, e.g. https://tonapi.io.
+	URL string
+	// Weight is a relative share of read requests routed to this endpoint.
+	// Zero is treated as 1.
+	Weight int
+}
+
+// EndpointStatus is a snapshot of the health of a particular endpoint.
+type EndpointStatus struct {
+	URL     string
+	Weight  int
+	Healthy bool
+	// Seqno is the last masterchain seqno reported by the endpoint.
+	Seqno int32
+	// Lag is the number of masterchain blocks the endpoint is behind the most up-to-date endpoint of the pool.
+	Lag int32
+	// ConsecutiveFailures is the number of failed requests since the last successful one.
+	ConsecutiveFailures int
+	LastError           error
+	LastCheck           time.Time
+}
+
+// ErrNoEndpoints is returned by EndpointPool when there is no endpoint to route a request to.
+var ErrNoEndpoints = errors.New("no endpoints available")
+
+type endpointState struct {
+	url    *url.URL
+	weight int
+	api    *Client
+
+	healthy             bool
+	seqno               int32
+	lag                 int32
+	consecutiveFailures int
+	lastError           error
+	lastCheck           time.Time
+}
+
+// EndpointPool is an HTTP client that spreads requests across several tonapi-compatible servers.
+//
+// Read requests are routed to healthy endpoints proportionally to their weights
+// and are transparently retried on another endpoint when a server fails or responds with 429 or 5xx.
+// Requests that send messages to the blockchain are never retried and always go to the send endpoint,
+// so a message is never broadcast twice through different servers.
+//
+// The pool implements ht.Client and is plugged into a Client with WithClient:
+//
+//	pool, err := tonapi.NewEndpointPool([]tonapi.Endpoint{
+//	    {URL: "http://opentonapi-1:8081", Weight: 3},
+//	    {URL: "http://opentonapi-2:8081", Weight: 3},
+//	    {URL: tonapi.TonApiURL, Weight: 1},
+//	})
+//	go pool.Run(ctx)
+//	client, err := tonapi.NewClient(tonapi.TonApiURL, tonapi.WithToken(token), tonapi.WithClient(pool))
+//
+// The server URL given to NewClient is ignored, the pool replaces the scheme and host of every request.
+type EndpointPool struct {
+	client         ht.Client
+	logger         Logger
+	healthInterval time.Duration
+	maxLag         int32
+	maxFailures    int
+
+	// mu protects the fields below.
+	mu        sync.Mutex
+	endpoints []*endpointState
+	send      *endpointState
+	rnd       *rand.Rand
+}
+
+type EndpointPoolOptions struct {
+	client         ht.Client
+	security       SecuritySource
+	logger         Logger
+	healthInterval time.Duration
+	maxLag         int32
+	maxFailures    int
+	sendEndpoint   string
+}
+
+type EndpointPoolOption func(*EndpointPoolOptions)
+
+// WithPoolHTTPClient configures an EndpointPool instance to send requests with the given client instead of http.DefaultClient.
+func WithPoolHTTPClient(client ht.Client) EndpointPoolOption {
+	return func(o *EndpointPoolOptions) {
+		o.client = client
+	}
+}
+
+// WithPoolSecurity configures a security source used by health checks.
+func WithPoolSecurity(sec SecuritySource) EndpointPoolOption {
+	return func(o *EndpointPoolOptions) {
+		o.security = sec
+	}
+}
+
+// WithPoolLogger configures an EndpointPool instance to report endpoint state changes to the given logger.
+func WithPoolLogger(logger Logger) EndpointPoolOption {
+	return func(o *EndpointPoolOptions) {
+		o.logger = logger
+	}
+}
+
+// WithPoolHealthInterval configures how often Run checks the health of endpoints. The default is 10 seconds.
+func WithPoolHealthInterval(interval time.Duration) EndpointPoolOption {
+	return func(o *EndpointPoolOptions) {
+		o.healthInterval = interval
+	}
+}
+
+// WithPoolMaxLag configures how many masterchain blocks an endpoint can be behind the most up-to-date one
+// before it is excluded from routing. The default is 3.
+func WithPoolMaxLag(blocks int32) EndpointPoolOption {
+	return func(o *EndpointPoolOptions) {
+		o.maxLag = blocks
+	}
+}
+
+// WithPoolMaxFailures configures how many consecutive failed requests mark an endpoint as unhealthy
+// until the next successful health check. The default is 3.
+func WithPoolMaxFailures(failures int) EndpointPoolOption {
+	return func(o *EndpointPoolOptions) {
+		o.maxFailures = failures
+	}
+}
+
+// WithPoolSendEndpoint configures an endpoint used to send messages to the blockchain.
+// The URL must be one of the pool's endpoints. By default, the first endpoint is used.
+func WithPoolSendEndpoint(endpointURL string) EndpointPoolOption {
+	return func(o *EndpointPoolOptions) {
+		o.sendEndpoint = endpointURL
+	}
+}
+
+// NewEndpointPool returns a new EndpointPool.
+// All endpoints are considered healthy until the first health check says otherwise.
+func NewEndpointPool(endpoints []Endpoint, opts ...EndpointPoolOption) (*EndpointPool, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	options := &EndpointPoolOptions{
+		client:         http.DefaultClient,
+		security:       &Security{},
+		logger:         &noopLogger{},
+		healthInterval: 10 * time.Second,
+		maxLag:         3,
+		maxFailures:    3,
+	}
+	for _, o := range opts {
+		o(options)
+	}
+	pool := &EndpointPool{
+		client:         options.client,
+		logger:         options.logger,
+		healthInterval: options.healthInterval,
+		maxLag:         options.maxLag,
+		maxFailures:    options.maxFailures,
+		rnd:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, e := range endpoints {
+		u, err := url.Parse(e.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %q: %w", e.URL, err)
+		}
+		trimTrailingSlashes(u)
+		api, err := NewClient(u.String(), options.security, WithClient(options.client))
+		if err != nil {
+			return nil, err
+		}
+		weight := e.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		state := &endpointState{url: u, weight: weight, api: api, healthy: true}
+		pool.endpoints = append(pool.endpoints, state)
+		if options.sendEndpoint != "" && strings.TrimRight(options.sendEndpoint, "/") == u.String() {
+			pool.send = state
+		}
+	}
+	if options.sendEndpoint != "" && pool.send == nil {
+		return nil, fmt.Errorf("send endpoint %q is not in the pool", options.sendEndpoint)
+	}
+	if pool.send == nil {
+		pool.send = pool.endpoints[0]
+	}
+	return pool, nil
+}
+
+// Run checks the health of all endpoints periodically until the context is canceled.
+func (p *EndpointPool) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.healthInterval)
+	defer ticker.Stop()
+	for {
+		p.CheckHealth(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckHealth requests Status and GetBlockchainMasterchainHead from every endpoint
+// and updates their health and lag.
+func (p *EndpointPool) CheckHealth(ctx context.Context) {
+	type result struct {
+		seqno int32
+		err   error
+	}
+	p.mu.Lock()
+	endpoints := make([]*endpointState, len(p.endpoints))
+	copy(endpoints, p.endpoints)
+	p.mu.Unlock()
+
+	results := make([]result, len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, e *endpointState) {
+			defer wg.Done()
+			results[i].seqno, results[i].err = checkEndpoint(ctx, e.api)
+		}(i, e)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var head int32
+	for _, r := range results {
+		if r.err == nil && r.seqno > head {
+			head = r.seqno
+		}
+	}
+	now := time.Now()
+	for i, e := range endpoints {
+		r := results[i]
+		wasHealthy := e.healthy
+		e.lastCheck = now
+		e.lastError = r.err
+		if r.err == nil {
+			e.seqno = r.seqno
+			e.lag = head - r.seqno
+			e.consecutiveFailures = 0
+			e.healthy = e.lag <= p.maxLag
+		} else {
+			e.healthy = false
+		}
+		switch {
+		case wasHealthy && !e.healthy && r.err != nil:
+			p.logger.Errorf("tonapi endpoint %v is unhealthy: %v", e.url, r.err)
+		case wasHealthy && !e.healthy:
+			p.logger.Errorf("tonapi endpoint %v is lagging by %v blocks", e.url, e.lag)
+		}
+	}
+}
+
+func checkEndpoint(ctx context.Context, api *Client) (int32, error) {
+	status, err := api.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !status.RestOnline {
+		return 0, errors.New("rest api is offline")
+	}
+	head, err := api.GetBlockchainMasterchainHead(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return head.Seqno, nil
+}
+
+// Statuses returns a snapshot of the health of all endpoints.
+func (p *EndpointPool) Statuses() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	statuses := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		statuses = append(statuses, EndpointStatus{
+			URL:                 e.url.String(),
+			Weight:              e.weight,
+			Healthy:             e.healthy,
+			Seqno:               e.seqno,
+			Lag:                 e.lag,
+			ConsecutiveFailures: e.consecutiveFailures,
+			LastError:           e.lastError,
+			LastCheck:           e.lastCheck,
+		})
+	}
+	return statuses
+}
+
+// Do sends an HTTP request to one of the pool's endpoints.
+func (p *EndpointPool) Do(req *http.Request) (*http.Response, error) {
+	if isSendRequest(req) {
+		p.mu.Lock()
+		send := p.send
+		p.mu.Unlock()
+		resp, err := p.client.Do(rewriteRequest(req, send.url))
+		p.observe(send, resp, err)
+		return resp, err
+	}
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	var lastErr error
+	for _, e := range p.candidates() {
+		r := rewriteRequest(req, e.url)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		resp, err := p.client.Do(r)
+		p.observe(e, resp, err)
+		if err != nil {
+			if req.Context().Err() != nil || !replayable {
+				return nil, err
+			}
+			lastErr = err
+			continue
+		}
+		if retryableStatus(resp.StatusCode) && replayable {
+			lastErr = fmt.Errorf("%v responded with %v", e.url, resp.Status)
+			resp.Body.Close()
+			continue
+		}
+		return resp, nil
+	}
+	if lastErr == nil {
+		lastErr = ErrNoEndpoints
+	}
+	return nil, lastErr
+}
+
+// candidates returns endpoints in the order they should be tried:
+// healthy endpoints in a weighted random order followed by unhealthy ones as a last resort.
+func (p *EndpointPool) candidates() []*endpointState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var healthy, unhealthy []*endpointState
+	totalWeight := 0
+	for _, e := range p.endpoints {
+		if e.healthy {
+			healthy = append(healthy, e)
+			totalWeight += e.weight
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	ordered := make([]*endpointState, 0, len(p.endpoints))
+	for len(healthy) > 0 {
+		n := p.rnd.Intn(totalWeight)
+		for i, e := range healthy {
+			if n < e.weight {
+				ordered = append(ordered, e)
+				totalWeight -= e.weight
+				healthy = append(healthy[:i], healthy[i+1:]...)
+				break
+			}
+			n -= e.weight
+		}
+	}
+	return append(ordered, unhealthy...)
+}
+
+// observe updates passive health of the endpoint based on the result of a request.
+func (p *EndpointPool) observe(e *endpointState, resp *http.Response, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil && resp.StatusCode < http.StatusInternalServerError {
+		e.consecutiveFailures = 0
+		return
+	}
+	e.consecutiveFailures++
+	if err == nil {
+		err = errors.New(resp.Status)
+	}
+	e.lastError = err
+	if e.healthy && e.consecutiveFailures >= p.maxFailures {
+		e.healthy = false
+		p.logger.Errorf("tonapi endpoint %v is unhealthy after %v failures: %v", e.url, e.consecutiveFailures, err)
+	}
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// isSendRequest returns true if the request broadcasts a message to the blockchain.
+func isSendRequest(req *http.Request) bool {
+	if req.Method != http.MethodPost {
+		return false
+	}
+	switch strings.TrimSuffix(req.URL.Path, "/") {
+	case "/v2/blockchain/message", "/v2/gasless/send", "/v2/liteserver/send_message":
+		return true
+	}
+	return false
+}
+
+func rewriteRequest(req *http.Request, base *url.URL) *http.Request {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = base.Scheme
+	r.URL.Host = base.Host
+	r.URL.Path = base.Path + req.URL.Path
+	if req.URL.RawPath != "" {
+		r.URL.RawPath = base.Path + req.URL.RawPath
+	}
+	r.Host = ""
+	return r
+}
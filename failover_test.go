@@ -0,0 +1,83 @@
+package tonapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeEndpoint(t *testing.T, seqno int32, statusCode int, hits *int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		if statusCode != http.StatusOK {
+			w.WriteHeader(statusCode)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/status":
+			data, _ := (&ServiceStatus{RestOnline: true, LastKnownMasterchainSeqno: seqno}).MarshalJSON()
+			w.Write(data)
+		case "/v2/blockchain/masterchain-head":
+			data, _ := (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: seqno}).MarshalJSON()
+			w.Write(data)
+		case "/v2/blockchain/message":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestEndpointPool(t *testing.T) {
+	var failingHits, laggingHits, healthyHits int32
+	failing := newFakeEndpoint(t, 0, http.StatusBadGateway, &failingHits)
+	lagging := newFakeEndpoint(t, 90, http.StatusOK, &laggingHits)
+	healthy := newFakeEndpoint(t, 100, http.StatusOK, &healthyHits)
+
+	pool, err := NewEndpointPool([]Endpoint{
+		{URL: failing.URL, Weight: 10},
+		{URL: lagging.URL, Weight: 10},
+		{URL: healthy.URL, Weight: 1},
+	}, WithPoolSendEndpoint(failing.URL))
+	require.NoError(t, err)
+
+	client, err := NewClient(TonApiURL, &Security{}, WithClient(pool))
+	require.NoError(t, err)
+
+	// before the first health check, reads fail over from the failing endpoint.
+	for i := 0; i < 10; i++ {
+		_, err = client.Status(context.Background())
+		require.NoError(t, err)
+	}
+
+	pool.CheckHealth(context.Background())
+	statuses := pool.Statuses()
+	require.False(t, statuses[0].Healthy)
+	require.False(t, statuses[1].Healthy)
+	require.Equal(t, int32(10), statuses[1].Lag)
+	require.True(t, statuses[2].Healthy)
+
+	atomic.StoreInt32(&laggingHits, 0)
+	atomic.StoreInt32(&healthyHits, 0)
+	for i := 0; i < 10; i++ {
+		_, err = client.Status(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(0), atomic.LoadInt32(&laggingHits))
+	require.Equal(t, int32(10), atomic.LoadInt32(&healthyHits))
+
+	// sends are pinned and never retried on another endpoint.
+	atomic.StoreInt32(&failingHits, 0)
+	atomic.StoreInt32(&healthyHits, 0)
+	_, err = client.SendMessage(context.Background(), []byte{1, 2, 3})
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&failingHits))
+	require.Equal(t, int32(0), atomic.LoadInt32(&healthyHits))
+}
@@ -0,0 +1,367 @@
+package tonapi
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/tonkeeper/tongo/abi"
+	"github.com/tonkeeper/tongo/boc"
+	"github.com/tonkeeper/tongo/tlb"
+	"github.com/tonkeeper/tongo/ton"
+	"github.com/tonkeeper/tongo/wallet"
+)
+
+const (
+	// defaultGaslessAttachedTon is attached to the jetton transfer to pay for its processing.
+	// The excess is returned to the relay which lowers the commission.
+	defaultGaslessAttachedTon = tlb.Grams(50_000_000)
+	// defaultGaslessForwardTon is the minimal forward amount to notify the destination about the transfer.
+	defaultGaslessForwardTon = tlb.Grams(1)
+	jettonTransferOpCode     = 0x0f8a7ea5
+	// nonBounceableTag is set in the first byte of non-bounceable user-friendly addresses.
+	nonBounceableTag = 0x40
+)
+
+var (
+	// ErrGaslessJettonNotSupported is returned when the jetton can't be used to pay for gas.
+	ErrGaslessJettonNotSupported = errors.New("jetton is not supported by the gasless relay")
+)
+
+// GaslessTransfer describes a jetton transfer whose fees are paid in the same jetton through a gasless relay.
+type GaslessTransfer struct {
+	// PrivateKey signs the transfer locally, it is never sent to tonapi.
+	PrivateKey ed25519.PrivateKey
+	// WalletVersion is the version of the sender's wallet. Gasless relays work with wallet.V5R1 only.
+	WalletVersion wallet.Version
+	// NetworkGlobalID is used to derive the W5 wallet address.
+	// If nil, it is taken from the current masterchain block.
+	NetworkGlobalID *int32
+	// JettonMaster is the jetton to transfer and to pay the commission with.
+	JettonMaster ton.AccountID
+	// Amount is the amount of jettons to transfer in the smallest jetton units.
+	Amount *big.Int
+	// Destination is the owner of the jetton wallet receiving the transfer.
+	Destination ton.AccountID
+	// Comment is an optional text comment forwarded to the destination.
+	Comment string
+	// MaxCommission is the maximum commission in the smallest jetton units the caller agrees to pay.
+	MaxCommission *big.Int
+	// AttachedTon is attached to the jetton transfer to pay for its processing. Defaults to 0.05 TON.
+	AttachedTon tlb.Grams
+	// ForwardTonAmount is forwarded to the destination with a transfer notification. Defaults to 1 nanoton.
+	ForwardTonAmount tlb.Grams
+	// MaxTon is the maximum amount of TON the signed messages can attach in total.
+	// Defaults to twice AttachedTon: one for the transfer and one for the commission.
+	MaxTon tlb.Grams
+}
+
+// GaslessResult is the outcome of a gasless transfer.
+type GaslessResult struct {
+	// Wallet is the sender's wallet address.
+	Wallet       ton.AccountID
+	ProtocolName string
+	// Commission is the commission paid to the relay in the smallest jetton units.
+	Commission *big.Int
+	// Params are the messages signed by the wallet as returned by the relay.
+	Params *SignRawParams
+	// MessageHash is the normalized hash of the external message sent by the relay.
+	MessageHash string
+	// Trace is the finished trace of the transfer.
+	// It is nil if the transfer was sent but the trace is not available yet.
+	Trace *Trace
+}
+
+// SendGaslessTransfer builds a jetton transfer, asks the relay to estimate it with GaslessEstimate,
+// checks the returned messages and emulation against the transfer with CheckGaslessParams, signs the returned messages as a W5 signed internal message,
+// sends it with GaslessSend and waits for the resulting trace to finish.
+//
+// If the context is done after the transfer is sent, the result is returned along with the context error,
+// so the caller can keep tracking the transfer by GaslessResult.MessageHash.
+func (c *Client) SendGaslessTransfer(ctx context.Context, t GaslessTransfer) (*GaslessResult, error) {
+	if t.WalletVersion != wallet.V5R1 {
+		return nil, fmt.Errorf("gasless transfers require a W5 wallet, got %v", t.WalletVersion.ToString())
+	}
+	if t.Amount == nil || t.Amount.Sign() <= 0 {
+		return nil, errors.New("gasless transfer amount must be positive")
+	}
+	if t.MaxCommission == nil {
+		return nil, errors.New("gasless transfer requires a commission ceiling")
+	}
+	if t.AttachedTon == 0 {
+		t.AttachedTon = defaultGaslessAttachedTon
+	}
+	if t.ForwardTonAmount == 0 {
+		t.ForwardTonAmount = defaultGaslessForwardTon
+	}
+	if t.MaxTon == 0 {
+		t.MaxTon = 2 * t.AttachedTon
+	}
+	if t.NetworkGlobalID == nil {
+		head, err := c.GetBlockchainMasterchainHead(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t.NetworkGlobalID = &head.GlobalID
+	}
+	w, err := wallet.New(t.PrivateKey, t.WalletVersion, nil, wallet.WithNetworkGlobalID(*t.NetworkGlobalID))
+	if err != nil {
+		return nil, err
+	}
+	result := &GaslessResult{Wallet: w.GetAddress()}
+
+	relay, err := c.gaslessRelay(ctx, t.JettonMaster)
+	if err != nil {
+		return nil, err
+	}
+	jettonWallet, transfer, err := c.gaslessTransferMessage(ctx, t, result.Wallet, relay)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := hex.EncodeToString(t.PrivateKey.Public().(ed25519.PublicKey))
+	params, err := c.GaslessEstimate(ctx, &GaslessEstimateReq{
+		WalletAddress:   result.Wallet.ToRaw(),
+		WalletPublicKey: publicKey,
+		Messages:        []GaslessEstimateReqMessagesItem{{Boc: transfer}},
+		ReturnEmulation: NewOptBool(true),
+	}, GaslessEstimateParams{MasterID: t.JettonMaster.ToRaw()})
+	if err != nil {
+		return nil, err
+	}
+	result.Params = params
+	result.ProtocolName = params.ProtocolName
+
+	result.Commission, _ = new(big.Int).SetString(params.Commission, 10)
+	err = CheckGaslessParams(GaslessIntent{
+		Wallet:        result.Wallet,
+		JettonMaster:  t.JettonMaster,
+		JettonWallet:  jettonWallet,
+		Destination:   t.Destination,
+		Amount:        t.Amount,
+		Relay:         relay,
+		MaxCommission: t.MaxCommission,
+		MaxTon:        t.MaxTon,
+	}, params)
+	if err != nil {
+		return result, err
+	}
+
+	msgBoc, err := c.signGaslessMessages(ctx, &w, params)
+	if err != nil {
+		return result, err
+	}
+	tx, err := c.GaslessSend(ctx, &GaslessSendReq{
+		WalletPublicKey: NewOptString(publicKey),
+		Boc:             msgBoc,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.ProtocolName = tx.ProtocolName
+	hash, ok := tx.External.Get()
+	if !ok {
+		return result, nil
+	}
+	result.MessageHash = hash
+	result.Trace, err = c.WaitForTrace(ctx, hash, time.Second)
+	return result, err
+}
+
+// gaslessRelay returns the relay address and checks the jetton can be used to pay for gas.
+func (c *Client) gaslessRelay(ctx context.Context, master ton.AccountID) (ton.AccountID, error) {
+	cfg, err := c.GaslessConfig(ctx)
+	if err != nil {
+		return ton.AccountID{}, err
+	}
+	supported := false
+	for _, jetton := range cfg.GasJettons {
+		if id, err := ton.ParseAccountID(jetton.MasterID); err == nil && id == master {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return ton.AccountID{}, ErrGaslessJettonNotSupported
+	}
+	return ton.ParseAccountID(cfg.RelayAddress)
+}
+
+// gaslessTransferMessage returns the sender's jetton wallet and a hex-encoded internal message transferring jettons from it,
+// with the excess sent to the relay.
+func (c *Client) gaslessTransferMessage(ctx context.Context, t GaslessTransfer, owner, relay ton.AccountID) (ton.AccountID, string, error) {
+	balance, err := c.GetAccountJettonBalance(ctx, GetAccountJettonBalanceParams{
+		AccountID: owner.ToRaw(),
+		JettonID:  t.JettonMaster.ToRaw(),
+	})
+	if err != nil {
+		return ton.AccountID{}, "", fmt.Errorf("failed to get jetton wallet: %w", err)
+	}
+	jettonWallet, err := ton.ParseAccountID(balance.WalletAddress.Address)
+	if err != nil {
+		return ton.AccountID{}, "", err
+	}
+	body := abi.JettonTransferMsgBody{
+		QueryId:             uint64(time.Now().UnixNano()),
+		Amount:              tlb.VarUInteger16(*t.Amount),
+		Destination:         t.Destination.ToMsgAddress(),
+		ResponseDestination: relay.ToMsgAddress(),
+		ForwardTonAmount:    tlb.VarUInteger16(*big.NewInt(int64(t.ForwardTonAmount))),
+	}
+	if t.Comment != "" {
+		comment := boc.NewCell()
+		if err := tlb.Marshal(comment, wallet.TextComment(t.Comment)); err != nil {
+			return ton.AccountID{}, "", err
+		}
+		body.ForwardPayload.IsRight = true
+		body.ForwardPayload.Value = abi.JettonPayload{SumType: abi.UnknownJettonOp, Value: comment}
+	}
+	bodyCell := boc.NewCell()
+	if err := bodyCell.WriteUint(jettonTransferOpCode, 32); err != nil {
+		return ton.AccountID{}, "", err
+	}
+	if err := tlb.Marshal(bodyCell, body); err != nil {
+		return ton.AccountID{}, "", err
+	}
+	msg, _, err := wallet.Message{
+		Amount:  t.AttachedTon,
+		Address: jettonWallet,
+		Bounce:  true,
+		Mode:    wallet.DefaultMessageMode,
+		Body:    bodyCell,
+	}.ToInternal()
+	if err != nil {
+		return ton.AccountID{}, "", err
+	}
+	cell := boc.NewCell()
+	if err := tlb.Marshal(cell, msg); err != nil {
+		return ton.AccountID{}, "", err
+	}
+	msgBoc, err := cell.ToBocString()
+	return jettonWallet, msgBoc, err
+}
+
+// signGaslessMessages signs the messages returned by the relay as a W5 signed internal message
+// wrapped into an external message and returns it as a base64-encoded BoC.
+func (c *Client) signGaslessMessages(ctx context.Context, w *wallet.Wallet, params *SignRawParams) (string, error) {
+	msgs := make([]wallet.Sendable, 0, len(params.Messages))
+	for _, m := range params.Messages {
+		msg, err := signRawMessageToWalletMessage(m)
+		if err != nil {
+			return "", err
+		}
+		msgs = append(msgs, msg)
+	}
+	seqno, init, err := c.walletSeqnoAndInit(ctx, w)
+	if err != nil {
+		return "", err
+	}
+	validUntil := time.Now().Add(wallet.DefaultMessageLifetime)
+	if params.ValidUntil > 0 {
+		validUntil = time.Unix(params.ValidUntil, 0)
+	}
+	body, err := w.CreateMessageBody(wallet.MessageConfig{
+		Seqno:      seqno,
+		ValidUntil: validUntil,
+		V5MsgType:  wallet.V5MsgTypeSignedInternal,
+	}, msgs...)
+	if err != nil {
+		return "", err
+	}
+	ext, err := ton.CreateExternalMessage(w.GetAddress(), body, init, tlb.VarUInteger16{})
+	if err != nil {
+		return "", err
+	}
+	cell := boc.NewCell()
+	if err := tlb.Marshal(cell, ext); err != nil {
+		return "", err
+	}
+	return cell.ToBocBase64()
+}
+
+// walletSeqnoAndInit returns the next seqno of the wallet and its state init if the wallet is not deployed yet.
+func (c *Client) walletSeqnoAndInit(ctx context.Context, w *wallet.Wallet) (uint32, *tlb.StateInit, error) {
+	account, err := c.GetAccount(ctx, GetAccountParams{AccountID: w.GetAddress().ToRaw()})
+	if err != nil {
+		return 0, nil, err
+	}
+	if account.Status == AccountStatusActive {
+		seqno, err := c.GetSeqno(ctx, w.GetAddress())
+		return seqno, nil, err
+	}
+	init, err := w.StateInit()
+	return 0, init, err
+}
+
+// signRawMessageToWalletMessage converts a message in the tonconnect format to a message sent by a wallet.
+func signRawMessageToWalletMessage(m SignRawMessage) (wallet.Message, error) {
+	address, bounce, err := parseMessageAddress(m.Address)
+	if err != nil {
+		return wallet.Message{}, fmt.Errorf("invalid message address %q: %w", m.Address, err)
+	}
+	amount, ok := new(big.Int).SetString(m.Amount, 10)
+	if !ok || !amount.IsUint64() {
+		return wallet.Message{}, fmt.Errorf("invalid message amount %q", m.Amount)
+	}
+	msg := wallet.Message{
+		Amount:  tlb.Grams(amount.Uint64()),
+		Address: address,
+		Bounce:  bounce,
+		Mode:    wallet.DefaultMessageMode,
+	}
+	if payload, ok := m.Payload.Get(); ok && payload != "" {
+		if msg.Body, err = deserializeSingleCell(payload); err != nil {
+			return wallet.Message{}, fmt.Errorf("invalid message payload: %w", err)
+		}
+	}
+	if stateInit, ok := m.StateInit.Get(); ok && stateInit != "" {
+		cell, err := deserializeSingleCell(stateInit)
+		if err != nil {
+			return wallet.Message{}, fmt.Errorf("invalid message state init: %w", err)
+		}
+		var init tlb.StateInit
+		if err := tlb.Unmarshal(cell, &init); err != nil {
+			return wallet.Message{}, fmt.Errorf("invalid message state init: %w", err)
+		}
+		if init.Code.Exists && init.Data.Exists {
+			code, data := init.Code.Value.Value, init.Data.Value.Value
+			msg.Code, msg.Data = &code, &data
+		}
+	}
+	return msg, nil
+}
+
+// parseMessageAddress parses a message destination in the raw or user-friendly form and returns its bounce flag.
+// Raw addresses are bounceable. Unlike tongo.ParseAddress, it doesn't resolve DNS names
+// and reads the flag of non-bounceable user-friendly addresses correctly.
+func parseMessageAddress(s string) (ton.AccountID, bool, error) {
+	if id, err := ton.AccountIDFromRaw(s); err == nil {
+		return id, true, nil
+	}
+	id, err := ton.AccountIDFromBase64Url(s)
+	if err != nil {
+		return ton.AccountID{}, false, err
+	}
+	// the checksum is verified, so the tag is there.
+	tag, _ := base64.URLEncoding.DecodeString(strings.NewReplacer("+", "-", "/", "_").Replace(s))
+	return id, tag[0]&nonBounceableTag == 0, nil
+}
+
+// deserializeSingleCell decodes a one-cell BoC encoded in hex or base64.
+func deserializeSingleCell(s string) (*boc.Cell, error) {
+	cells, err := boc.DeserializeBocHex(s)
+	if err != nil {
+		if cells, err = boc.DeserializeBocBase64(s); err != nil {
+			return nil, err
+		}
+	}
+	if len(cells) != 1 {
+		return nil, fmt.Errorf("expected one root cell, got %v", len(cells))
+	}
+	return cells[0], nil
+}
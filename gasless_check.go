@@ -0,0 +1,195 @@
+package tonapi
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tonkeeper/tongo/abi"
+	"github.com/tonkeeper/tongo/tlb"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+// GaslessIntent is what the user expects a gasless transfer to do.
+// It is checked against the messages and the emulation returned by GaslessEstimate before signing.
+type GaslessIntent struct {
+	// Wallet is the sender's wallet.
+	Wallet ton.AccountID
+	// JettonMaster is the transferred jetton.
+	JettonMaster ton.AccountID
+	// JettonWallet is the sender's jetton wallet. All messages must be sent to it.
+	JettonWallet ton.AccountID
+	// Destination is the owner of the jetton wallet receiving the transfer.
+	Destination ton.AccountID
+	// Amount is the amount of jettons the destination must receive.
+	Amount *big.Int
+	// Relay is the relay address from GaslessConfig. The commission and the excess must go to it.
+	Relay ton.AccountID
+	// MaxCommission is the maximum commission in the smallest jetton units.
+	MaxCommission *big.Int
+	// MaxTon is the maximum amount of TON all messages can attach.
+	MaxTon tlb.Grams
+}
+
+// GaslessCheckError lists the reasons the messages returned by the relay don't match the user's intent.
+type GaslessCheckError struct {
+	Violations []string
+}
+
+func (e *GaslessCheckError) Error() string {
+	return "gasless transfer rejected: " + strings.Join(e.Violations, "; ")
+}
+
+// CheckGaslessParams verifies that signing the given messages does exactly what the intent says and nothing else:
+//   - the commission doesn't exceed the ceiling and is paid to the relay;
+//   - there is exactly one transfer of the expected amount to the destination;
+//   - every message is a jetton transfer from the sender's jetton wallet with the excess sent to the relay;
+//   - the emulation, if present, doesn't put at risk more TON or jettons than expected,
+//     doesn't allow draining the wallet and doesn't move NFTs.
+//
+// It returns a *GaslessCheckError describing all violations found.
+// An incomplete intent is a violation on its own and the messages are not checked against it.
+func CheckGaslessParams(intent GaslessIntent, params *SignRawParams) error {
+	var violations []string
+	fail := func(format string, args ...any) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+
+	if intent.Amount == nil || intent.Amount.Sign() <= 0 {
+		fail("intent: the amount must be positive")
+	}
+	for _, a := range []struct {
+		name    string
+		address ton.AccountID
+	}{
+		{"relay", intent.Relay},
+		{"jetton wallet", intent.JettonWallet},
+		{"destination", intent.Destination},
+	} {
+		if a.address == (ton.AccountID{}) {
+			fail("intent: no %v", a.name)
+		}
+	}
+	if len(violations) > 0 {
+		return &GaslessCheckError{Violations: violations}
+	}
+
+	commission, ok := new(big.Int).SetString(params.Commission, 10)
+	if !ok {
+		fail("invalid commission %q", params.Commission)
+		commission = new(big.Int)
+	} else if intent.MaxCommission != nil && commission.Cmp(intent.MaxCommission) > 0 {
+		fail("commission %v exceeds the ceiling %v", commission, intent.MaxCommission)
+	}
+	if relay, err := ton.ParseAccountID(params.RelayAddress); err != nil || relay != intent.Relay {
+		fail("unexpected relay %v", params.RelayAddress)
+	}
+	if params.From != "" {
+		if from, err := ton.ParseAccountID(params.From); err != nil || from != intent.Wallet {
+			fail("messages are built for another wallet %v", params.From)
+		}
+	}
+
+	var totalTon big.Int
+	transfers, fees := 0, 0
+	for i, m := range params.Messages {
+		amount, ok := new(big.Int).SetString(m.Amount, 10)
+		if !ok {
+			fail("message %v: invalid amount %q", i, m.Amount)
+			continue
+		}
+		totalTon.Add(&totalTon, amount)
+		if dest, err := ton.ParseAccountID(m.Address); err != nil || dest != intent.JettonWallet {
+			fail("message %v: sent to %v instead of the jetton wallet", i, m.Address)
+			continue
+		}
+		if stateInit, ok := m.StateInit.Get(); ok && stateInit != "" {
+			fail("message %v: unexpected state init", i)
+		}
+		body, err := decodeJettonTransfer(m)
+		if err != nil {
+			fail("message %v: %v", i, err)
+			continue
+		}
+		if dest, err := ton.AccountIDFromTlb(body.ResponseDestination); err != nil || dest == nil || *dest != intent.Relay {
+			fail("message %v: excess is not sent to the relay", i)
+		}
+		jettons := big.Int(body.Amount)
+		dest, err := ton.AccountIDFromTlb(body.Destination)
+		switch {
+		case err != nil || dest == nil:
+			fail("message %v: invalid transfer destination", i)
+		case *dest == intent.Destination && jettons.Cmp(intent.Amount) == 0:
+			transfers++
+		case *dest == intent.Relay && jettons.Cmp(commission) == 0:
+			fees++
+		default:
+			fail("message %v: unexpected transfer of %v jettons to %v", i, &jettons, dest.ToRaw())
+		}
+	}
+	if transfers != 1 {
+		fail("expected exactly one transfer of %v jettons to %v, found %v", intent.Amount, intent.Destination.ToRaw(), transfers)
+	}
+	if commission.Sign() > 0 && fees != 1 {
+		fail("expected exactly one commission transfer to the relay, found %v", fees)
+	}
+	maxTon := new(big.Int).SetUint64(uint64(intent.MaxTon))
+	if intent.MaxTon > 0 && totalTon.Cmp(maxTon) > 0 {
+		fail("messages attach %v nanoton, more than %v", &totalTon, intent.MaxTon)
+	}
+
+	if emulation, ok := params.Emulation.Get(); ok {
+		violations = append(violations, checkGaslessRisk(intent, commission, emulation.Risk)...)
+	}
+	if len(violations) > 0 {
+		return &GaslessCheckError{Violations: violations}
+	}
+	return nil
+}
+
+func checkGaslessRisk(intent GaslessIntent, commission *big.Int, risk Risk) []string {
+	var violations []string
+	if risk.TransferAllRemainingBalance {
+		violations = append(violations, "emulation: messages allow draining the wallet balance")
+	}
+	if intent.MaxTon > 0 && risk.Gram > int64(intent.MaxTon) {
+		violations = append(violations, fmt.Sprintf("emulation: %v nanoton at risk, more than %v", risk.Gram, intent.MaxTon))
+	}
+	expectedJettons := new(big.Int).Add(intent.Amount, commission)
+	for _, j := range risk.Jettons {
+		master, err := ton.ParseAccountID(j.Jetton.Address)
+		if err != nil || master != intent.JettonMaster {
+			violations = append(violations, fmt.Sprintf("emulation: unexpected jetton %v at risk", j.Jetton.Address))
+			continue
+		}
+		quantity, ok := new(big.Int).SetString(j.Quantity, 10)
+		if !ok || quantity.Cmp(expectedJettons) > 0 {
+			violations = append(violations, fmt.Sprintf("emulation: %v jettons at risk, more than %v", j.Quantity, expectedJettons))
+		}
+	}
+	for _, nft := range risk.Nfts {
+		violations = append(violations, fmt.Sprintf("emulation: nft %v at risk", nft.Address))
+	}
+	return violations
+}
+
+// decodeJettonTransfer decodes the payload of a message as a jetton transfer.
+func decodeJettonTransfer(m SignRawMessage) (*abi.JettonTransferMsgBody, error) {
+	payload, ok := m.Payload.Get()
+	if !ok || payload == "" {
+		return nil, fmt.Errorf("no payload")
+	}
+	cell, err := deserializeSingleCell(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	op, err := cell.ReadUint(32)
+	if err != nil || op != jettonTransferOpCode {
+		return nil, fmt.Errorf("payload is not a jetton transfer")
+	}
+	var body abi.JettonTransferMsgBody
+	if err := tlb.Unmarshal(cell, &body); err != nil {
+		return nil, fmt.Errorf("invalid jetton transfer: %w", err)
+	}
+	return &body, nil
+}
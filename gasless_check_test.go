@@ -0,0 +1,105 @@
+package tonapi
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/abi"
+	"github.com/tonkeeper/tongo/boc"
+	"github.com/tonkeeper/tongo/tlb"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func jettonTransferPayload(t *testing.T, amount int64, destination, response ton.AccountID) string {
+	body := abi.JettonTransferMsgBody{
+		Amount:              tlb.VarUInteger16(*big.NewInt(amount)),
+		Destination:         destination.ToMsgAddress(),
+		ResponseDestination: response.ToMsgAddress(),
+	}
+	cell := boc.NewCell()
+	require.NoError(t, cell.WriteUint(jettonTransferOpCode, 32))
+	require.NoError(t, tlb.Marshal(cell, body))
+	payload, err := cell.ToBocString()
+	require.NoError(t, err)
+	return payload
+}
+
+func TestCheckGaslessParams(t *testing.T) {
+	wallet := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	jettonWallet := ton.MustParseAccountID("0:2222222222222222222222222222222222222222222222222222222222222222")
+	destination := ton.MustParseAccountID("0:3333333333333333333333333333333333333333333333333333333333333333")
+	relay := ton.MustParseAccountID("0:4444444444444444444444444444444444444444444444444444444444444444")
+	master := ton.MustParseAccountID("0:5555555555555555555555555555555555555555555555555555555555555555")
+	intent := GaslessIntent{
+		Wallet:        wallet,
+		JettonMaster:  master,
+		JettonWallet:  jettonWallet,
+		Destination:   destination,
+		Amount:        big.NewInt(1000),
+		Relay:         relay,
+		MaxCommission: big.NewInt(50),
+		MaxTon:        100_000_000,
+	}
+	params := func() *SignRawParams {
+		return &SignRawParams{
+			RelayAddress: relay.ToRaw(),
+			Commission:   "40",
+			From:         wallet.ToRaw(),
+			Messages: []SignRawMessage{
+				{Address: jettonWallet.ToRaw(), Amount: "50000000", Payload: NewOptString(jettonTransferPayload(t, 1000, destination, relay))},
+				{Address: jettonWallet.ToRaw(), Amount: "50000000", Payload: NewOptString(jettonTransferPayload(t, 40, relay, relay))},
+			},
+			Emulation: NewOptMessageConsequences(MessageConsequences{
+				Risk: Risk{
+					Gram:    100_000_000,
+					Jettons: []JettonQuantity{{Quantity: "1040", Jetton: JettonPreview{Address: master.ToRaw()}}},
+				},
+			}),
+		}
+	}
+	require.NoError(t, CheckGaslessParams(intent, params()))
+
+	tests := []struct {
+		name   string
+		modify func(p *SignRawParams)
+		intent func(i *GaslessIntent)
+	}{
+		{name: "commission above ceiling", modify: func(p *SignRawParams) { p.Commission = "60" }},
+		{name: "another relay", modify: func(p *SignRawParams) { p.RelayAddress = destination.ToRaw() }},
+		{name: "wrong amount", modify: func(p *SignRawParams) {
+			p.Messages[0].Payload = NewOptString(jettonTransferPayload(t, 999, destination, relay))
+		}},
+		{name: "excess to another address", modify: func(p *SignRawParams) {
+			p.Messages[1].Payload = NewOptString(jettonTransferPayload(t, 40, relay, destination))
+		}},
+		{name: "message to another contract", modify: func(p *SignRawParams) { p.Messages[1].Address = destination.ToRaw() }},
+		{name: "too much ton", modify: func(p *SignRawParams) { p.Messages[0].Amount = "1000000000" }},
+		{name: "wallet drain", modify: func(p *SignRawParams) { p.Emulation.Value.Risk.TransferAllRemainingBalance = true }},
+		{name: "nft at risk", modify: func(p *SignRawParams) {
+			p.Emulation.Value.Risk.Nfts = []NftItem{{Address: destination.ToRaw()}}
+		}},
+		{name: "no amount", intent: func(i *GaslessIntent) { i.Amount = nil }},
+		{name: "zero amount", intent: func(i *GaslessIntent) { i.Amount = new(big.Int) }},
+		{name: "negative amount", intent: func(i *GaslessIntent) { i.Amount = big.NewInt(-1000) }},
+		{name: "no relay", intent: func(i *GaslessIntent) { i.Relay = ton.AccountID{} }},
+		{name: "no jetton wallet", intent: func(i *GaslessIntent) { i.JettonWallet = ton.AccountID{} }},
+		{name: "no destination", intent: func(i *GaslessIntent) { i.Destination = ton.AccountID{} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, i := params(), intent
+			if tt.modify != nil {
+				tt.modify(p)
+			}
+			if tt.intent != nil {
+				tt.intent(&i)
+			}
+			err := CheckGaslessParams(i, p)
+			var checkErr *GaslessCheckError
+			require.True(t, errors.As(err, &checkErr), "expected GaslessCheckError, got %v", err)
+			require.NotEmpty(t, checkErr.Violations)
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package tonapi
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/abi"
+	"github.com/tonkeeper/tongo/boc"
+	"github.com/tonkeeper/tongo/tlb"
+	"github.com/tonkeeper/tongo/ton"
+	"github.com/tonkeeper/tongo/wallet"
+)
+
+func TestDeserializeSingleCell(t *testing.T) {
+	cell := boc.NewCell()
+	require.NoError(t, cell.WriteUint(0xdeadbeef, 32))
+	hash, err := cell.Hash256()
+	require.NoError(t, err)
+	hexBoc, err := cell.ToBocString()
+	require.NoError(t, err)
+	base64Boc, err := cell.ToBocBase64()
+	require.NoError(t, err)
+
+	for _, s := range []string{hexBoc, base64Boc} {
+		decoded, err := deserializeSingleCell(s)
+		require.NoError(t, err)
+		decodedHash, err := decoded.Hash256()
+		require.NoError(t, err)
+		require.Equal(t, hash, decodedHash)
+	}
+	_, err = deserializeSingleCell("not a boc")
+	require.Error(t, err)
+}
+
+func TestSignRawMessageToWalletMessage(t *testing.T) {
+	destination := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	payload := boc.NewCell()
+	require.NoError(t, payload.WriteUint(jettonTransferOpCode, 32))
+	payloadBoc, err := payload.ToBocString()
+	require.NoError(t, err)
+
+	code, data := boc.NewCell(), boc.NewCell()
+	require.NoError(t, code.WriteUint(1, 8))
+	require.NoError(t, data.WriteUint(2, 8))
+	init := tlb.StateInit{}
+	init.Code.Exists, init.Code.Value.Value = true, *code
+	init.Data.Exists, init.Data.Value.Value = true, *data
+	initCell := boc.NewCell()
+	require.NoError(t, tlb.Marshal(initCell, init))
+	initBoc, err := initCell.ToBocBase64()
+	require.NoError(t, err)
+
+	msg, err := signRawMessageToWalletMessage(SignRawMessage{
+		Address:   destination.ToHuman(false, false),
+		Amount:    "50000000",
+		Payload:   NewOptString(payloadBoc),
+		StateInit: NewOptString(initBoc),
+	})
+	require.NoError(t, err)
+	require.Equal(t, destination, msg.Address)
+	require.False(t, msg.Bounce)
+	require.Equal(t, tlb.Grams(50_000_000), msg.Amount)
+	require.Equal(t, wallet.DefaultMessageMode, int(msg.Mode))
+	op, err := msg.Body.ReadUint(32)
+	require.NoError(t, err)
+	require.Equal(t, uint64(jettonTransferOpCode), op)
+	require.NotNil(t, msg.Code)
+	require.NotNil(t, msg.Data)
+	codeHash, _ := code.Hash256()
+	msgCodeHash, _ := msg.Code.Hash256()
+	require.Equal(t, codeHash, msgCodeHash)
+
+	// raw addresses are bounceable, empty payloads and state inits are ignored.
+	msg, err = signRawMessageToWalletMessage(SignRawMessage{Address: destination.ToRaw(), Amount: "1", Payload: NewOptString("")})
+	require.NoError(t, err)
+	require.True(t, msg.Bounce)
+	require.Nil(t, msg.Body)
+	require.Nil(t, msg.Code)
+	msg, err = signRawMessageToWalletMessage(SignRawMessage{Address: destination.ToHuman(true, true), Amount: "1"})
+	require.NoError(t, err)
+	require.True(t, msg.Bounce)
+	require.Equal(t, destination, msg.Address)
+
+	for _, m := range []SignRawMessage{
+		{Address: "wallet.ton", Amount: "1"},
+		{Address: destination.ToRaw(), Amount: "-1"},
+		{Address: destination.ToRaw(), Amount: "18446744073709551616"},
+		{Address: destination.ToRaw(), Amount: "ten"},
+		{Address: destination.ToRaw(), Amount: "1", Payload: NewOptString("zz")},
+		{Address: destination.ToRaw(), Amount: "1", StateInit: NewOptString(payloadBoc)},
+	} {
+		_, err := signRawMessageToWalletMessage(m)
+		require.Error(t, err, m)
+	}
+}
+
+func TestGaslessTransferMessage(t *testing.T) {
+	owner := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	jettonWallet := ton.MustParseAccountID("0:2222222222222222222222222222222222222222222222222222222222222222")
+	destination := ton.MustParseAccountID("0:3333333333333333333333333333333333333333333333333333333333333333")
+	relay := ton.MustParseAccountID("0:4444444444444444444444444444444444444444444444444444444444444444")
+	master := ton.MustParseAccountID("0:5555555555555555555555555555555555555555555555555555555555555555")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v2/accounts/"+owner.ToRaw()+"/jettons/"+master.ToRaw(), r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := (&JettonBalance{
+			Balance:       "1000",
+			WalletAddress: AccountAddress{Address: jettonWallet.ToRaw()},
+			Jetton:        JettonPreview{Address: master.ToRaw(), Verification: JettonVerificationTypeWhitelist},
+		}).MarshalJSON()
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+
+	transfer := GaslessTransfer{
+		JettonMaster:     master,
+		Amount:           big.NewInt(1000),
+		Destination:      destination,
+		Comment:          "thanks",
+		AttachedTon:      defaultGaslessAttachedTon,
+		ForwardTonAmount: defaultGaslessForwardTon,
+	}
+	gotWallet, msgBoc, err := client.gaslessTransferMessage(context.Background(), transfer, owner, relay)
+	require.NoError(t, err)
+	require.Equal(t, jettonWallet, gotWallet)
+
+	cell, err := deserializeSingleCell(msgBoc)
+	require.NoError(t, err)
+	var msg tlb.Message
+	require.NoError(t, tlb.Unmarshal(cell, &msg))
+	require.Equal(t, tlb.SumType("IntMsgInfo"), msg.Info.SumType)
+	require.True(t, msg.Info.IntMsgInfo.Bounce)
+	require.Equal(t, defaultGaslessAttachedTon, msg.Info.IntMsgInfo.Value.Grams)
+	dest, err := ton.AccountIDFromTlb(msg.Info.IntMsgInfo.Dest)
+	require.NoError(t, err)
+	require.Equal(t, jettonWallet, *dest)
+
+	// the body is a TEP-74 jetton transfer returning the excess to the relay.
+	body := boc.Cell(msg.Body.Value)
+	op, err := body.ReadUint(32)
+	require.NoError(t, err)
+	require.Equal(t, uint64(jettonTransferOpCode), op)
+	var transferBody abi.JettonTransferMsgBody
+	require.NoError(t, tlb.Unmarshal(&body, &transferBody))
+	amount := big.Int(transferBody.Amount)
+	require.Equal(t, int64(1000), amount.Int64())
+	recipient, err := ton.AccountIDFromTlb(transferBody.Destination)
+	require.NoError(t, err)
+	require.Equal(t, destination, *recipient)
+	response, err := ton.AccountIDFromTlb(transferBody.ResponseDestination)
+	require.NoError(t, err)
+	require.Equal(t, relay, *response)
+	forward := big.Int(transferBody.ForwardTonAmount)
+	require.Equal(t, int64(defaultGaslessForwardTon), forward.Int64())
+	require.True(t, transferBody.ForwardPayload.IsRight)
+	// the forward payload is a text comment recognized by wallets and explorers.
+	comment, ok := transferBody.ForwardPayload.Value.Value.(abi.TextCommentJettonPayload)
+	require.True(t, ok)
+	require.Equal(t, tlb.Text("thanks"), comment.Text)
+}
+
+func TestWaitForTrace(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		responses []int
+		requests  int
+	)
+	finished := Trace{Transaction: consumerTestTransaction(1)}
+	inProgress := finished
+	inProgress.Transaction.OutMsgs = []Message{{MsgType: MessageMsgTypeIntMsg, CreatedLt: 1, Value: 1, FwdFee: 1, IhrFee: 1, ImportFee: 1, CreatedAt: 1}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		status := http.StatusOK
+		if len(responses) > 0 {
+			status, responses = responses[0], responses[1:]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch status {
+		case http.StatusOK:
+			data, _ := finished.MarshalJSON()
+			w.Write(data)
+		case http.StatusAccepted:
+			data, _ := inProgress.MarshalJSON()
+			w.Write(data)
+		default:
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":"x"}`))
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	ctx := context.Background()
+	wait := func(statuses ...int) (*Trace, error) {
+		mu.Lock()
+		responses, requests = statuses, 0
+		mu.Unlock()
+		return client.WaitForTrace(ctx, "abc", time.Millisecond)
+	}
+
+	// missing and unfinished traces and transient errors are polled again.
+	trace, err := wait(http.StatusNotFound, http.StatusBadGateway, http.StatusTooManyRequests, http.StatusAccepted, http.StatusServiceUnavailable)
+	require.NoError(t, err)
+	require.False(t, TraceInProgress(trace))
+	require.Equal(t, 6, requests)
+
+	_, err = wait(http.StatusBadRequest)
+	require.Error(t, err)
+	require.Equal(t, 1, requests)
+
+	// the polls give up after five transient errors in a row.
+	_, err = wait(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	require.Error(t, err)
+	require.Equal(t, 5, requests)
+	_, err = wait(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusNotFound, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	require.NoError(t, err)
+	require.Equal(t, 7, requests)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.WaitForTrace(cancelled, "abc", time.Millisecond)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -1,3 +1,5 @@
 package tonapi
 
 //go:generate go run github.com/ogen-go/ogen/cmd/ogen -clean -package tonapi -target . api/openapi.yml
+//go:generate go run ./tonapitest/internal/specgen -spec api/openapi.yml -routes -out routes_gen.go
+//go:generate go generate ./tonapitest
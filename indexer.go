@@ -0,0 +1,418 @@
+package tonapi
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-faster/errors"
+	"github.com/tonkeeper/tongo/abi"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+const indexerConcurrency = 4
+
+// IndexedMessage is a decoded inbound or outbound message of an indexed transaction.
+type IndexedMessage struct {
+	Hash string `json:"hash"`
+	// Direction is "in" or "out".
+	Direction   string `json:"direction"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Value       int64  `json:"value"`
+	// OpCode is the first 32 bits of the body, nil for messages without an operation.
+	OpCode *uint32 `json:"op_code,omitempty"`
+	// OpName is the name of the operation as in https://github.com/tonkeeper/tongo/blob/master/abi/messages.go,
+	// empty if the operation is unknown.
+	OpName string `json:"op_name,omitempty"`
+	// Body is the decoded body as JSON, nil if the body could not be decoded.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// IndexedTransaction is a transaction passed through the Indexer pipeline.
+type IndexedTransaction struct {
+	Hash string `json:"hash"`
+	Lt   int64  `json:"lt"`
+	// Account is the raw address of the account.
+	Account          string           `json:"account"`
+	Utime            int64            `json:"utime"`
+	Success          bool             `json:"success"`
+	Block            ton.BlockID      `json:"-"`
+	MasterchainSeqno uint32           `json:"masterchain_seqno"`
+	Messages         []IndexedMessage `json:"messages"`
+	// Transaction is the transaction as returned by tonapi.
+	Transaction Transaction `json:"-"`
+}
+
+// InMessage returns the inbound message of the transaction, if any.
+func (tx *IndexedTransaction) InMessage() (IndexedMessage, bool) {
+	for _, m := range tx.Messages {
+		if m.Direction == "in" {
+			return m, true
+		}
+	}
+	return IndexedMessage{}, false
+}
+
+// MarshalJSON adds the block ID in the "(workchain,shard,seqno)" form.
+func (tx IndexedTransaction) MarshalJSON() ([]byte, error) {
+	type plain IndexedTransaction
+	return json.Marshal(struct {
+		plain
+		Block string `json:"block"`
+	}{plain(tx), tx.Block.String()})
+}
+
+// IndexerBatch holds the transactions committed by a masterchain block that passed the filters.
+type IndexerBatch struct {
+	MasterchainSeqno uint32
+	// Blocks lists all blocks committed by the masterchain block in order, including blocks without matching transactions.
+	Blocks       []ton.BlockID
+	Transactions []IndexedTransaction
+}
+
+// TransactionFilter selects transactions to index.
+type TransactionFilter func(ctx context.Context, tx *IndexedTransaction) (bool, error)
+
+// AccountFilter selects transactions of the given accounts.
+func AccountFilter(accounts ...ton.AccountID) TransactionFilter {
+	set := make(map[string]struct{}, len(accounts))
+	for _, account := range accounts {
+		set[account.ToRaw()] = struct{}{}
+	}
+	return func(ctx context.Context, tx *IndexedTransaction) (bool, error) {
+		_, ok := set[tx.Account]
+		return ok, nil
+	}
+}
+
+// OperationFilter selects transactions whose inbound message has one of the given operations.
+// Each operation is either MsgOpName from https://github.com/tonkeeper/tongo/blob/master/abi/messages.go
+// or a hex string representing an unsigned 32-bit integer, like "0x0f8a7ea5".
+func OperationFilter(operations ...string) TransactionFilter {
+	names := map[string]struct{}{}
+	codes := map[uint32]struct{}{}
+	for _, op := range operations {
+		if code, err := strconv.ParseUint(strings.TrimPrefix(op, "0x"), 16, 32); err == nil && strings.HasPrefix(op, "0x") {
+			codes[uint32(code)] = struct{}{}
+			continue
+		}
+		names[op] = struct{}{}
+	}
+	return func(ctx context.Context, tx *IndexedTransaction) (bool, error) {
+		m, ok := tx.InMessage()
+		if !ok {
+			return false, nil
+		}
+		if _, ok := names[m.OpName]; ok && m.OpName != "" {
+			return true, nil
+		}
+		if m.OpCode == nil {
+			return false, nil
+		}
+		_, ok = codes[*m.OpCode]
+		return ok, nil
+	}
+}
+
+// InterfaceFilter selects transactions of accounts implementing any of the given interfaces, like "wallet_v4r2" or "jetton_wallet".
+// Interfaces are looked up with GetAccount once per account and cached,
+// put the filter after cheaper ones to limit the number of lookups.
+func InterfaceFilter(client *Client, interfaces ...string) TransactionFilter {
+	var mu sync.Mutex
+	cache := map[string]bool{}
+	return func(ctx context.Context, tx *IndexedTransaction) (bool, error) {
+		mu.Lock()
+		matched, ok := cache[tx.Account]
+		mu.Unlock()
+		if ok {
+			return matched, nil
+		}
+		account, err := client.GetAccount(ctx, GetAccountParams{AccountID: tx.Account})
+		if err != nil {
+			return false, errors.Wrapf(err, "get interfaces of %v", tx.Account)
+		}
+		matched = slices.ContainsFunc(account.Interfaces, func(i string) bool { return slices.Contains(interfaces, i) })
+		mu.Lock()
+		cache[tx.Account] = matched
+		mu.Unlock()
+		return matched, nil
+	}
+}
+
+// IndexerSink stores indexed transactions.
+type IndexerSink interface {
+	// Write stores the transactions of a masterchain block. The indexer calls Write for masterchain blocks
+	// one by one in order and saves its checkpoint once Write returns, so after a restart the last batch
+	// may be written again.
+	Write(ctx context.Context, batch IndexerBatch) error
+}
+
+// Indexer follows the blockchain and writes every transaction that passes its filters to a sink.
+//
+// It expands masterchain blocks into shard blocks like BlockFollower, fetching several masterchain blocks
+// in parallel while writing them to the sink strictly in order. Messages of every transaction are decoded,
+// then the filters are applied in order and a transaction is indexed if all of them accept it.
+// The seqno of the last written masterchain block is kept in a CheckpointStore under "<name>/masterchain".
+type Indexer struct {
+	client      *Client
+	store       CheckpointStore
+	name        string
+	sink        IndexerSink
+	expander    *blockExpander
+	filters     []TransactionFilter
+	concurrency int
+	consumerConfig
+}
+
+// IndexerOption configures an Indexer.
+type IndexerOption func(ix *Indexer)
+
+// WithIndexerFilters configures an Indexer to index only transactions accepted by all the filters.
+func WithIndexerFilters(filters ...TransactionFilter) IndexerOption {
+	return func(ix *Indexer) {
+		ix.filters = append(ix.filters, filters...)
+	}
+}
+
+// WithIndexerConcurrency configures the number of masterchain blocks fetched in parallel. The default is 4,
+// values below 1 are treated as 1.
+func WithIndexerConcurrency(n int) IndexerOption {
+	return func(ix *Indexer) {
+		ix.concurrency = n
+	}
+}
+
+// WithIndexerConsumerOptions configures an Indexer with the options of MasterchainConsumer:
+// WithConsumerStart sets the last processed masterchain seqno to start after when there is no checkpoint yet,
+// WithConsumerStreaming wakes the indexer up on new masterchain blocks.
+// By default, an Indexer starts from the current masterchain head and checks for new blocks every 10 seconds.
+func WithIndexerConsumerOptions(opts ...ConsumerOption) IndexerOption {
+	return func(ix *Indexer) {
+		for _, o := range opts {
+			o(&ix.consumerConfig)
+		}
+	}
+}
+
+// NewIndexer returns an indexer writing to the sink.
+func NewIndexer(client *Client, store CheckpointStore, name string, sink IndexerSink, opts ...IndexerOption) *Indexer {
+	ix := &Indexer{
+		client:         client,
+		store:          store,
+		name:           name,
+		sink:           sink,
+		expander:       newBlockExpander(client),
+		concurrency:    indexerConcurrency,
+		consumerConfig: newConsumerConfig(nil),
+	}
+	for _, o := range opts {
+		o(ix)
+	}
+	ix.concurrency = max(ix.concurrency, 1)
+	return ix
+}
+
+// CheckpointKey returns the key of the indexer's checkpoint.
+func (ix *Indexer) CheckpointKey() string {
+	return ix.name + "/masterchain"
+}
+
+// Run indexes transactions until the context is canceled or the checkpoint store fails.
+// Fetch and sink errors are logged and retried after the poll interval.
+func (ix *Indexer) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wake := make(chan struct{}, 1)
+	if ix.streaming != nil {
+		masterchain := -1
+		go ix.listen(ctx, wake, func(ctx context.Context, notify func()) error {
+			return ix.streaming.SubscribeToBlocks(ctx, &masterchain, func(BlockEventData) { notify() })
+		})
+	}
+	for {
+		err := ix.runOnce(ctx)
+		var cpErr *checkpointError
+		if errors.As(err, &cpErr) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			ix.logger.Error("indexer failed", "indexer", ix.name, "error", err)
+		}
+		if err := ix.wait(ctx, wake); err != nil {
+			return err
+		}
+	}
+}
+
+type indexerFetch struct {
+	batch IndexerBatch
+	err   error
+}
+
+// runOnce indexes masterchain blocks up to the current head.
+func (ix *Indexer) runOnce(ctx context.Context) error {
+	last, ok, err := ix.store.Load(ctx, ix.CheckpointKey())
+	if err != nil {
+		return &checkpointError{err}
+	}
+	head, err := ix.client.GetBlockchainMasterchainHead(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		last = uint64(head.Seqno) - 1
+		if ix.hasStart {
+			last = ix.start
+		}
+	}
+	// fetches run ahead of the commits by up to concurrency masterchain blocks.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pending := map[uint64]chan indexerFetch{}
+	scheduled := last
+	for next := last + 1; next <= uint64(head.Seqno); next++ {
+		for scheduled < uint64(head.Seqno) && scheduled-last < uint64(ix.concurrency) {
+			scheduled++
+			ch := make(chan indexerFetch, 1)
+			pending[scheduled] = ch
+			go func(seqno uint32) {
+				batch, err := ix.fetch(ctx, seqno)
+				ch <- indexerFetch{batch: batch, err: err}
+			}(uint32(scheduled))
+		}
+		var res indexerFetch
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case res = <-pending[next]:
+		}
+		delete(pending, next)
+		if res.err != nil {
+			return errors.Wrapf(res.err, "fetch masterchain block %v", next)
+		}
+		if err := ix.sink.Write(ctx, res.batch); err != nil {
+			return errors.Wrapf(err, "write masterchain block %v", next)
+		}
+		if err := ix.store.Save(ctx, ix.CheckpointKey(), next); err != nil {
+			return &checkpointError{err}
+		}
+		last = next
+	}
+	return nil
+}
+
+// fetch expands a masterchain block and runs its transactions through the decoder and the filters.
+func (ix *Indexer) fetch(ctx context.Context, seqno uint32) (IndexerBatch, error) {
+	blocks, err := ix.expander.expand(ctx, seqno)
+	if err != nil {
+		return IndexerBatch{}, err
+	}
+	batch := IndexerBatch{MasterchainSeqno: seqno, Blocks: make([]ton.BlockID, 0, len(blocks))}
+	for _, block := range blocks {
+		batch.Blocks = append(batch.Blocks, block.ID)
+		txs := slices.Clone(block.Transactions)
+		slices.SortFunc(txs, func(a, b Transaction) int { return cmp.Compare(a.Lt, b.Lt) })
+		for _, tx := range txs {
+			indexed := indexTransaction(tx, block.ID, seqno)
+			ok, err := ix.accept(ctx, &indexed)
+			if err != nil {
+				return IndexerBatch{}, err
+			}
+			if ok {
+				batch.Transactions = append(batch.Transactions, indexed)
+			}
+		}
+	}
+	return batch, nil
+}
+
+func (ix *Indexer) accept(ctx context.Context, tx *IndexedTransaction) (bool, error) {
+	for _, filter := range ix.filters {
+		ok, err := filter(ctx, tx)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func indexTransaction(tx Transaction, block ton.BlockID, seqno uint32) IndexedTransaction {
+	indexed := IndexedTransaction{
+		Hash:             tx.Hash,
+		Lt:               tx.Lt,
+		Account:          normalizeRaw(tx.Account.Address),
+		Utime:            tx.Utime,
+		Success:          tx.Success,
+		Block:            block,
+		MasterchainSeqno: seqno,
+		Transaction:      tx,
+	}
+	if m, ok := tx.InMsg.Get(); ok {
+		indexed.Messages = append(indexed.Messages, decodeIndexedMessage(m, "in"))
+	}
+	for _, m := range tx.OutMsgs {
+		indexed.Messages = append(indexed.Messages, decodeIndexedMessage(m, "out"))
+	}
+	return indexed
+}
+
+// decodeIndexedMessage takes the operation decoded by tonapi and decodes the raw body itself otherwise.
+func decodeIndexedMessage(m Message, direction string) IndexedMessage {
+	indexed := IndexedMessage{Hash: m.Hash, Direction: direction, Value: m.Value, OpName: m.DecodedOpName.Or("")}
+	if source, ok := m.Source.Get(); ok {
+		indexed.Source = normalizeRaw(source.Address)
+	}
+	if destination, ok := m.Destination.Get(); ok {
+		indexed.Destination = normalizeRaw(destination.Address)
+	}
+	if code, err := strconv.ParseUint(strings.TrimPrefix(m.OpCode.Or(""), "0x"), 16, 32); err == nil {
+		op := uint32(code)
+		indexed.OpCode = &op
+	}
+	if len(m.DecodedBody) > 0 && string(m.DecodedBody) != "null" {
+		indexed.Body = json.RawMessage(m.DecodedBody)
+	}
+	rawBody, ok := m.RawBody.Get()
+	if (indexed.OpName != "" && indexed.Body != nil) || !ok || rawBody == "" {
+		return indexed
+	}
+	cell, err := deserializeSingleCell(rawBody)
+	if err != nil {
+		return indexed
+	}
+	decoder := abi.InternalMessageDecoder
+	if m.MsgType == MessageMsgTypeExtInMsg {
+		decoder = abi.ExtInMessageDecoder
+	}
+	code, name, body, err := decoder(cell, nil)
+	if err != nil {
+		return indexed
+	}
+	if code != nil {
+		indexed.OpCode = code
+	}
+	if name != nil {
+		indexed.OpName = *name
+		if data, err := json.Marshal(body); err == nil {
+			indexed.Body = data
+		}
+	}
+	return indexed
+}
+
+// normalizeRaw converts an address to the raw form, returning it as is if it cannot be parsed.
+func normalizeRaw(address string) string {
+	account, err := ton.ParseAccountID(address)
+	if err != nil {
+		return address
+	}
+	return account.ToRaw()
+}
@@ -0,0 +1,253 @@
+package tonapi
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/boc"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func TestIndexer(t *testing.T) {
+	wallet := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	other := ton.MustParseAccountID("0:2222222222222222222222222222222222222222222222222222222222222222")
+	comment := boc.NewCell()
+	require.NoError(t, comment.WriteUint(0, 32))
+	require.NoError(t, comment.WriteBytes([]byte("hi")))
+	commentBoc, err := comment.ToBocString()
+	require.NoError(t, err)
+
+	transaction := func(account ton.AccountID, lt int64, in Message) Transaction {
+		tx := consumerTestTransaction(lt)
+		tx.Hash = fmt.Sprintf("%x", lt)
+		tx.Account = AccountAddress{Address: account.ToRaw()}
+		in.MsgType = MessageMsgTypeIntMsg
+		in.Destination = NewOptAccountAddress(AccountAddress{Address: account.ToRaw()})
+		tx.InMsg = NewOptMessage(in)
+		return tx
+	}
+	jettonTransfer := Message{Hash: "m", OpCode: NewOptString("0x0f8a7ea5"), DecodedOpName: NewOptString("jetton_transfer"), DecodedBody: []byte(`{"query_id":1}`)}
+	// shard block 100+n is committed by masterchain block 9+n.
+	transactions := map[uint32][]Transaction{
+		101: {transaction(other, 1012, Message{Hash: "c", RawBody: NewOptString(commentBoc)}), transaction(wallet, 1011, jettonTransfer)},
+		102: {transaction(wallet, 1021, Message{Hash: "e"})},
+		103: {transaction(wallet, 1031, jettonTransfer)},
+	}
+	shardID := func(seqno uint32) string { return fmt.Sprintf("(0,8000000000000000,%d)", seqno) }
+	shardBlock := func(seqno uint32) BlockchainBlock {
+		return BlockchainBlock{Shard: "8000000000000000", Seqno: int32(seqno), TxQuantity: len(transactions[seqno]), PrevRefs: []string{shardID(seqno - 1)}}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/"), "/")
+		var data []byte
+		switch {
+		case parts[1] == "masterchain-head":
+			data, _ = (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: 12}).MarshalJSON()
+		case parts[1] == "masterchain" && parts[3] == "shards":
+			var seqno uint32
+			fmt.Sscan(parts[2], &seqno)
+			data, _ = (&BlockchainBlockShards{Shards: []BlockchainBlockShardsShardsItem{{LastKnownBlockID: shardID(seqno + 91)}}}).MarshalJSON()
+		case parts[1] == "masterchain" && parts[3] == "blocks":
+			var seqno uint32
+			fmt.Sscan(parts[2], &seqno)
+			data, _ = (&BlockchainBlocks{Blocks: []BlockchainBlock{
+				shardBlock(seqno + 91),
+				{WorkchainID: -1, Shard: "8000000000000000", Seqno: int32(seqno)},
+			}}).MarshalJSON()
+		case parts[1] == "blocks" && parts[3] == "transactions":
+			id := ton.MustParseBlockID(parts[2])
+			data, _ = (&Transactions{Transactions: append([]Transaction{}, transactions[id.Seqno]...)}).MarshalJSON()
+		case parts[0] == "accounts":
+			account := Account{Address: parts[1], Status: AccountStatusActive}
+			if parts[1] == wallet.ToRaw() {
+				account.Interfaces = []string{"wallet_v4r2"}
+			}
+			data, _ = account.MarshalJSON()
+		default:
+			t.Errorf("unexpected request %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// filtered transactions are written in order as JSON lines.
+	var out bytes.Buffer
+	store := NewMemoryCheckpointStore()
+	indexer := NewIndexer(client, store, "jettons", NewJSONLSink(&out),
+		WithIndexerConsumerOptions(WithConsumerStart(9)),
+		WithIndexerConcurrency(2),
+		WithIndexerFilters(AccountFilter(wallet), InterfaceFilter(client, "wallet_v4r2"), OperationFilter("0x0f8a7ea5")))
+	require.NoError(t, indexer.runOnce(ctx))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var first IndexedTransaction
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.Equal(t, int64(1011), first.Lt)
+	require.Equal(t, "jetton_transfer", first.Messages[0].OpName)
+	require.JSONEq(t, `{"query_id":1}`, string(first.Messages[0].Body))
+	require.Contains(t, lines[0], `"block":"(0,8000000000000000,101)"`)
+	require.Contains(t, lines[1], `"lt":1031`)
+	position, _, _ := store.Load(ctx, indexer.CheckpointKey())
+	require.Equal(t, uint64(12), position)
+
+	// a restarted indexer has nothing to do.
+	out.Reset()
+	require.NoError(t, NewIndexer(client, store, "jettons", NewJSONLSink(&out)).runOnce(ctx))
+	require.Empty(t, out.String())
+
+	// without filters every transaction is indexed, raw bodies are decoded locally.
+	batches := make(chan IndexerBatch, 3)
+	require.NoError(t, NewIndexer(client, NewMemoryCheckpointStore(), "all", ChannelSink(batches), WithIndexerConsumerOptions(WithConsumerStart(9))).runOnce(ctx))
+	close(batches)
+	var seqnos []uint32
+	var lts []int64
+	var decoded IndexedMessage
+	for batch := range batches {
+		seqnos = append(seqnos, batch.MasterchainSeqno)
+		require.Len(t, batch.Blocks, 2)
+		for _, tx := range batch.Transactions {
+			lts = append(lts, tx.Lt)
+			if tx.Lt == 1012 {
+				decoded, _ = tx.InMessage()
+			}
+		}
+	}
+	require.Equal(t, []uint32{10, 11, 12}, seqnos)
+	require.Equal(t, []int64{1011, 1012, 1021, 1031}, lts)
+	require.Equal(t, "TextComment", decoded.OpName)
+	require.Equal(t, uint32(0), *decoded.OpCode)
+	require.Contains(t, string(decoded.Body), "hi")
+
+	// invalid concurrency fetches blocks one by one.
+	for _, n := range []int{0, -1} {
+		batches := make(chan IndexerBatch, 3)
+		require.NoError(t, NewIndexer(client, NewMemoryCheckpointStore(), "serial", ChannelSink(batches), WithIndexerConsumerOptions(WithConsumerStart(9)), WithIndexerConcurrency(n)).runOnce(ctx))
+		require.Len(t, batches, 3)
+	}
+}
+
+func TestPostgresSink(t *testing.T) {
+	db := sql.OpenDB(&fakeConnector{})
+	defer db.Close()
+	conn := db.Driver().(*fakeConnector)
+	sink := NewPostgresSink(db)
+	ctx := context.Background()
+
+	op := uint32(0x0f8a7ea5)
+	batch := IndexerBatch{MasterchainSeqno: 10, Transactions: []IndexedTransaction{{
+		Hash:             "ab",
+		Lt:               7,
+		Account:          "0:11",
+		Block:            ton.BlockID{Shard: masterchainShard, Seqno: 101},
+		MasterchainSeqno: 10,
+		Messages:         []IndexedMessage{{Hash: "m", Direction: "in", Value: 5, OpCode: &op, OpName: "jetton_transfer"}},
+	}}}
+	require.NoError(t, sink.Write(ctx, batch))
+	require.Len(t, conn.execs, 2)
+	require.Contains(t, conn.execs[0].query, "INSERT INTO transactions")
+	require.Equal(t, []driver.Value{"ab", int64(7), "0:11", int64(0), false, "(0,8000000000000000,101)", int64(10)}, conn.execs[0].args)
+	require.Equal(t, []driver.Value{"ab", int64(0), "in", "m", nil, nil, int64(5), int64(op), "jetton_transfer", nil}, conn.execs[1].args)
+	require.Equal(t, 1, conn.commits)
+
+	_, ok, err := sink.Load(ctx, "indexer/masterchain")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, sink.Save(ctx, "indexer/masterchain", 10))
+	position, ok, err := sink.Load(ctx, "indexer/masterchain")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(10), position)
+}
+
+// fakeConnector is a database/sql driver recording statements. It keeps checkpoints
+// saved with an upsert into indexer_checkpoints to answer selects from that table.
+type fakeConnector struct {
+	mu          sync.Mutex
+	execs       []fakeExec
+	commits     int
+	checkpoints map[string]int64
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{c}, nil }
+func (c *fakeConnector) Driver() driver.Driver                        { return c }
+func (c *fakeConnector) Open(string) (driver.Conn, error)             { return &fakeConn{c}, nil }
+
+type fakeConn struct{ c *fakeConnector }
+
+func (f *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{f.c, query}, nil }
+func (f *fakeConn) Close() error                              { return nil }
+func (f *fakeConn) Begin() (driver.Tx, error)                 { return f, nil }
+func (f *fakeConn) Commit() error {
+	f.c.mu.Lock()
+	defer f.c.mu.Unlock()
+	f.c.commits++
+	return nil
+}
+func (f *fakeConn) Rollback() error { return nil }
+
+type fakeStmt struct {
+	c     *fakeConnector
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	if strings.Contains(s.query, "indexer_checkpoints") {
+		if s.c.checkpoints == nil {
+			s.c.checkpoints = map[string]int64{}
+		}
+		s.c.checkpoints[args[0].(string)] = args[1].(int64)
+		return driver.RowsAffected(1), nil
+	}
+	s.c.execs = append(s.c.execs, fakeExec{query: s.query, args: args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	rows := &fakeRows{}
+	if position, ok := s.c.checkpoints[args[0].(string)]; ok {
+		rows.values = []driver.Value{position}
+	}
+	return rows, nil
+}
+
+type fakeRows struct{ values []driver.Value }
+
+func (r *fakeRows) Columns() []string { return []string{"position"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.values == nil {
+		return io.EOF
+	}
+	copy(dest, r.values)
+	r.values = nil
+	return nil
+}
@@ -0,0 +1,159 @@
+package tonapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/go-faster/errors"
+)
+
+// JSONLSink writes indexed transactions as JSON lines.
+// If the writer has a Sync method, like *os.File, it is called after every batch
+// so the checkpoint never gets ahead of the data.
+type JSONLSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLSink returns a sink writing to w.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{w: w}
+}
+
+func (s *JSONLSink) Write(ctx context.Context, batch IndexerBatch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := json.NewEncoder(s.w)
+	for _, tx := range batch.Transactions {
+		if err := enc.Encode(tx); err != nil {
+			return err
+		}
+	}
+	if syncer, ok := s.w.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// ChannelSink sends batches to a channel. A batch counts as written once it is received,
+// so the receiver should process it before receiving the next one.
+type ChannelSink chan<- IndexerBatch
+
+func (s ChannelSink) Write(ctx context.Context, batch IndexerBatch) error {
+	select {
+	case s <- batch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// PostgresIndexerSchema creates the tables used by PostgresSink.
+const PostgresIndexerSchema = `
+CREATE TABLE IF NOT EXISTS transactions (
+	hash              TEXT PRIMARY KEY,
+	lt                BIGINT NOT NULL,
+	account           TEXT NOT NULL,
+	utime             BIGINT NOT NULL,
+	success           BOOLEAN NOT NULL,
+	block             TEXT NOT NULL,
+	masterchain_seqno BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transactions_account_lt ON transactions (account, lt);
+CREATE TABLE IF NOT EXISTS messages (
+	transaction_hash TEXT NOT NULL REFERENCES transactions (hash),
+	position         INTEGER NOT NULL,
+	direction        TEXT NOT NULL,
+	hash             TEXT NOT NULL,
+	source           TEXT,
+	destination      TEXT,
+	value            BIGINT NOT NULL,
+	op_code          BIGINT,
+	op_name          TEXT,
+	body             JSONB,
+	PRIMARY KEY (transaction_hash, position)
+);
+CREATE TABLE IF NOT EXISTS indexer_checkpoints (
+	key      TEXT PRIMARY KEY,
+	position BIGINT NOT NULL
+);
+`
+
+// PostgresSink writes indexed transactions to the tables of PostgresIndexerSchema
+// using any PostgreSQL driver for database/sql. Every batch is written in a database transaction
+// and rows that already exist are skipped, so writing a batch again after a restart is harmless.
+//
+// PostgresSink is also a CheckpointStore keeping positions in the indexer_checkpoints table,
+// which keeps the data and the checkpoint in the same database.
+type PostgresSink struct {
+	db *sql.DB
+}
+
+// NewPostgresSink returns a sink writing to db. Create the tables with PostgresIndexerSchema beforehand.
+func NewPostgresSink(db *sql.DB) *PostgresSink {
+	return &PostgresSink{db: db}
+}
+
+func (s *PostgresSink) Write(ctx context.Context, batch IndexerBatch) error {
+	if len(batch.Transactions) == 0 {
+		return nil
+	}
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+	for _, tx := range batch.Transactions {
+		if _, err := dbTx.ExecContext(ctx,
+			`INSERT INTO transactions (hash, lt, account, utime, success, block, masterchain_seqno)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (hash) DO NOTHING`,
+			tx.Hash, tx.Lt, tx.Account, tx.Utime, tx.Success, tx.Block.String(), int64(tx.MasterchainSeqno)); err != nil {
+			return errors.Wrapf(err, "insert transaction %v", tx.Hash)
+		}
+		for i, m := range tx.Messages {
+			var opCode, body any
+			if m.OpCode != nil {
+				opCode = int64(*m.OpCode)
+			}
+			if m.Body != nil {
+				body = string(m.Body)
+			}
+			if _, err := dbTx.ExecContext(ctx,
+				`INSERT INTO messages (transaction_hash, position, direction, hash, source, destination, value, op_code, op_name, body)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT (transaction_hash, position) DO NOTHING`,
+				tx.Hash, i, m.Direction, m.Hash, nullString(m.Source), nullString(m.Destination), m.Value, opCode, nullString(m.OpName), body); err != nil {
+				return errors.Wrapf(err, "insert message %v", m.Hash)
+			}
+		}
+	}
+	return dbTx.Commit()
+}
+
+func (s *PostgresSink) Load(ctx context.Context, key string) (uint64, bool, error) {
+	var position int64
+	err := s.db.QueryRowContext(ctx, `SELECT position FROM indexer_checkpoints WHERE key = $1`, key).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(position), true, nil
+}
+
+func (s *PostgresSink) Save(ctx context.Context, key string, position uint64) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO indexer_checkpoints (key, position) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET position = EXCLUDED.position`,
+		key, int64(position))
+	return err
+}
+
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
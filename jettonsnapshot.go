@@ -0,0 +1,226 @@
+package tonapi
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/tonkeeper/tongo/ton"
+)
+
+const (
+	// jettonHoldersPageSize is the maximum page size of GetJettonHolders.
+	jettonHoldersPageSize = 1000
+	// jettonHoldersMaxOffset is the maximum offset accepted by GetJettonHolders.
+	jettonHoldersMaxOffset = 9000
+	// defaultSnapshotPageOverlap is the number of rows each page shares with the previous one
+	// to catch holders moving across page boundaries while paging.
+	defaultSnapshotPageOverlap = 50
+)
+
+// JettonHolder is a row of a JettonSnapshot.
+type JettonHolder struct {
+	Owner   ton.AccountID `json:"owner"`
+	Wallet  ton.AccountID `json:"wallet"`
+	Balance *big.Int      `json:"balance"`
+	// Verified is set when the balance was re-read with GetAccountJettonBalance
+	// because the row changed while paging.
+	Verified bool `json:"verified,omitempty"`
+}
+
+// JettonSnapshot is a list of jetton holders collected at a point in time.
+type JettonSnapshot struct {
+	Jetton ton.AccountID `json:"jetton"`
+	// MasterchainSeqno is the masterchain block the snapshot was started at.
+	MasterchainSeqno int32     `json:"masterchain_seqno"`
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	// Holders are sorted by balance in descending order.
+	Holders []JettonHolder `json:"holders"`
+	// ReportedHolders is JettonHolders.Total as reported by the first page.
+	ReportedHolders int64 `json:"reported_holders"`
+	// TotalSupply is JettonInfo.TotalSupply.
+	TotalSupply *big.Int `json:"total_supply"`
+	// TotalBalance is the sum of balances of all holders in the snapshot.
+	TotalBalance *big.Int `json:"total_balance"`
+	// Truncated is set when the jetton has more holders than GetJettonHolders can page through.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// JettonSnapshotReconciliation compares the snapshot with the totals reported by tonapi.
+type JettonSnapshotReconciliation struct {
+	// MissingHolders is the number of holders reported by tonapi but not present in the snapshot.
+	// It is negative if the snapshot has more holders, for example when holders appeared while paging.
+	MissingHolders int64
+	// UnaccountedSupply is the part of the total supply not held by the holders in the snapshot.
+	UnaccountedSupply *big.Int
+}
+
+// Reconcile compares the snapshot with JettonHolders.Total and JettonInfo.TotalSupply.
+func (s *JettonSnapshot) Reconcile() JettonSnapshotReconciliation {
+	return JettonSnapshotReconciliation{
+		MissingHolders:    s.ReportedHolders - int64(len(s.Holders)),
+		UnaccountedSupply: new(big.Int).Sub(s.TotalSupply, s.TotalBalance),
+	}
+}
+
+// WriteCSV writes the holders as CSV with owner, wallet, balance and verified columns.
+// Addresses are written in the raw form.
+func (s *JettonSnapshot) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"owner", "wallet", "balance", "verified"}); err != nil {
+		return err
+	}
+	for _, h := range s.Holders {
+		record := []string{h.Owner.ToRaw(), h.Wallet.ToRaw(), h.Balance.String(), fmt.Sprintf("%v", h.Verified)}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the snapshot including its metadata as indented JSON.
+func (s *JettonSnapshot) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// JettonSnapshotOption configures JettonHoldersSnapshot.
+type JettonSnapshotOption func(o *jettonSnapshotOptions)
+
+type jettonSnapshotOptions struct {
+	overlap int
+}
+
+// WithSnapshotPageOverlap sets the number of rows each page shares with the previous one. Defaults to 50.
+// A larger overlap tolerates more balance changes while paging at the cost of more requests.
+func WithSnapshotPageOverlap(overlap int) JettonSnapshotOption {
+	return func(o *jettonSnapshotOptions) {
+		o.overlap = overlap
+	}
+}
+
+// JettonHoldersSnapshot pages through GetJettonHolders and returns a consistent list of holders of the jetton.
+//
+// Holders are ordered by balance, so a balance change while paging moves a holder across pages.
+// Pages overlap to catch such moves, and a holder whose balance differs between pages
+// is re-read with GetAccountJettonBalance.
+// Re-read balances are current ones and may be newer than MasterchainSeqno.
+func (c *Client) JettonHoldersSnapshot(ctx context.Context, jetton ton.AccountID, opts ...JettonSnapshotOption) (*JettonSnapshot, error) {
+	options := jettonSnapshotOptions{overlap: defaultSnapshotPageOverlap}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.overlap < 0 || options.overlap >= jettonHoldersPageSize {
+		return nil, fmt.Errorf("invalid page overlap %v", options.overlap)
+	}
+	head, err := c.GetBlockchainMasterchainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.GetJettonInfo(ctx, GetJettonInfoParams{AccountID: jetton.ToRaw()})
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &JettonSnapshot{
+		Jetton:           jetton,
+		MasterchainSeqno: head.Seqno,
+		StartedAt:        time.Now(),
+		TotalBalance:     new(big.Int),
+	}
+	var ok bool
+	if snapshot.TotalSupply, ok = new(big.Int).SetString(info.TotalSupply, 10); !ok {
+		return nil, fmt.Errorf("invalid total supply %q", info.TotalSupply)
+	}
+
+	holders := map[ton.AccountID]*JettonHolder{}
+	suspicious := map[ton.AccountID]struct{}{}
+	step := jettonHoldersPageSize - options.overlap
+	for offset := 0; ; offset = min(offset+step, jettonHoldersMaxOffset) {
+		page, err := c.GetJettonHolders(ctx, GetJettonHoldersParams{
+			AccountID: jetton.ToRaw(),
+			Limit:     NewOptInt(jettonHoldersPageSize),
+			Offset:    NewOptInt(offset),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if offset == 0 {
+			snapshot.ReportedHolders = page.Total
+		}
+		for _, item := range page.Addresses {
+			holder, err := parseJettonHolder(item)
+			if err != nil {
+				return nil, err
+			}
+			if previous, ok := holders[holder.Wallet]; ok && previous.Balance.Cmp(holder.Balance) != 0 {
+				suspicious[holder.Wallet] = struct{}{}
+			}
+			holders[holder.Wallet] = holder
+		}
+		if len(page.Addresses) < jettonHoldersPageSize {
+			break
+		}
+		// the last page GetJettonHolders serves is full, there may be holders past it.
+		if offset == jettonHoldersMaxOffset {
+			snapshot.Truncated = true
+			break
+		}
+	}
+
+	for wallet := range suspicious {
+		holder := holders[wallet]
+		balance, err := c.GetAccountJettonBalance(ctx, GetAccountJettonBalanceParams{
+			AccountID: holder.Owner.ToRaw(),
+			JettonID:  jetton.ToRaw(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify balance of %v: %w", holder.Owner.ToRaw(), err)
+		}
+		if holder.Balance, ok = new(big.Int).SetString(balance.Balance, 10); !ok {
+			return nil, fmt.Errorf("invalid balance %q of %v", balance.Balance, holder.Owner.ToRaw())
+		}
+		holder.Verified = true
+	}
+
+	snapshot.Holders = make([]JettonHolder, 0, len(holders))
+	for _, holder := range holders {
+		if holder.Balance.Sign() == 0 {
+			continue
+		}
+		snapshot.Holders = append(snapshot.Holders, *holder)
+		snapshot.TotalBalance.Add(snapshot.TotalBalance, holder.Balance)
+	}
+	sort.Slice(snapshot.Holders, func(i, j int) bool {
+		if cmp := snapshot.Holders[i].Balance.Cmp(snapshot.Holders[j].Balance); cmp != 0 {
+			return cmp > 0
+		}
+		return snapshot.Holders[i].Wallet.ToRaw() < snapshot.Holders[j].Wallet.ToRaw()
+	})
+	snapshot.FinishedAt = time.Now()
+	return snapshot, nil
+}
+
+func parseJettonHolder(item JettonHoldersAddressesItem) (*JettonHolder, error) {
+	wallet, err := ton.ParseAccountID(item.Address)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := ton.ParseAccountID(item.Owner.Address)
+	if err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(item.Balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance %q of %v", item.Balance, item.Address)
+	}
+	return &JettonHolder{Owner: owner, Wallet: wallet, Balance: balance}, nil
+}
@@ -0,0 +1,147 @@
+package tonapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func TestJettonHoldersSnapshot(t *testing.T) {
+	jetton := ton.MustParseAccountID("0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe")
+	address := func(prefix string, i int) string {
+		return fmt.Sprintf("0:%s%062x", prefix, i)
+	}
+	// holders are sorted by balance, the holder at index 10 sends half of its balance after the first page is read.
+	const total = 1500
+	balances := make([]int64, total)
+	for i := range balances {
+		balances[i] = int64(total-i) * 10
+	}
+	moved := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var data []byte
+		switch {
+		case r.URL.Path == "/v2/blockchain/masterchain-head":
+			data, _ = (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: 42}).MarshalJSON()
+		case r.URL.Path == "/v2/jettons/"+jetton.ToRaw():
+			data, _ = (&JettonInfo{TotalSupply: "20000000", Verification: JettonVerificationTypeWhitelist}).MarshalJSON()
+		case r.URL.Path == "/v2/jettons/"+jetton.ToRaw()+"/holders":
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			page := JettonHolders{Total: total}
+			for i := offset; i < offset+limit && i < total; i++ {
+				page.Addresses = append(page.Addresses, JettonHoldersAddressesItem{
+					Address: address("aa", i),
+					Owner:   AccountAddress{Address: address("bb", i)},
+					Balance: strconv.FormatInt(balances[i], 10),
+				})
+			}
+			if !moved {
+				moved = true
+				balances[960] /= 2
+			}
+			data, _ = page.MarshalJSON()
+		case strings.HasPrefix(r.URL.Path, "/v2/accounts/"+address("bb", 960)+"/jettons/"):
+			data, _ = (&JettonBalance{
+				Balance: strconv.FormatInt(balances[960], 10),
+				Jetton:  JettonPreview{Verification: JettonVerificationTypeWhitelist},
+			}).MarshalJSON()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	snapshot, err := client.JettonHoldersSnapshot(context.Background(), jetton)
+	require.NoError(t, err)
+
+	require.Equal(t, int32(42), snapshot.MasterchainSeqno)
+	require.Len(t, snapshot.Holders, total)
+	require.False(t, snapshot.Truncated)
+	verified := 0
+	for _, h := range snapshot.Holders {
+		if h.Verified {
+			verified++
+			require.Equal(t, address("bb", 960), h.Owner.ToRaw())
+			require.Equal(t, big.NewInt(balances[960]), h.Balance)
+		}
+	}
+	require.Equal(t, 1, verified)
+
+	reconciliation := snapshot.Reconcile()
+	require.Equal(t, int64(0), reconciliation.MissingHolders)
+	var sum int64
+	for _, b := range balances {
+		sum += b
+	}
+	require.Equal(t, big.NewInt(20000000-sum), reconciliation.UnaccountedSupply)
+
+	var buf bytes.Buffer
+	require.NoError(t, snapshot.WriteCSV(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, total+1)
+	require.Equal(t, "owner,wallet,balance,verified", lines[0])
+	require.Equal(t, address("bb", 0)+","+address("aa", 0)+",15000,false", lines[1])
+}
+
+func TestJettonHoldersSnapshotTruncated(t *testing.T) {
+	jetton := ton.MustParseAccountID("0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe")
+	const total = 12000
+	var offsets []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var data []byte
+		switch r.URL.Path {
+		case "/v2/blockchain/masterchain-head":
+			data, _ = (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: 42}).MarshalJSON()
+		case "/v2/jettons/" + jetton.ToRaw():
+			data, _ = (&JettonInfo{TotalSupply: "1000000000", Verification: JettonVerificationTypeWhitelist}).MarshalJSON()
+		case "/v2/jettons/" + jetton.ToRaw() + "/holders":
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			if offset > jettonHoldersMaxOffset {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"offset is too big"}`))
+				return
+			}
+			offsets = append(offsets, offset)
+			page := JettonHolders{Total: total}
+			for i := offset; i < offset+limit && i < total; i++ {
+				page.Addresses = append(page.Addresses, JettonHoldersAddressesItem{
+					Address: fmt.Sprintf("0:aa%062x", i),
+					Owner:   AccountAddress{Address: fmt.Sprintf("0:bb%062x", i)},
+					Balance: strconv.Itoa(total - i),
+				})
+			}
+			data, _ = page.MarshalJSON()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	snapshot, err := client.JettonHoldersSnapshot(context.Background(), jetton)
+	require.NoError(t, err)
+	// the last offset is clamped to the maximum, so the holders up to the last page are read.
+	require.Equal(t, []int{0, 950, 1900, 2850, 3800, 4750, 5700, 6650, 7600, 8550, 9000}, offsets)
+	require.True(t, snapshot.Truncated)
+	require.Len(t, snapshot.Holders, jettonHoldersMaxOffset+jettonHoldersPageSize)
+}
@@ -0,0 +1,345 @@
+package tonapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	ht "github.com/ogen-go/ogen/http"
+	"golang.org/x/time/rate"
+)
+
+// APIKey is a tonapi key with an optional per-key quota.
+type APIKey struct {
+	Token string
+	// RPS is the number of requests per second the key is allowed to make.
+	// Zero means the pool doesn't limit the key.
+	RPS float64
+}
+
+// KeyStats contains usage statistics of a key in KeyPool.
+type KeyStats struct {
+	// Key is the key with all but the last 4 characters masked.
+	Key          string
+	Requests     uint64
+	Throttled    uint64
+	Unauthorized uint64
+	// BenchedUntil is set when the key is temporarily excluded from rotation.
+	BenchedUntil time.Time
+}
+
+// ErrNoKeysAvailable is returned by KeyPool when all keys are benched.
+var ErrNoKeysAvailable = errors.New("no api keys available")
+
+// KeyLoader returns the current list of keys for KeyPool.Refresh.
+type KeyLoader func(ctx context.Context) ([]APIKey, error)
+
+type poolKey struct {
+	APIKey
+	quota        *rate.Limiter
+	requests     uint64
+	throttled    uint64
+	unauthorized uint64
+	benchedUntil time.Time
+}
+
+// KeyPool is a SecuritySource that rotates requests across several API keys.
+//
+// The pool tracks per-key usage and quotas and temporarily benches keys that are throttled with 429 or rejected with 401/403.
+// To see responses, the pool must also wrap the HTTP client of the Client:
+//
+//	pool := tonapi.NewKeyPool([]tonapi.APIKey{{Token: key1, RPS: 10}, {Token: key2, RPS: 10}})
+//	client, err := tonapi.NewClient(tonapi.TonApiURL, pool, tonapi.WithClient(pool.Wrap(http.DefaultClient)))
+//
+// Keys can be replaced at any time with Reload or Refresh without recreating the client.
+type KeyPool struct {
+	throttleCooldown     time.Duration
+	unauthorizedCooldown time.Duration
+	logger               Logger
+
+	// mu protects the fields below.
+	mu   sync.Mutex
+	keys []*poolKey
+	next int
+}
+
+type KeyPoolOptions struct {
+	throttleCooldown     time.Duration
+	unauthorizedCooldown time.Duration
+	logger               Logger
+}
+
+type KeyPoolOption func(*KeyPoolOptions)
+
+// WithKeyThrottleCooldown configures how long a key stays benched after a 429 response. The default is 10 seconds.
+func WithKeyThrottleCooldown(d time.Duration) KeyPoolOption {
+	return func(o *KeyPoolOptions) {
+		o.throttleCooldown = d
+	}
+}
+
+// WithKeyUnauthorizedCooldown configures how long a key stays benched after a 401 or 403 response. The default is 10 minutes.
+func WithKeyUnauthorizedCooldown(d time.Duration) KeyPoolOption {
+	return func(o *KeyPoolOptions) {
+		o.unauthorizedCooldown = d
+	}
+}
+
+// WithKeyPoolLogger configures a KeyPool instance to report benched keys to the given logger.
+func WithKeyPoolLogger(logger Logger) KeyPoolOption {
+	return func(o *KeyPoolOptions) {
+		o.logger = logger
+	}
+}
+
+// NewKeyPool returns a new KeyPool.
+func NewKeyPool(keys []APIKey, opts ...KeyPoolOption) *KeyPool {
+	options := &KeyPoolOptions{
+		throttleCooldown:     10 * time.Second,
+		unauthorizedCooldown: 10 * time.Minute,
+		logger:               &noopLogger{},
+	}
+	for _, o := range opts {
+		o(options)
+	}
+	pool := &KeyPool{
+		throttleCooldown:     options.throttleCooldown,
+		unauthorizedCooldown: options.unauthorizedCooldown,
+		logger:               options.logger,
+	}
+	pool.Reload(keys)
+	return pool
+}
+
+// Wrap returns an HTTP client reporting responses of next to the pool, so it can bench throttled and revoked keys.
+// Use it with WithClient.
+func (p *KeyPool) Wrap(next ht.Client) ht.Client {
+	return &keyPoolClient{pool: p, next: next}
+}
+
+// BearerAuth returns the next available key.
+// Keys with an exhausted quota are skipped. If every available key has exhausted its quota,
+// BearerAuth waits until the first of them frees up or the context is done.
+func (p *KeyPool) BearerAuth(ctx context.Context, operationName OperationName, client *Client) (BearerAuth, error) {
+	p.mu.Lock()
+	now := time.Now()
+	var (
+		fallback    *poolKey
+		reservation *rate.Reservation
+	)
+	for i := 0; i < len(p.keys); i++ {
+		key := p.keys[(p.next+i)%len(p.keys)]
+		if now.Before(key.benchedUntil) {
+			continue
+		}
+		if key.quota != nil && !key.quota.AllowN(now, 1) {
+			// reserve a token of the key that frees up first.
+			r := key.quota.ReserveN(now, 1)
+			if reservation == nil || r.DelayFrom(now) < reservation.DelayFrom(now) {
+				if reservation != nil {
+					reservation.CancelAt(now)
+				}
+				fallback, reservation = key, r
+			} else {
+				r.CancelAt(now)
+			}
+			continue
+		}
+		if reservation != nil {
+			reservation.CancelAt(now)
+		}
+		p.next = (p.next + i + 1) % len(p.keys)
+		key.requests++
+		p.mu.Unlock()
+		return BearerAuth{Token: key.Token}, nil
+	}
+	if fallback == nil {
+		p.mu.Unlock()
+		return BearerAuth{}, ErrNoKeysAvailable
+	}
+	p.mu.Unlock()
+
+	timer := time.NewTimer(reservation.DelayFrom(now))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		reservation.Cancel()
+		return BearerAuth{}, ctx.Err()
+	case <-timer.C:
+		p.mu.Lock()
+		fallback.requests++
+		p.mu.Unlock()
+		return BearerAuth{Token: fallback.Token}, nil
+	}
+}
+
+// Reload replaces the keys of the pool. Statistics and bench state of keys present in both lists are preserved.
+func (p *KeyPool) Reload(keys []APIKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	existing := make(map[string]*poolKey, len(p.keys))
+	for _, key := range p.keys {
+		existing[key.Token] = key
+	}
+	p.keys = make([]*poolKey, 0, len(keys))
+	for _, k := range keys {
+		if k.Token == "" {
+			continue
+		}
+		key, ok := existing[k.Token]
+		if !ok || key.RPS != k.RPS {
+			key = &poolKey{APIKey: k}
+			if prev, ok := existing[k.Token]; ok {
+				key.requests, key.throttled, key.unauthorized = prev.requests, prev.throttled, prev.unauthorized
+				key.benchedUntil = prev.benchedUntil
+			}
+			if k.RPS > 0 {
+				burst := int(k.RPS)
+				if burst < 1 {
+					burst = 1
+				}
+				key.quota = rate.NewLimiter(rate.Limit(k.RPS), burst)
+			}
+		}
+		p.keys = append(p.keys, key)
+	}
+	if p.next >= len(p.keys) {
+		p.next = 0
+	}
+}
+
+// Refresh reloads keys from the loader periodically until the context is canceled.
+// Errors of the loader are logged and the previous keys are kept.
+func (p *KeyPool) Refresh(ctx context.Context, interval time.Duration, loader KeyLoader) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		keys, err := loader(ctx)
+		if err != nil {
+			p.logger.Errorf("failed to load api keys: %v", err)
+		} else {
+			p.Reload(keys)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// KeysFromFile returns a KeyLoader reading keys from a text file.
+// Each line contains a key optionally followed by its RPS quota, lines starting with # are ignored:
+//
+//	# indexer keys
+//	AE7YQ...  10
+//	AFXRT...  10
+func KeysFromFile(path string) KeyLoader {
+	return func(ctx context.Context) ([]APIKey, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var keys []APIKey
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for line := 1; scanner.Scan(); line++ {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			key := APIKey{Token: fields[0]}
+			if len(fields) > 1 {
+				key.RPS, err = strconv.ParseFloat(fields[1], 64)
+				if err != nil {
+					return nil, fmt.Errorf("%v:%v: invalid rps: %w", path, line, err)
+				}
+			}
+			keys = append(keys, key)
+		}
+		return keys, scanner.Err()
+	}
+}
+
+// Stats returns usage statistics of all keys.
+func (p *KeyPool) Stats() []KeyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]KeyStats, 0, len(p.keys))
+	for _, key := range p.keys {
+		stats = append(stats, KeyStats{
+			Key:          maskToken(key.Token),
+			Requests:     key.requests,
+			Throttled:    key.throttled,
+			Unauthorized: key.unauthorized,
+			BenchedUntil: key.benchedUntil,
+		})
+	}
+	return stats
+}
+
+func (p *KeyPool) report(token string, statusCode int) {
+	var cooldown time.Duration
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var key *poolKey
+	for _, k := range p.keys {
+		if k.Token == token {
+			key = k
+			break
+		}
+	}
+	if key == nil {
+		return
+	}
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		key.throttled++
+		cooldown = p.throttleCooldown
+	case http.StatusUnauthorized, http.StatusForbidden:
+		key.unauthorized++
+		cooldown = p.unauthorizedCooldown
+	default:
+		return
+	}
+	key.benchedUntil = time.Now().Add(cooldown)
+	p.logger.Errorf("api key %v is benched for %v after %v response", maskToken(token), cooldown, statusCode)
+}
+
+func maskToken(token string) string {
+	if len(token) <= 4 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", len(token)-4) + token[len(token)-4:]
+}
+
+type keyPoolClient struct {
+	pool *KeyPool
+	next ht.Client
+}
+
+func (c *keyPoolClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.next.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	if token, ok := bearerToken(req); ok {
+		c.pool.report(token, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func bearerToken(req *http.Request) (string, bool) {
+	auth := req.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "bearer ") {
+		return "", false
+	}
+	return auth[7:], true
+}
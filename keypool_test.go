@@ -0,0 +1,125 @@
+package tonapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func keyPoolTokens(t *testing.T, pool *KeyPool, n int) []string {
+	tokens := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		auth, err := pool.BearerAuth(context.Background(), StatusOperation, nil)
+		require.NoError(t, err)
+		tokens = append(tokens, auth.Token)
+	}
+	return tokens
+}
+
+func TestKeyPoolRotation(t *testing.T) {
+	pool := NewKeyPool([]APIKey{{Token: "a"}, {Token: "b"}, {Token: ""}, {Token: "c"}})
+	require.Equal(t, []string{"a", "b", "c", "a", "b", "c"}, keyPoolTokens(t, pool, 6))
+	stats := pool.Stats()
+	require.Len(t, stats, 3)
+	for _, s := range stats {
+		require.Equal(t, uint64(2), s.Requests)
+	}
+
+	// keys with an exhausted quota are skipped.
+	pool = NewKeyPool([]APIKey{{Token: "limited", RPS: 1}, {Token: "unlimited"}})
+	require.Equal(t, []string{"limited", "unlimited", "unlimited"}, keyPoolTokens(t, pool, 3))
+}
+
+func TestKeyPoolQuotaExhausted(t *testing.T) {
+	// the burst of a key is its RPS.
+	pool := NewKeyPool([]APIKey{{Token: "a", RPS: 20}, {Token: "b", RPS: 20}})
+	keyPoolTokens(t, pool, 40)
+
+	// every quota is exhausted, the pool waits for a key instead of exceeding its quota.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := pool.BearerAuth(ctx, StatusOperation, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	start := time.Now()
+	auth, err := pool.BearerAuth(context.Background(), StatusOperation, nil)
+	require.NoError(t, err)
+	require.Contains(t, []string{"a", "b"}, auth.Token)
+	require.Greater(t, time.Since(start), 20*time.Millisecond)
+	var requests uint64
+	for _, s := range pool.Stats() {
+		requests += s.Requests
+	}
+	require.Equal(t, uint64(41), requests)
+}
+
+func TestKeyPoolBenching(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Header.Get("Authorization") {
+		case "Bearer throttled":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "Bearer revoked":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "Bearer forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		}
+		w.Write([]byte(`{"error":"x"}`))
+	}))
+	defer srv.Close()
+
+	keys := []APIKey{{Token: "throttled"}, {Token: "revoked"}, {Token: "forbidden"}, {Token: "good"}}
+	pool := NewKeyPool(keys, WithKeyThrottleCooldown(time.Minute), WithKeyUnauthorizedCooldown(time.Hour))
+	client, err := NewClient(srv.URL, pool, WithClient(pool.Wrap(http.DefaultClient)))
+	require.NoError(t, err)
+	for i := 0; i < len(keys); i++ {
+		_, _ = client.Status(context.Background())
+	}
+	stats := pool.Stats()
+	require.Equal(t, uint64(1), stats[0].Throttled)
+	require.WithinDuration(t, time.Now().Add(time.Minute), stats[0].BenchedUntil, time.Second)
+	require.Equal(t, uint64(1), stats[1].Unauthorized)
+	require.Equal(t, uint64(1), stats[2].Unauthorized)
+	require.WithinDuration(t, time.Now().Add(time.Hour), stats[2].BenchedUntil, time.Second)
+	require.True(t, stats[3].BenchedUntil.IsZero())
+	require.Equal(t, "*****tled", stats[0].Key)
+
+	// only the good key is left in rotation.
+	require.Equal(t, []string{"good", "good"}, keyPoolTokens(t, pool, 2))
+
+	// statistics and bench state survive a reload, even if the quota of a key changes.
+	pool.Reload([]APIKey{{Token: "throttled", RPS: 5}, {Token: "revoked"}, {Token: "new"}})
+	stats = pool.Stats()
+	require.Len(t, stats, 3)
+	require.Equal(t, uint64(1), stats[0].Throttled)
+	require.Equal(t, uint64(1), stats[0].Requests)
+	require.False(t, stats[0].BenchedUntil.IsZero())
+	require.Equal(t, uint64(1), stats[1].Unauthorized)
+	require.Zero(t, stats[2].Requests)
+	require.Equal(t, []string{"new", "new"}, keyPoolTokens(t, pool, 2))
+
+	pool.Reload([]APIKey{{Token: "throttled"}})
+	_, err = pool.BearerAuth(context.Background(), StatusOperation, nil)
+	require.ErrorIs(t, err, ErrNoKeysAvailable)
+}
+
+func TestKeysFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# indexer keys\n\nAE7YQ  10\n  AFXRT\nAGZZZ 0.5 ignored\n"), 0o600))
+	keys, err := KeysFromFile(path)(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []APIKey{{Token: "AE7YQ", RPS: 10}, {Token: "AFXRT"}, {Token: "AGZZZ", RPS: 0.5}}, keys)
+
+	require.NoError(t, os.WriteFile(path, []byte("AE7YQ 10\nAFXRT ten\n"), 0o600))
+	_, err = KeysFromFile(path)(context.Background())
+	require.ErrorContains(t, err, "keys.txt:2: invalid rps")
+
+	_, err = KeysFromFile(filepath.Join(t.TempDir(), "missing.txt"))(context.Background())
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,278 @@
+package tonapi
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/tonkeeper/tongo/ton"
+)
+
+// accountEventsPageSize is the maximum page size of GetAccountEvents.
+const accountEventsPageSize = 100
+
+// LedgerDirection tells whether a LedgerRow moves an asset into or out of the account.
+type LedgerDirection string
+
+const (
+	// LedgerCredit is an asset received by the account.
+	LedgerCredit LedgerDirection = "credit"
+	// LedgerDebit is an asset sent by the account.
+	LedgerDebit LedgerDirection = "debit"
+	// LedgerFee is a row carrying only the fee of an event without asset movements.
+	LedgerFee LedgerDirection = "fee"
+)
+
+// LedgerRow is a single movement of an asset in or out of the account.
+type LedgerRow struct {
+	EventID    string          `json:"event_id"`
+	Lt         int64           `json:"lt"`
+	Time       time.Time       `json:"time"`
+	Action     int             `json:"action"`
+	ActionType ActionType      `json:"action_type"`
+	Status     ActionStatus    `json:"status"`
+	Direction  LedgerDirection `json:"direction"`
+	// Asset is "TON", a jetton master address, an NFT item address or "extra:<id>" for extra currencies.
+	Asset  string `json:"asset"`
+	Symbol string `json:"symbol"`
+	// Amount is always positive and in the smallest units of the asset.
+	Amount       *big.Int `json:"amount"`
+	Decimals     int      `json:"decimals"`
+	Counterparty string   `json:"counterparty,omitempty"`
+	Comment      string   `json:"comment,omitempty"`
+	// FeeShare is the part of the implicit fee of the event in nanotons allocated to the row.
+	// The fee is taken from AccountEvent.Extra and split evenly between the rows of the event.
+	// It is negative when the account was refunded.
+	FeeShare   int64 `json:"fee_share"`
+	InProgress bool  `json:"in_progress,omitempty"`
+}
+
+// LedgerReconciliation compares the TON movements of a Ledger with GetAccountDiff.
+type LedgerReconciliation struct {
+	// BalanceChange is the change of the TON balance reported by GetAccountDiff.
+	BalanceChange int64
+	// LedgerChange is TON credits minus TON debits of successful rows minus fee shares of all rows.
+	LedgerChange int64
+	// Difference is BalanceChange minus LedgerChange. It is not zero when some movements
+	// are not represented by actions, for example when events were filtered with SubjectOnly or Initiator.
+	Difference int64
+}
+
+// Ledger is the activity of an account in a date range flattened into rows.
+type Ledger struct {
+	Account ton.AccountID
+	From    time.Time
+	To      time.Time
+	// Rows are ordered by event lt and action index.
+	Rows           []LedgerRow
+	Reconciliation LedgerReconciliation
+}
+
+// LedgerOption configures AccountLedger.
+type LedgerOption func(o *ledgerOptions)
+
+type ledgerOptions struct {
+	subjectOnly bool
+	initiator   bool
+}
+
+// WithLedgerSubjectOnly drops actions where the account is not the real subject,
+// for example when it only acts as a jetton wallet of a transfer.
+func WithLedgerSubjectOnly() LedgerOption {
+	return func(o *ledgerOptions) {
+		o.subjectOnly = true
+	}
+}
+
+// WithLedgerInitiator keeps only events initiated by the account.
+func WithLedgerInitiator() LedgerOption {
+	return func(o *ledgerOptions) {
+		o.initiator = true
+	}
+}
+
+// AccountLedger walks GetAccountEvents of the account between from and to
+// and flattens TON, jetton, NFT and extra currency movements into debit and credit rows.
+// A swap is a debit of the sold asset and a credit of the bought one.
+// The TON movements are reconciled with GetAccountDiff for the same range.
+func (c *Client) AccountLedger(ctx context.Context, account ton.AccountID, from, to time.Time, opts ...LedgerOption) (*Ledger, error) {
+	var options ledgerOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	ledger := &Ledger{Account: account, From: from, To: to}
+
+	var events []AccountEvent
+	params := GetAccountEventsParams{
+		AccountID: account.ToRaw(),
+		Limit:     accountEventsPageSize,
+		StartDate: NewOptInt64(from.Unix()),
+		EndDate:   NewOptInt64(to.Unix()),
+		SortOrder: NewOptGetAccountEventsSortOrder(GetAccountEventsSortOrderAsc),
+	}
+	if options.subjectOnly {
+		params.SubjectOnly = NewOptBool(true)
+	}
+	if options.initiator {
+		params.Initiator = NewOptBool(true)
+	}
+	for {
+		page, err := c.GetAccountEvents(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page.Events...)
+		// pages may be short or even empty because of filtered out events,
+		// the range ends when NextFrom is zero or doesn't move.
+		if page.NextFrom == 0 || page.NextFrom == params.AfterLt.Or(0) {
+			break
+		}
+		params.AfterLt = NewOptInt64(page.NextFrom)
+	}
+	for _, event := range events {
+		ledger.Rows = append(ledger.Rows, accountEventRows(account, event)...)
+	}
+
+	diff, err := c.GetAccountDiff(ctx, GetAccountDiffParams{
+		AccountID: account.ToRaw(),
+		StartDate: from.Unix(),
+		EndDate:   to.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ledger.Reconciliation.BalanceChange = diff.BalanceChange
+	for _, row := range ledger.Rows {
+		ledger.Reconciliation.LedgerChange -= row.FeeShare
+		if row.Status != ActionStatusOk || row.Asset != "TON" {
+			continue
+		}
+		switch row.Direction {
+		case LedgerCredit:
+			ledger.Reconciliation.LedgerChange += row.Amount.Int64()
+		case LedgerDebit:
+			ledger.Reconciliation.LedgerChange -= row.Amount.Int64()
+		}
+	}
+	ledger.Reconciliation.Difference = ledger.Reconciliation.BalanceChange - ledger.Reconciliation.LedgerChange
+	return ledger, nil
+}
+
+// accountEventRows flattens the actions of an event into rows of the account.
+func accountEventRows(account ton.AccountID, event AccountEvent) []LedgerRow {
+	var rows []LedgerRow
+	base := LedgerRow{
+		EventID:    event.EventID,
+		Lt:         event.Lt,
+		Time:       time.Unix(event.Timestamp, 0).UTC(),
+		InProgress: event.InProgress,
+	}
+	for i, action := range event.Actions {
+		for _, asset := range actionAssets(action) {
+			row := base
+			row.Action = i
+			row.ActionType = action.Type
+			row.Status = action.Status
+			row.Symbol = asset.Symbol
+			row.Amount = new(big.Int).Abs(asset.Amount)
+			row.Decimals = asset.Decimals
+			row.Comment = asset.Comment
+			switch {
+			case asset.Token == gramToken:
+				row.Asset = "TON"
+			case asset.Nft != "":
+				row.Asset = asset.Nft
+			case asset.Token == "":
+				row.Asset = "extra:" + strconv.Itoa(int(asset.CurrencyID))
+			default:
+				row.Asset = asset.Token
+			}
+			sender, recipient := sameAccount(asset.Sender, account), sameAccount(asset.Recipient, account)
+			switch {
+			case sender && !recipient:
+				row.Direction, row.Counterparty = LedgerDebit, asset.Recipient
+			case recipient && !sender:
+				row.Direction, row.Counterparty = LedgerCredit, asset.Sender
+			default:
+				continue
+			}
+			rows = append(rows, row)
+		}
+	}
+	fee := -event.Extra
+	if fee == 0 {
+		return rows
+	}
+	if len(rows) == 0 {
+		row := base
+		row.Direction = LedgerFee
+		row.Status = ActionStatusOk
+		row.Asset, row.Symbol, row.Amount, row.Decimals = "TON", "TON", new(big.Int), tonDecimals
+		row.FeeShare = fee
+		return []LedgerRow{row}
+	}
+	share := fee / int64(len(rows))
+	for i := range rows {
+		rows[i].FeeShare = share
+	}
+	rows[0].FeeShare += fee - share*int64(len(rows))
+	return rows
+}
+
+func sameAccount(address string, account ton.AccountID) bool {
+	id, err := ton.ParseAccountID(address)
+	return err == nil && id == account
+}
+
+var ledgerCSVHeader = []string{
+	"event_id", "lt", "time", "action", "action_type", "status", "direction",
+	"asset", "symbol", "amount", "decimals", "counterparty", "comment", "fee_share", "in_progress",
+}
+
+// WriteCSV writes the rows as CSV with a header line.
+func (l *Ledger) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ledgerCSVHeader); err != nil {
+		return err
+	}
+	for _, row := range l.Rows {
+		record := []string{
+			row.EventID,
+			strconv.FormatInt(row.Lt, 10),
+			row.Time.Format(time.RFC3339),
+			strconv.Itoa(row.Action),
+			string(row.ActionType),
+			string(row.Status),
+			string(row.Direction),
+			row.Asset,
+			row.Symbol,
+			row.Amount.String(),
+			strconv.Itoa(row.Decimals),
+			row.Counterparty,
+			row.Comment,
+			strconv.FormatInt(row.FeeShare, 10),
+			fmt.Sprintf("%v", row.InProgress),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSONL writes one JSON object per row, suitable for loading into columnar stores.
+func (l *Ledger) WriteJSONL(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, row := range l.Rows {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
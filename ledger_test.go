@@ -0,0 +1,164 @@
+package tonapi
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func TestAccountLedger(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	friend := "0:2222222222222222222222222222222222222222222222222222222222222222"
+	usdt := JettonPreview{
+		Address:      "0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe",
+		Symbol:       "USD₮",
+		Decimals:     6,
+		Verification: JettonVerificationTypeWhitelist,
+	}
+	events := AccountEvents{Events: []AccountEvent{
+		{
+			EventID:   "a",
+			Lt:        1,
+			Timestamp: 1_700_000_000,
+			Account:   AccountAddress{Address: account.ToRaw()},
+			Actions: []Action{{
+				Type:   ActionTypeTonTransfer,
+				Status: ActionStatusOk,
+				TonTransfer: NewOptTonTransferAction(TonTransferAction{
+					Sender:    AccountAddress{Address: friend},
+					Recipient: AccountAddress{Address: account.ToRaw()},
+					Amount:    5_000_000_000,
+					Comment:   NewOptString("salary"),
+				}),
+			}},
+		},
+		{
+			EventID:   "b",
+			Lt:        2,
+			Timestamp: 1_700_000_100,
+			Account:   AccountAddress{Address: account.ToRaw()},
+			Extra:     -7,
+			Actions: []Action{
+				{
+					Type:   ActionTypeTonTransfer,
+					Status: ActionStatusOk,
+					TonTransfer: NewOptTonTransferAction(TonTransferAction{
+						Sender:    AccountAddress{Address: account.ToRaw()},
+						Recipient: AccountAddress{Address: friend},
+						Amount:    1_000_000_000,
+					}),
+				},
+				{
+					Type:   ActionTypeJettonTransfer,
+					Status: ActionStatusOk,
+					JettonTransfer: NewOptJettonTransferAction(JettonTransferAction{
+						Sender:    NewOptAccountAddress(AccountAddress{Address: account.ToRaw()}),
+						Recipient: NewOptAccountAddress(AccountAddress{Address: friend}),
+						Amount:    "2500000",
+						Jetton:    usdt,
+					}),
+				},
+			},
+		},
+		{
+			EventID:   "c",
+			Lt:        3,
+			Timestamp: 1_700_000_200,
+			Account:   AccountAddress{Address: account.ToRaw()},
+			Extra:     -3,
+			Actions:   []Action{},
+		},
+		{
+			EventID:   "d",
+			Lt:        4,
+			Timestamp: 1_700_000_300,
+			Account:   AccountAddress{Address: account.ToRaw()},
+			Actions: []Action{{
+				Type:   ActionTypeJettonSwap,
+				Status: ActionStatusOk,
+				JettonSwap: NewOptJettonSwapAction(JettonSwapAction{
+					Dex:             "stonfi",
+					AmountOut:       "1000000",
+					GramIn:          NewOptInt64(300_000_000),
+					UserWallet:      AccountAddress{Address: account.ToRaw()},
+					Router:          AccountAddress{Address: friend},
+					JettonMasterOut: NewOptJettonPreview(usdt),
+				}),
+			}},
+		},
+	}}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var data []byte
+		switch r.URL.Path {
+		case "/v2/accounts/" + account.ToRaw() + "/events":
+			require.Equal(t, "true", r.URL.Query().Get("subject_only"))
+			require.Equal(t, "asc", r.URL.Query().Get("sort_order"))
+			// pages are short and the second one has no events left after filtering, only a zero next_from ends them.
+			var page AccountEvents
+			switch r.URL.Query().Get("after_lt") {
+			case "":
+				page = AccountEvents{Events: events.Events[:2], NextFrom: 2}
+			case "2":
+				page = AccountEvents{Events: []AccountEvent{}, NextFrom: 3}
+			case "3":
+				page = AccountEvents{Events: events.Events[2:]}
+			}
+			data, _ = page.MarshalJSON()
+		case "/v2/accounts/" + account.ToRaw() + "/diff":
+			data, _ = (&GetAccountDiffOK{BalanceChange: 3_699_999_990}).MarshalJSON()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	ledger, err := client.AccountLedger(context.Background(), account, time.Unix(1_699_999_000, 0), time.Unix(1_700_001_000, 0), WithLedgerSubjectOnly())
+	require.NoError(t, err)
+
+	require.Len(t, ledger.Rows, 6)
+	require.Equal(t, LedgerCredit, ledger.Rows[0].Direction)
+	require.Equal(t, friend, ledger.Rows[0].Counterparty)
+	require.Equal(t, "salary", ledger.Rows[0].Comment)
+	require.Equal(t, LedgerDebit, ledger.Rows[1].Direction)
+	require.Equal(t, int64(4), ledger.Rows[1].FeeShare)
+	require.Equal(t, usdt.Address, ledger.Rows[2].Asset)
+	require.Equal(t, int64(3), ledger.Rows[2].FeeShare)
+	require.Equal(t, LedgerFee, ledger.Rows[3].Direction)
+	require.Equal(t, int64(3), ledger.Rows[3].FeeShare)
+	// a swap sells one asset and buys another.
+	require.Equal(t, ActionTypeJettonSwap, ledger.Rows[4].ActionType)
+	require.Equal(t, LedgerDebit, ledger.Rows[4].Direction)
+	require.Equal(t, "TON", ledger.Rows[4].Asset)
+	require.Equal(t, int64(300_000_000), ledger.Rows[4].Amount.Int64())
+	require.Equal(t, LedgerCredit, ledger.Rows[5].Direction)
+	require.Equal(t, usdt.Address, ledger.Rows[5].Asset)
+	require.Equal(t, int64(1_000_000), ledger.Rows[5].Amount.Int64())
+	require.Equal(t, friend, ledger.Rows[5].Counterparty)
+	require.Equal(t, LedgerReconciliation{
+		BalanceChange: 3_699_999_990,
+		LedgerChange:  3_699_999_990,
+	}, ledger.Reconciliation)
+
+	var buf bytes.Buffer
+	require.NoError(t, ledger.WriteCSV(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 7)
+	require.Equal(t, "b,2,2023-11-14T22:15:00Z,1,JettonTransfer,ok,debit,"+usdt.Address+",USD₮,2500000,6,"+friend+",,3,false", lines[3])
+
+	buf.Reset()
+	require.NoError(t, ledger.WriteJSONL(&buf))
+	require.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 6)
+}
@@ -1,9 +1,58 @@
 package tonapi
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Logger interface {
 	Errorf(format string, args ...interface{})
 }
 
+// StructuredLogger is a leveled logger with key-value fields.
+// Its methods have the same signatures as the methods of *slog.Logger, so a *slog.Logger can be used directly:
+//
+//	streaming := tonapi.NewStreamingAPI(tonapi.WithStreamingStructuredLogger(slog.Default()))
+type StructuredLogger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
 type noopLogger struct{}
 
 func (l noopLogger) Errorf(format string, args ...interface{}) {}
+
+func (l noopLogger) Debug(msg string, args ...any) {}
+func (l noopLogger) Info(msg string, args ...any)  {}
+func (l noopLogger) Warn(msg string, args ...any)  {}
+func (l noopLogger) Error(msg string, args ...any) {}
+
+// errorfLogger adapts a Logger to StructuredLogger.
+// Logger has no levels, so only errors are passed through.
+type errorfLogger struct {
+	logger Logger
+}
+
+func (l errorfLogger) Debug(msg string, args ...any) {}
+func (l errorfLogger) Info(msg string, args ...any)  {}
+func (l errorfLogger) Warn(msg string, args ...any)  {}
+
+func (l errorfLogger) Error(msg string, args ...any) {
+	l.logger.Errorf("%s", formatLogMessage(msg, args))
+}
+
+// formatLogMessage renders a message with key-value fields as "msg key1=value1 key2=value2".
+func formatLogMessage(msg string, args []any) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fmt.Fprintf(&b, " %v", args[i])
+			break
+		}
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	return b.String()
+}
@@ -0,0 +1,387 @@
+package tonapi
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/tonkeeper/tongo/boc"
+	"github.com/tonkeeper/tongo/tlb"
+	"github.com/tonkeeper/tongo/ton"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	mempoolConcurrency  = 8
+	mempoolPollInterval = 2 * time.Second
+	mempoolTTL          = 3 * time.Minute
+	mempoolMaxPending   = 100
+	// mempoolQueueSize is the number of mempool events buffered while the watcher is busy.
+	mempoolQueueSize = 1024
+)
+
+// MempoolStatus is the state of a message seen in the mempool.
+type MempoolStatus string
+
+const (
+	// MempoolStatusPending means the message is waiting to be included in a block.
+	MempoolStatusPending MempoolStatus = "pending"
+	// MempoolStatusConfirmed means the message was included and every transaction of its trace succeeded.
+	MempoolStatusConfirmed MempoolStatus = "confirmed"
+	// MempoolStatusFailed means the message was included but a transaction of its trace failed.
+	MempoolStatusFailed MempoolStatus = "failed"
+	// MempoolStatusExpired means the message was not included before the watcher's TTL.
+	MempoolStatusExpired MempoolStatus = "expired"
+)
+
+// PendingMessage is an external message tracked by MempoolWatcher.
+type PendingMessage struct {
+	// Hash is the normalized hash of the message in hex, see NormalizedMessageHash.
+	Hash        string
+	Message     tlb.Message
+	Destination ton.AccountID
+	// InvolvedAccounts are the accounts of the emulated trace as reported by the mempool stream.
+	InvolvedAccounts []ton.AccountID
+	FirstSeen        time.Time
+	Status           MempoolStatus
+	// Emulation is the event predicted by EmulateMessageToEvent.
+	// It is nil if emulation is disabled or failed, in which case EmulationError holds the reason.
+	Emulation      *Event
+	EmulationError error
+	// Trace is the finished trace of the message, nil until the message is confirmed or failed.
+	Trace *Trace
+	// IncludedAt is the time of the transaction that processed the message.
+	IncludedAt time.Time
+}
+
+// TimeToInclusion returns the time between the message showing up in the mempool
+// and the transaction processing it, zero if the message is not included.
+func (m *PendingMessage) TimeToInclusion() time.Duration {
+	if m.IncludedAt.IsZero() || m.IncludedAt.Before(m.FirstSeen) {
+		return 0
+	}
+	return m.IncludedAt.Sub(m.FirstSeen)
+}
+
+// EmulationMatched reports whether the emulation predicted the outcome of an included message:
+// every emulated action succeeded if and only if every transaction of the trace succeeded.
+// It returns false when there is no emulation or the message is not included yet.
+func (m *PendingMessage) EmulationMatched() bool {
+	if m.Emulation == nil || m.Trace == nil {
+		return false
+	}
+	predicted := true
+	for _, action := range m.Emulation.Actions {
+		if action.Status != ActionStatusOk {
+			predicted = false
+		}
+	}
+	return predicted == (m.Status == MempoolStatusConfirmed)
+}
+
+// MempoolWatcherFunc is called by MempoolWatcher every time a message changes its status.
+type MempoolWatcherFunc func(ctx context.Context, msg PendingMessage)
+
+// MempoolWatcher follows external messages from the mempool to their traces.
+//
+// Every message received with SubscribeToMempool is decoded, optionally emulated with EmulateMessageToEvent
+// and reported as pending. The watcher then polls GetTrace with the normalized hash of the message
+// and reports it as confirmed or failed once its trace is finished, or as expired if it is not included in time.
+// Each message is reported once per status, messages sent again with the same normalized hash are ignored.
+type MempoolWatcher struct {
+	client     *Client
+	streaming  *StreamingAPI
+	accounts   []string
+	handler    MempoolWatcherFunc
+	emulate    bool
+	ttl        time.Duration
+	poll       time.Duration
+	maxPending int
+	logger     StructuredLogger
+
+	mu      sync.Mutex
+	pending map[string]*PendingMessage
+	// done remembers finished messages for one TTL to ignore their repeated broadcasts.
+	done map[string]time.Time
+}
+
+// MempoolWatcherOption configures a MempoolWatcher.
+type MempoolWatcherOption func(w *MempoolWatcher)
+
+// WithMempoolAccounts configures a watcher to receive only messages involving the given accounts.
+// By default, all messages of the mempool are received, but only WithMempoolMaxPending of them are tracked at a time.
+func WithMempoolAccounts(accounts ...ton.AccountID) MempoolWatcherOption {
+	return func(w *MempoolWatcher) {
+		for _, account := range accounts {
+			w.accounts = append(w.accounts, account.ToRaw())
+		}
+	}
+}
+
+// WithMempoolEmulation configures a watcher to emulate every message with EmulateMessageToEvent
+// before reporting it as pending.
+func WithMempoolEmulation() MempoolWatcherOption {
+	return func(w *MempoolWatcher) {
+		w.emulate = true
+	}
+}
+
+// WithMempoolTTL configures how long a message may stay pending before it is reported as expired.
+// The default is 3 minutes, longer than the validity of messages created by the common wallets.
+func WithMempoolTTL(ttl time.Duration) MempoolWatcherOption {
+	return func(w *MempoolWatcher) {
+		w.ttl = ttl
+	}
+}
+
+// WithMempoolPollInterval configures how often pending messages are looked up with GetTrace.
+// The default is 2 seconds.
+func WithMempoolPollInterval(interval time.Duration) MempoolWatcherOption {
+	return func(w *MempoolWatcher) {
+		w.poll = interval
+	}
+}
+
+// WithMempoolMaxPending limits the number of messages tracked at a time, every one of them is looked up
+// with GetTrace on each poll. Messages received while the limit is reached are dropped. The default is 100.
+func WithMempoolMaxPending(n int) MempoolWatcherOption {
+	return func(w *MempoolWatcher) {
+		w.maxPending = n
+	}
+}
+
+// WithMempoolLogger configures a watcher to report decoding and API errors to the given logger.
+func WithMempoolLogger(logger StructuredLogger) MempoolWatcherOption {
+	return func(w *MempoolWatcher) {
+		w.logger = logger
+	}
+}
+
+// NewMempoolWatcher returns a watcher reporting status changes of mempool messages to the handler.
+func NewMempoolWatcher(client *Client, streaming *StreamingAPI, handler MempoolWatcherFunc, opts ...MempoolWatcherOption) *MempoolWatcher {
+	w := &MempoolWatcher{
+		client:     client,
+		streaming:  streaming,
+		handler:    handler,
+		ttl:        mempoolTTL,
+		poll:       mempoolPollInterval,
+		maxPending: mempoolMaxPending,
+		logger:     noopLogger{},
+		pending:    map[string]*PendingMessage{},
+		done:       map[string]time.Time{},
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// Pending returns the messages waiting to be included.
+func (w *MempoolWatcher) Pending() []PendingMessage {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	res := make([]PendingMessage, 0, len(w.pending))
+	for _, m := range w.pending {
+		res = append(res, *m)
+	}
+	return res
+}
+
+// Run watches the mempool until the context is canceled.
+// The handler is called from a single goroutine.
+func (w *MempoolWatcher) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan MempoolEventData, mempoolQueueSize)
+	go func() {
+		for ctx.Err() == nil {
+			err := w.streaming.SubscribeToMempool(ctx, w.accounts, func(data MempoolEventData) {
+				select {
+				case events <- data:
+				default:
+					w.logger.Error("mempool watcher is too slow, message dropped")
+				}
+			})
+			if err != nil && ctx.Err() == nil {
+				w.logger.Error("mempool streaming connection failed", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.poll):
+			}
+		}
+	}()
+	ticker := time.NewTicker(w.poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case data := <-events:
+			if err := w.observe(ctx, data); err != nil {
+				w.logger.Error("mempool message skipped", "error", err)
+			}
+		case <-ticker.C:
+			w.track(ctx)
+		}
+	}
+}
+
+// observe starts tracking a message from the mempool.
+func (w *MempoolWatcher) observe(ctx context.Context, data MempoolEventData) error {
+	cells, err := boc.DeserializeBoc(data.BOC)
+	if err != nil {
+		return errors.Wrap(err, "decode boc")
+	}
+	if len(cells) != 1 {
+		return errors.Errorf("boc has %v roots", len(cells))
+	}
+	var msg tlb.Message
+	if err := tlb.Unmarshal(cells[0], &msg); err != nil {
+		return errors.Wrap(err, "decode message")
+	}
+	hash, err := NormalizedMessageHash(msg)
+	if err != nil {
+		return err
+	}
+	destination, err := ton.AccountIDFromTlb(msg.Info.ExtInMsgInfo.Dest)
+	if err != nil || destination == nil {
+		return errors.Errorf("message %v has no destination", hash.Hex())
+	}
+	now := time.Now()
+	w.mu.Lock()
+	_, tracked := w.pending[hash.Hex()]
+	_, finished := w.done[hash.Hex()]
+	full := len(w.pending) >= w.maxPending
+	w.mu.Unlock()
+	if tracked || finished {
+		return nil
+	}
+	if full {
+		w.logger.Warn("mempool watcher is full, message dropped", "hash", hash.Hex())
+		return nil
+	}
+
+	m := &PendingMessage{
+		Hash:             hash.Hex(),
+		Message:          msg,
+		Destination:      *destination,
+		InvolvedAccounts: data.InvolvedAccounts,
+		FirstSeen:        now,
+		Status:           MempoolStatusPending,
+	}
+	if w.emulate {
+		m.Emulation, m.EmulationError = w.client.EmulateMessageToEvent(ctx, &EmulateMessageToEventReq{
+			Boc: base64.StdEncoding.EncodeToString(data.BOC),
+		}, EmulateMessageToEventParams{})
+	}
+	w.mu.Lock()
+	w.pending[m.Hash] = m
+	w.mu.Unlock()
+	w.handler(ctx, *m)
+	return nil
+}
+
+// track looks up the traces of pending messages and reports the ones that are finished or expired.
+func (w *MempoolWatcher) track(ctx context.Context) {
+	w.mu.Lock()
+	messages := make([]*PendingMessage, 0, len(w.pending))
+	for _, m := range w.pending {
+		messages = append(messages, m)
+	}
+	for hash, at := range w.done {
+		if time.Since(at) > w.ttl {
+			delete(w.done, hash)
+		}
+	}
+	w.mu.Unlock()
+
+	updates := make([]*PendingMessage, len(messages))
+	g := errgroup.Group{}
+	g.SetLimit(mempoolConcurrency)
+	for i, m := range messages {
+		g.Go(func() error {
+			updates[i] = w.lookup(ctx, *m)
+			return nil
+		})
+	}
+	g.Wait()
+	for _, m := range updates {
+		if m == nil {
+			continue
+		}
+		w.mu.Lock()
+		delete(w.pending, m.Hash)
+		w.done[m.Hash] = time.Now()
+		w.mu.Unlock()
+		w.handler(ctx, *m)
+	}
+}
+
+// lookup returns the message with a final status, or nil if the message is still pending.
+func (w *MempoolWatcher) lookup(ctx context.Context, m PendingMessage) *PendingMessage {
+	trace, err := w.client.GetTrace(ctx, GetTraceParams{TraceID: m.Hash})
+	switch {
+	case err == nil:
+		if TraceInProgress(trace) {
+			return nil
+		}
+		m.Trace = trace
+		m.IncludedAt = time.Unix(trace.Transaction.Utime, 0)
+		m.Status = MempoolStatusConfirmed
+		if !traceSucceeded(trace) {
+			m.Status = MempoolStatusFailed
+		}
+		return &m
+	case ctx.Err() != nil:
+		return nil
+	case !isNotFound(err):
+		w.logger.Error("mempool trace lookup failed", "hash", m.Hash, "error", err)
+	}
+	// a message that can't be looked up expires like a message that is not included.
+	if time.Since(m.FirstSeen) < w.ttl {
+		return nil
+	}
+	m.Status = MempoolStatusExpired
+	return &m
+}
+
+// traceSucceeded reports whether every transaction of the trace succeeded.
+func traceSucceeded(t *Trace) bool {
+	if !t.Transaction.Success || t.Transaction.Aborted {
+		return false
+	}
+	for i := range t.Children {
+		if !traceSucceeded(&t.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizedMessageHash returns the normalized hash of an external inbound message as defined by TEP-467.
+// The hash ignores the fields a relay can change without changing the meaning of the message:
+// the source address and import fee are cleared, the state init is dropped and the body is stored in a reference.
+// It identifies the message in GetTrace and GetEvent.
+func NormalizedMessageHash(msg tlb.Message) (ton.Bits256, error) {
+	if msg.Info.SumType != "ExtInMsgInfo" {
+		return ton.Bits256{}, errors.Errorf("expected an external inbound message, got %v", msg.Info.SumType)
+	}
+	info := *msg.Info.ExtInMsgInfo
+	info.Src = tlb.MsgAddress{SumType: "AddrNone"}
+	info.ImportFee = tlb.VarUInteger16{}
+	msg.Info.ExtInMsgInfo = &info
+	msg.Init = tlb.Maybe[tlb.EitherRef[tlb.StateInit]]{}
+	msg.Body.IsRight = true
+	cell := boc.NewCell()
+	if err := tlb.Marshal(cell, msg); err != nil {
+		return ton.Bits256{}, errors.Wrap(err, "encode normalized message")
+	}
+	hash, err := cell.Hash256()
+	if err != nil {
+		return ton.Bits256{}, err
+	}
+	return hash, nil
+}
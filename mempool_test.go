@@ -0,0 +1,198 @@
+package tonapi
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/boc"
+	"github.com/tonkeeper/tongo/tlb"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func mempoolTestMessage(t *testing.T, destination ton.AccountID, text string) tlb.Message {
+	body := boc.NewCell()
+	require.NoError(t, body.WriteUint(0, 32))
+	require.NoError(t, body.WriteBytes([]byte(text)))
+	msg, err := ton.CreateExternalMessage(destination, body, nil, tlb.VarUInteger16{})
+	require.NoError(t, err)
+	return msg
+}
+
+func mempoolTestBoc(t *testing.T, msg tlb.Message) []byte {
+	cell := boc.NewCell()
+	require.NoError(t, tlb.Marshal(cell, msg))
+	data, err := cell.ToBoc()
+	require.NoError(t, err)
+	return data
+}
+
+func TestNormalizedMessageHash(t *testing.T) {
+	destination := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	msg := mempoolTestMessage(t, destination, "hello")
+	hash, err := NormalizedMessageHash(msg)
+	require.NoError(t, err)
+
+	// a relay may set the import fee, attach a state init and inline the body.
+	changed := mempoolTestMessage(t, destination, "hello")
+	changed.Info.ExtInMsgInfo.ImportFee = tlb.VarUInteger16(*big.NewInt(1000))
+	changed.Init.Exists = true
+	changed.Init.Value.IsRight = true
+	changed.Body.IsRight = false
+	changedHash, err := NormalizedMessageHash(changed)
+	require.NoError(t, err)
+	require.Equal(t, hash, changedHash)
+
+	// the normalized message laid out by hand as TEP-467 defines it.
+	body := boc.NewCell()
+	require.NoError(t, body.WriteUint(0, 32))
+	require.NoError(t, body.WriteBytes([]byte("hello")))
+	normalized := boc.NewCell()
+	require.NoError(t, normalized.WriteUint(0b10, 2))  // ext_in_msg_info$10
+	require.NoError(t, normalized.WriteUint(0b00, 2))  // src:addr_none$00
+	require.NoError(t, normalized.WriteUint(0b100, 3)) // dest:addr_std$10 anycast:nothing$0
+	require.NoError(t, normalized.WriteInt(0, 8))
+	require.NoError(t, normalized.WriteBytes(destination.Address[:]))
+	require.NoError(t, normalized.WriteUint(0, 4)) // import_fee:(VarUInteger 16) 0
+	require.NoError(t, normalized.WriteBit(false)) // init:nothing$0
+	require.NoError(t, normalized.WriteBit(true))  // body:right$1
+	require.NoError(t, normalized.AddRef(body))
+	expected, err := normalized.Hash256()
+	require.NoError(t, err)
+	require.Equal(t, ton.Bits256(expected), changedHash)
+
+	otherHash, err := NormalizedMessageHash(mempoolTestMessage(t, destination, "bye"))
+	require.NoError(t, err)
+	require.NotEqual(t, hash, otherHash)
+
+	internal := tlb.Message{Info: tlb.CommonMsgInfo{SumType: "IntMsgInfo"}}
+	_, err = NormalizedMessageHash(internal)
+	require.Error(t, err)
+}
+
+func TestMempoolWatcher(t *testing.T) {
+	destination := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	confirmed := mempoolTestMessage(t, destination, "confirmed")
+	failed := mempoolTestMessage(t, destination, "failed")
+	expired := mempoolTestMessage(t, destination, "expired")
+	hash := func(msg tlb.Message) string {
+		h, err := NormalizedMessageHash(msg)
+		require.NoError(t, err)
+		return h.Hex()
+	}
+	included := time.Now().Add(5 * time.Second)
+	trace := func(success bool) Trace {
+		child := consumerTestTransaction(2)
+		child.Success = success
+		root := consumerTestTransaction(1)
+		root.Success = true
+		root.Utime = included.Unix()
+		return Trace{Transaction: root, Children: []Trace{{Transaction: child}}}
+	}
+	traces := map[string]Trace{hash(confirmed): trace(true), hash(failed): trace(false)}
+	emulations := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data []byte
+		switch {
+		case r.URL.Path == "/v2/events/emulate":
+			emulations++
+			data, _ = (&Event{EventID: "e", Actions: []Action{{Type: ActionTypeTonTransfer, Status: ActionStatusOk}}}).MarshalJSON()
+		case strings.HasPrefix(r.URL.Path, "/v2/traces/"):
+			trace, ok := traces[strings.TrimPrefix(r.URL.Path, "/v2/traces/")]
+			if !ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"not found"}`))
+				return
+			}
+			data, _ = trace.MarshalJSON()
+		default:
+			t.Errorf("unexpected request %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var updates []PendingMessage
+	watcher := NewMempoolWatcher(client, nil, func(ctx context.Context, msg PendingMessage) {
+		updates = append(updates, msg)
+	}, WithMempoolEmulation(), WithMempoolTTL(time.Nanosecond))
+	for _, msg := range []tlb.Message{confirmed, failed, expired, confirmed} {
+		require.NoError(t, watcher.observe(ctx, MempoolEventData{BOC: mempoolTestBoc(t, msg)}))
+	}
+	require.Error(t, watcher.observe(ctx, MempoolEventData{BOC: []byte("garbage")}))
+	require.Len(t, updates, 3)
+	require.Equal(t, 3, emulations)
+	require.Len(t, watcher.Pending(), 3)
+	for _, m := range updates {
+		require.Equal(t, MempoolStatusPending, m.Status)
+		require.Equal(t, destination, m.Destination)
+		require.NotNil(t, m.Emulation)
+	}
+
+	updates = nil
+	watcher.track(ctx)
+	require.Empty(t, watcher.Pending())
+	slices.SortFunc(updates, func(a, b PendingMessage) int { return strings.Compare(string(a.Status), string(b.Status)) })
+	require.Len(t, updates, 3)
+	require.Equal(t, MempoolStatusConfirmed, updates[0].Status)
+	require.Equal(t, hash(confirmed), updates[0].Hash)
+	require.True(t, updates[0].EmulationMatched())
+	require.Greater(t, updates[0].TimeToInclusion(), time.Second)
+	require.Equal(t, MempoolStatusExpired, updates[1].Status)
+	require.Equal(t, hash(expired), updates[1].Hash)
+	require.Zero(t, updates[1].TimeToInclusion())
+	require.Equal(t, MempoolStatusFailed, updates[2].Status)
+	require.False(t, updates[2].EmulationMatched())
+
+	// finished messages broadcast again are ignored.
+	updates = nil
+	require.NoError(t, watcher.observe(ctx, MempoolEventData{BOC: mempoolTestBoc(t, confirmed)}))
+	require.Empty(t, updates)
+}
+
+func TestMempoolWatcherLimits(t *testing.T) {
+	destination := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"internal error"}`))
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var updates []PendingMessage
+	watcher := NewMempoolWatcher(client, nil, func(ctx context.Context, msg PendingMessage) {
+		updates = append(updates, msg)
+	}, WithMempoolMaxPending(2), WithMempoolTTL(50*time.Millisecond))
+	// messages over the limit are dropped.
+	for _, text := range []string{"a", "b", "c"} {
+		require.NoError(t, watcher.observe(ctx, MempoolEventData{BOC: mempoolTestBoc(t, mempoolTestMessage(t, destination, text))}))
+	}
+	require.Len(t, watcher.Pending(), 2)
+
+	// failing lookups keep messages pending until the TTL.
+	updates = nil
+	watcher.track(ctx)
+	require.Empty(t, updates)
+	require.Len(t, watcher.Pending(), 2)
+	time.Sleep(50 * time.Millisecond)
+	watcher.track(ctx)
+	require.Len(t, updates, 2)
+	require.Equal(t, MempoolStatusExpired, updates[0].Status)
+	require.Empty(t, watcher.Pending())
+}
@@ -0,0 +1,263 @@
+package tonapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+// MultisigEventType is the kind of change of a multisig order reported by MultisigWatcher.
+type MultisigEventType string
+
+const (
+	// MultisigOrderCreated is reported when a new order appears in a multisig wallet.
+	MultisigOrderCreated MultisigEventType = "created"
+	// MultisigOrderApproved is reported once for every signer approving an order.
+	MultisigOrderApproved MultisigEventType = "approved"
+	// MultisigOrderThresholdReached is reported when an order collects enough approvals to be executed.
+	MultisigOrderThresholdReached MultisigEventType = "threshold_reached"
+	// MultisigOrderExecuted is reported when an order is sent for execution.
+	MultisigOrderExecuted MultisigEventType = "executed"
+	// MultisigOrderExpired is reported when an order expires before being executed.
+	MultisigOrderExpired MultisigEventType = "expired"
+)
+
+// MultisigEvent is a change of a multisig order.
+type MultisigEvent struct {
+	Type     MultisigEventType
+	Multisig ton.AccountID
+	Order    MultisigOrder
+	// Signer is the signer who approved the order, set for MultisigOrderApproved only.
+	Signer ton.AccountID
+}
+
+// MultisigWatcher follows multisig wallets and reports changes of their orders.
+// It is safe for concurrent use, concurrent calls of Poll are serialized.
+type MultisigWatcher struct {
+	client    *Client
+	multisigs []ton.AccountID
+	interval  time.Duration
+	logger    StructuredLogger
+	now       func() time.Time
+
+	// mu guards orders and seeded for the whole duration of a poll.
+	mu sync.Mutex
+	// orders holds the last known state of every tracked order by its address.
+	orders map[ton.AccountID]*trackedOrder
+	seeded map[ton.AccountID]bool
+}
+
+type trackedOrder struct {
+	multisig ton.AccountID
+	order    MultisigOrder
+	done     bool
+}
+
+// MultisigWatcherOption configures a MultisigWatcher.
+type MultisigWatcherOption func(w *MultisigWatcher)
+
+// WithMultisigPollInterval sets how often multisig wallets are polled. Defaults to 10 seconds.
+func WithMultisigPollInterval(interval time.Duration) MultisigWatcherOption {
+	return func(w *MultisigWatcher) {
+		w.interval = interval
+	}
+}
+
+// WithMultisigLogger configures a logger for polling errors.
+func WithMultisigLogger(logger StructuredLogger) MultisigWatcherOption {
+	return func(w *MultisigWatcher) {
+		w.logger = logger
+	}
+}
+
+// NewMultisigWatcher returns a watcher following the given multisig wallets.
+// The first poll of a wallet only records the current state of its orders, no events are reported for it.
+func NewMultisigWatcher(client *Client, multisigs []ton.AccountID, opts ...MultisigWatcherOption) *MultisigWatcher {
+	w := &MultisigWatcher{
+		client:    client,
+		multisigs: multisigs,
+		interval:  10 * time.Second,
+		logger:    noopLogger{},
+		now:       time.Now,
+		orders:    map[ton.AccountID]*trackedOrder{},
+		seeded:    map[ton.AccountID]bool{},
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// Run polls multisig wallets until the context is done and calls the handler for every event.
+// Polling errors are logged and the failed wallet is retried on the next tick.
+func (w *MultisigWatcher) Run(ctx context.Context, handler func(MultisigEvent)) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		for _, event := range w.Poll(ctx) {
+			handler(event)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches all multisig wallets once and returns the events since the previous poll.
+func (w *MultisigWatcher) Poll(ctx context.Context) []MultisigEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var events []MultisigEvent
+	for _, multisig := range w.multisigs {
+		account, err := w.client.GetMultisigAccount(ctx, GetMultisigAccountParams{AccountID: multisig.ToRaw()})
+		if err != nil {
+			w.logger.Error("failed to get multisig", "multisig", multisig.ToRaw(), "error", err)
+			continue
+		}
+		events = append(events, w.update(ctx, multisig, account.Orders)...)
+		w.seeded[multisig] = true
+	}
+	return events
+}
+
+func (w *MultisigWatcher) update(ctx context.Context, multisig ton.AccountID, orders []MultisigOrder) []MultisigEvent {
+	var events []MultisigEvent
+	baseline := !w.seeded[multisig]
+	seen := map[ton.AccountID]struct{}{}
+	for _, order := range orders {
+		address, err := ton.ParseAccountID(order.Address)
+		if err != nil {
+			continue
+		}
+		seen[address] = struct{}{}
+		tracked, ok := w.orders[address]
+		if !ok {
+			tracked = &trackedOrder{multisig: multisig}
+			w.orders[address] = tracked
+			if !baseline {
+				events = append(events, MultisigEvent{Type: MultisigOrderCreated, Multisig: multisig, Order: order})
+			}
+		}
+		previous := tracked.order
+		tracked.order = order
+		if baseline || tracked.done {
+			tracked.done = tracked.done || w.finished(order)
+			continue
+		}
+		events = append(events, w.changes(multisig, previous, order, ok)...)
+		tracked.done = w.finished(order)
+	}
+	// orders removed from the wallet are either executed or expired, check which one happened.
+	for address, tracked := range w.orders {
+		if tracked.multisig != multisig {
+			continue
+		}
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		if tracked.done || baseline {
+			delete(w.orders, address)
+			continue
+		}
+		order := tracked.order
+		latest, err := w.client.GetMultisigOrder(ctx, GetMultisigOrderParams{AccountID: address.ToRaw()})
+		var statusErr *ErrorStatusCode
+		switch {
+		case err == nil:
+			order = *latest
+		case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound:
+			// the order contract is destroyed, the last known state is final.
+		default:
+			// keep the order tracked and look it up again on the next poll.
+			w.logger.Error("failed to get multisig order", "order", address.ToRaw(), "error", err)
+			continue
+		}
+		delete(w.orders, address)
+		events = append(events, w.changes(multisig, tracked.order, order, true)...)
+		if !w.finished(order) {
+			// the order is gone without being executed, so it can't be executed anymore.
+			events = append(events, MultisigEvent{Type: MultisigOrderExpired, Multisig: multisig, Order: order})
+		}
+	}
+	return events
+}
+
+// changes returns the events between two states of an order.
+func (w *MultisigWatcher) changes(multisig ton.AccountID, previous, current MultisigOrder, known bool) []MultisigEvent {
+	var events []MultisigEvent
+	signed := map[ton.AccountID]struct{}{}
+	if known {
+		for _, s := range previous.SignedBy {
+			if signer, err := ton.ParseAccountID(s); err == nil {
+				signed[signer] = struct{}{}
+			}
+		}
+	}
+	for _, s := range current.SignedBy {
+		signer, err := ton.ParseAccountID(s)
+		if err != nil {
+			continue
+		}
+		if _, ok := signed[signer]; !ok {
+			events = append(events, MultisigEvent{Type: MultisigOrderApproved, Multisig: multisig, Order: current, Signer: signer})
+		}
+	}
+	wasReady := known && previous.Threshold > 0 && previous.ApprovalsNum >= previous.Threshold
+	if !wasReady && current.Threshold > 0 && current.ApprovalsNum >= current.Threshold {
+		events = append(events, MultisigEvent{Type: MultisigOrderThresholdReached, Multisig: multisig, Order: current})
+	}
+	switch {
+	case current.SentForExecution && !(known && previous.SentForExecution):
+		events = append(events, MultisigEvent{Type: MultisigOrderExecuted, Multisig: multisig, Order: current})
+	case !current.SentForExecution && w.expired(current):
+		events = append(events, MultisigEvent{Type: MultisigOrderExpired, Multisig: multisig, Order: current})
+	}
+	return events
+}
+
+func (w *MultisigWatcher) expired(order MultisigOrder) bool {
+	return order.ExpirationDate > 0 && w.now().Unix() >= order.ExpirationDate
+}
+
+// finished reports whether no more events can happen to the order.
+func (w *MultisigWatcher) finished(order MultisigOrder) bool {
+	return order.SentForExecution || w.expired(order)
+}
+
+// PendingMultisigOrders returns the orders of all multisig wallets the signer belongs to
+// which still wait for the signer's approval: not signed by them, not executed and not expired.
+func (c *Client) PendingMultisigOrders(ctx context.Context, signer ton.AccountID) ([]MultisigOrder, error) {
+	multisigs, err := c.GetAccountMultisigs(ctx, GetAccountMultisigsParams{AccountID: signer.ToRaw()})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	var pending []MultisigOrder
+	for _, multisig := range multisigs.Multisigs {
+		for _, order := range multisig.Orders {
+			if order.SentForExecution || (order.ExpirationDate > 0 && now >= order.ExpirationDate) {
+				continue
+			}
+			if containsAccount(order.Signers, signer) && !containsAccount(order.SignedBy, signer) {
+				pending = append(pending, order)
+			}
+		}
+	}
+	return pending, nil
+}
+
+// containsAccount reports whether the list of addresses contains the account in any address form.
+func containsAccount(addresses []string, account ton.AccountID) bool {
+	for _, address := range addresses {
+		if id, err := ton.ParseAccountID(address); err == nil && id == account {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package tonapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func TestMultisigWatcher(t *testing.T) {
+	multisig := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	alice := "0:2222222222222222222222222222222222222222222222222222222222222222"
+	bob := "0:3333333333333333333333333333333333333333333333333333333333333333"
+	existing := "0:4444444444444444444444444444444444444444444444444444444444444444"
+	created := "0:5555555555555555555555555555555555555555555555555555555555555555"
+	expiration := time.Now().Add(time.Hour).Unix()
+
+	var mu sync.Mutex
+	state := Multisig{Address: multisig.ToRaw(), Threshold: 2, Signers: []string{alice, bob}}
+	// removed holds the state of orders gone from the wallet, a missing order means the lookup fails.
+	removed := map[string]MultisigOrder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if address, ok := strings.CutPrefix(r.URL.Path, "/v2/multisig/order/"); ok {
+			order, ok := removed[address]
+			if !ok {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			data, _ := order.MarshalJSON()
+			w.Write(data)
+			return
+		}
+		if r.URL.Path != "/v2/multisig/"+multisig.ToRaw() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := state.MarshalJSON()
+		w.Write(data)
+	}))
+	defer srv.Close()
+	setOrders := func(orders ...MultisigOrder) {
+		mu.Lock()
+		defer mu.Unlock()
+		state.Orders = orders
+	}
+	order := func(address string, signedBy ...string) MultisigOrder {
+		return MultisigOrder{
+			Address:         address,
+			Threshold:       2,
+			Signers:         []string{alice, bob},
+			SignedBy:        signedBy,
+			ApprovalsNum:    int32(len(signedBy)),
+			ExpirationDate:  expiration,
+			MultisigAddress: multisig.ToRaw(),
+		}
+	}
+	types := func(events []MultisigEvent) []MultisigEventType {
+		var result []MultisigEventType
+		for _, e := range events {
+			result = append(result, e.Type)
+		}
+		return result
+	}
+
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	watcher := NewMultisigWatcher(client, []ton.AccountID{multisig})
+	ctx := context.Background()
+
+	setOrders(order(existing, alice))
+	require.Empty(t, watcher.Poll(ctx))
+
+	setOrders(order(existing, alice), order(created))
+	events := watcher.Poll(ctx)
+	require.Equal(t, []MultisigEventType{MultisigOrderCreated}, types(events))
+	require.Equal(t, created, events[0].Order.Address)
+
+	setOrders(order(existing, alice, bob), order(created))
+	events = watcher.Poll(ctx)
+	require.Equal(t, []MultisigEventType{MultisigOrderApproved, MultisigOrderThresholdReached}, types(events))
+	require.Equal(t, ton.MustParseAccountID(bob), events[0].Signer)
+
+	executed := order(existing, alice, bob)
+	executed.SentForExecution = true
+	setOrders(executed, order(created))
+	require.Equal(t, []MultisigEventType{MultisigOrderExecuted}, types(watcher.Poll(ctx)))
+
+	watcher.now = func() time.Time { return time.Unix(expiration, 0) }
+	require.Equal(t, []MultisigEventType{MultisigOrderExpired}, types(watcher.Poll(ctx)))
+	require.Empty(t, watcher.Poll(ctx))
+
+	// an order disappears from the wallet while its state can't be fetched: nothing is concluded until the lookup succeeds.
+	watcher.now = time.Now
+	later := "0:6666666666666666666666666666666666666666666666666666666666666666"
+	setOrders(order(later, alice))
+	require.Equal(t, []MultisigEventType{MultisigOrderCreated, MultisigOrderApproved}, types(watcher.Poll(ctx)))
+	setOrders()
+	require.Empty(t, watcher.Poll(ctx))
+	require.Empty(t, watcher.Poll(ctx))
+	final := order(later, alice, bob)
+	final.SentForExecution = true
+	mu.Lock()
+	removed[later] = final
+	mu.Unlock()
+	require.Equal(t, []MultisigEventType{MultisigOrderApproved, MultisigOrderThresholdReached, MultisigOrderExecuted}, types(watcher.Poll(ctx)))
+	require.Empty(t, watcher.Poll(ctx))
+}
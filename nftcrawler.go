@@ -0,0 +1,460 @@
+package tonapi
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tonkeeper/tongo/ton"
+)
+
+const (
+	// nftItemsPageSize is the maximum page size of GetItemsFromCollection.
+	nftItemsPageSize = 1000
+	// nftItemsBulkSize is the number of items refreshed with one GetNftItemsByAddresses request.
+	nftItemsBulkSize = 100
+	// nftItemsOverlap is the number of stored items read again when looking for newly minted items,
+	// so items are not missed if the listing shifts.
+	nftItemsOverlap = 100
+	// nftHistoryPageSize is the page size of GetNftHistoryByID.
+	nftHistoryPageSize = 100
+	// nftSyncAccountsPerStream is the number of item addresses subscribed to with one SSE connection
+	// to keep the URL within common limits.
+	nftSyncAccountsPerStream = 500
+)
+
+// NftStore persists a mirror of NFT collections crawled by NftCrawler.
+// Implementations must be safe for concurrent use.
+type NftStore interface {
+	// PutCollection creates or replaces a collection.
+	PutCollection(ctx context.Context, collection NftCollection) error
+	// PutItems creates or replaces items, including their owners, sales, metadata and previews.
+	PutItems(ctx context.Context, items []NftItem) error
+	// ItemAddresses returns the addresses of all stored items of a collection.
+	ItemAddresses(ctx context.Context, collection ton.AccountID) ([]ton.AccountID, error)
+}
+
+// MemoryNftStore is an in-memory NftStore.
+type MemoryNftStore struct {
+	mu          sync.RWMutex
+	collections map[ton.AccountID]NftCollection
+	items       map[ton.AccountID]NftItem
+}
+
+// NewMemoryNftStore returns an empty MemoryNftStore.
+func NewMemoryNftStore() *MemoryNftStore {
+	return &MemoryNftStore{
+		collections: map[ton.AccountID]NftCollection{},
+		items:       map[ton.AccountID]NftItem{},
+	}
+}
+
+func (s *MemoryNftStore) PutCollection(ctx context.Context, collection NftCollection) error {
+	address, err := ton.ParseAccountID(collection.Address)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collections[address] = collection
+	return nil
+}
+
+func (s *MemoryNftStore) PutItems(ctx context.Context, items []NftItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		address, err := ton.ParseAccountID(item.Address)
+		if err != nil {
+			return err
+		}
+		s.items[address] = item
+	}
+	return nil
+}
+
+func (s *MemoryNftStore) ItemAddresses(ctx context.Context, collection ton.AccountID) ([]ton.AccountID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var addresses []ton.AccountID
+	for address, item := range s.items {
+		if nftItemCollection(item) == collection {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+// Collection returns a stored collection.
+func (s *MemoryNftStore) Collection(address ton.AccountID) (NftCollection, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	collection, ok := s.collections[address]
+	return collection, ok
+}
+
+// Item returns a stored item.
+func (s *MemoryNftStore) Item(address ton.AccountID) (NftItem, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	item, ok := s.items[address]
+	return item, ok
+}
+
+// Items returns all stored items of a collection ordered by index.
+func (s *MemoryNftStore) Items(collection ton.AccountID) []NftItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var items []NftItem
+	for _, item := range s.items {
+		if nftItemCollection(item) == collection {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Index < items[j].Index })
+	return items
+}
+
+func nftItemCollection(item NftItem) ton.AccountID {
+	collection, ok := item.Collection.Get()
+	if !ok {
+		return ton.AccountID{}
+	}
+	address, _ := ton.ParseAccountID(collection.Address)
+	return address
+}
+
+// NftCrawler mirrors an NFT collection into an NftStore.
+// Crawl indexes the whole collection once, Sync keeps the mirror up to date from streaming transactions.
+type NftCrawler struct {
+	client     *Client
+	collection ton.AccountID
+	store      NftStore
+	logger     StructuredLogger
+	flush      time.Duration
+}
+
+// NftCrawlerOption configures an NftCrawler.
+type NftCrawlerOption func(c *NftCrawler)
+
+// WithNftCrawlerLogger configures a logger for errors while syncing.
+func WithNftCrawlerLogger(logger StructuredLogger) NftCrawlerOption {
+	return func(c *NftCrawler) {
+		c.logger = logger
+	}
+}
+
+// WithNftCrawlerFlushInterval sets how long Sync collects changed items before refreshing them. Defaults to 2 seconds.
+// The delay also gives tonapi time to index the transaction that changed the item.
+func WithNftCrawlerFlushInterval(interval time.Duration) NftCrawlerOption {
+	return func(c *NftCrawler) {
+		c.flush = interval
+	}
+}
+
+// NewNftCrawler returns a crawler of the collection storing data to the store.
+func NewNftCrawler(client *Client, collection ton.AccountID, store NftStore, opts ...NftCrawlerOption) *NftCrawler {
+	c := &NftCrawler{
+		client:     client,
+		collection: collection,
+		store:      store,
+		logger:     noopLogger{},
+		flush:      2 * time.Second,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Crawl indexes the collection and all its items and returns the number of stored items.
+func (c *NftCrawler) Crawl(ctx context.Context) (int, error) {
+	collection, err := c.client.GetNftCollection(ctx, GetNftCollectionParams{AccountID: c.collection.ToRaw()})
+	if err != nil {
+		return 0, err
+	}
+	if err := c.store.PutCollection(ctx, *collection); err != nil {
+		return 0, err
+	}
+	items, err := c.crawlItems(ctx, 0)
+	return len(items), err
+}
+
+// crawlItems stores the items of the collection starting from the offset and returns their addresses.
+func (c *NftCrawler) crawlItems(ctx context.Context, offset int) ([]ton.AccountID, error) {
+	var addresses []ton.AccountID
+	for {
+		page, err := c.client.GetItemsFromCollection(ctx, GetItemsFromCollectionParams{
+			AccountID: c.collection.ToRaw(),
+			Limit:     NewOptInt(nftItemsPageSize),
+			Offset:    NewOptInt(offset),
+		})
+		if err != nil {
+			return addresses, err
+		}
+		if err := c.store.PutItems(ctx, page.NftItems); err != nil {
+			return addresses, err
+		}
+		for _, item := range page.NftItems {
+			address, err := ton.ParseAccountID(item.Address)
+			if err != nil {
+				return addresses, err
+			}
+			addresses = append(addresses, address)
+		}
+		offset += len(page.NftItems)
+		if len(page.NftItems) < nftItemsPageSize {
+			return addresses, nil
+		}
+	}
+}
+
+// ItemHistory returns the events of an item between from and to, newest first, read with GetNftHistoryByID.
+// It shows how the ownership and sale state stored by the crawler came to be.
+func (c *NftCrawler) ItemHistory(ctx context.Context, item ton.AccountID, from, to time.Time) ([]AccountEvent, error) {
+	params := GetNftHistoryByIDParams{
+		AccountID: item.ToRaw(),
+		Limit:     nftHistoryPageSize,
+		StartDate: NewOptInt64(from.Unix()),
+		EndDate:   NewOptInt64(to.Unix()),
+	}
+	var events []AccountEvent
+	for {
+		page, err := c.client.GetNftHistoryByID(ctx, params)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, page.Events...)
+		if page.NextFrom == 0 || page.NextFrom == params.BeforeLt.Or(0) {
+			return events, nil
+		}
+		params.BeforeLt = NewOptInt64(page.NextFrom)
+	}
+}
+
+// Sync subscribes to transactions of the collection and all stored items and refreshes the items
+// whose ownership or sale state may have changed. Transactions of the collection trigger fetching newly minted items,
+// which are stored and followed from then on.
+// Sync blocks until the context is done or a subscription fails.
+func (c *NftCrawler) Sync(ctx context.Context, streaming *StreamingAPI) error {
+	items, err := c.store.ItemAddresses(ctx, c.collection)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the handler only records changes, so slow refreshes don't hold up the stream readers.
+	changed := &changedAccounts{accounts: map[ton.AccountID]struct{}{}}
+	subs := &nftSubscriptions{
+		ctx:       ctx,
+		streaming: streaming,
+		handler:   func(data TransactionEventData) { changed.add(data.AccountID) },
+		fail:      cancel,
+	}
+	accounts := make([]string, 0, len(items)+1)
+	accounts = append(accounts, c.collection.ToRaw())
+	for _, item := range items {
+		accounts = append(accounts, item.ToRaw())
+	}
+	subs.follow(accounts)
+	subs.wg.Add(1)
+	go func() {
+		defer subs.wg.Done()
+		c.refreshLoop(ctx, changed, subs.follow)
+	}()
+	subs.wg.Wait()
+
+	if err := subs.err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// changedAccounts collects accounts with new transactions between refreshes.
+type changedAccounts struct {
+	mu       sync.Mutex
+	accounts map[ton.AccountID]struct{}
+}
+
+func (c *changedAccounts) add(account ton.AccountID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accounts[account] = struct{}{}
+}
+
+// take returns the collected accounts and starts a new collection.
+func (c *changedAccounts) take() map[ton.AccountID]struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	accounts := c.accounts
+	c.accounts = map[ton.AccountID]struct{}{}
+	return accounts
+}
+
+// restore returns accounts that failed to refresh to the collection.
+func (c *changedAccounts) restore(accounts map[ton.AccountID]struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for account := range accounts {
+		c.accounts[account] = struct{}{}
+	}
+}
+
+// nftSubscriptions follows accounts with as few streams as possible, nftSyncAccountsPerStream accounts per stream.
+type nftSubscriptions struct {
+	ctx       context.Context
+	streaming *StreamingAPI
+	handler   func(TransactionEventData)
+	fail      func()
+	wg        sync.WaitGroup
+
+	// mu protects the fields below.
+	mu       sync.Mutex
+	streams  []*nftStream
+	firstErr error
+}
+
+type nftStream struct {
+	accounts []string
+	cancel   context.CancelFunc
+}
+
+// follow subscribes to the accounts. They are added to the last stream while it has room:
+// a stream with the extended list replaces it, the old one is closed once the new one is started.
+func (s *nftSubscriptions) follow(accounts []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(accounts) > 0 {
+		var (
+			chunk    []string
+			replaced *nftStream
+		)
+		if n := len(s.streams); n > 0 && len(s.streams[n-1].accounts) < nftSyncAccountsPerStream {
+			replaced = s.streams[n-1]
+			s.streams = s.streams[:n-1]
+			chunk = append(chunk, replaced.accounts...)
+		}
+		n := min(nftSyncAccountsPerStream-len(chunk), len(accounts))
+		chunk = append(chunk, accounts[:n]...)
+		accounts = accounts[n:]
+		s.start(chunk)
+		if replaced != nil {
+			replaced.cancel()
+		}
+	}
+}
+
+// start opens a stream, s.mu must be held.
+func (s *nftSubscriptions) start(accounts []string) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.streams = append(s.streams, &nftStream{accounts: accounts, cancel: cancel})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		err := s.streaming.SubscribeToTransactions(ctx, accounts, nil, s.handler)
+		// replaced streams and streams of a finished Sync end with a done context.
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.firstErr == nil {
+			s.firstErr = err
+		}
+		s.mu.Unlock()
+		s.fail()
+	}()
+}
+
+// err returns the first error a stream failed with.
+func (s *nftSubscriptions) err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.firstErr
+}
+
+// size returns the number of open streams.
+func (s *nftSubscriptions) size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.streams)
+}
+
+// refreshLoop refreshes the changed accounts every flush interval.
+// Newly minted items are passed to follow.
+func (c *NftCrawler) refreshLoop(ctx context.Context, changed *changedAccounts, follow func(accounts []string)) {
+	ticker := time.NewTicker(c.flush)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pending := changed.take()
+			if len(pending) == 0 {
+				continue
+			}
+			minted, err := c.refresh(ctx, pending)
+			if len(minted) > 0 {
+				follow(minted)
+			}
+			if err != nil {
+				c.logger.Error("failed to refresh nft items", "collection", c.collection.ToRaw(), "error", err)
+				changed.restore(pending)
+			}
+		}
+	}
+}
+
+// refresh re-reads the changed items and, if the collection changed, the collection and its new items.
+// It returns the addresses of the newly minted items that were stored.
+func (c *NftCrawler) refresh(ctx context.Context, accounts map[ton.AccountID]struct{}) ([]string, error) {
+	var items []string
+	for account := range accounts {
+		if account == c.collection {
+			continue
+		}
+		items = append(items, account.ToRaw())
+	}
+	for start := 0; start < len(items); start += nftItemsBulkSize {
+		end := min(start+nftItemsBulkSize, len(items))
+		res, err := c.client.GetNftItemsByAddresses(ctx, NewOptGetNftItemsByAddressesReq(GetNftItemsByAddressesReq{
+			AccountIds: items[start:end],
+		}))
+		if err != nil {
+			return nil, err
+		}
+		if err := c.store.PutItems(ctx, res.NftItems); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := accounts[c.collection]; !ok {
+		return nil, nil
+	}
+	collection, err := c.client.GetNftCollection(ctx, GetNftCollectionParams{AccountID: c.collection.ToRaw()})
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.PutCollection(ctx, *collection); err != nil {
+		return nil, err
+	}
+	// item indices may have gaps, so new items are found by their addresses
+	// among the items listed after the ones already stored.
+	known, err := c.store.ItemAddresses(ctx, c.collection)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[ton.AccountID]struct{}, len(known))
+	for _, address := range known {
+		stored[address] = struct{}{}
+	}
+	crawled, err := c.crawlItems(ctx, max(len(known)-nftItemsOverlap, 0))
+	var minted []string
+	for _, address := range crawled {
+		if _, ok := stored[address]; !ok {
+			minted = append(minted, address.ToRaw())
+		}
+	}
+	return minted, err
+}
@@ -0,0 +1,198 @@
+package tonapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func TestNftCrawler(t *testing.T) {
+	collection := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	alice := "0:2222222222222222222222222222222222222222222222222222222222222222"
+	bob := "0:3333333333333333333333333333333333333333333333333333333333333333"
+
+	var mu sync.Mutex
+	var items []NftItem
+	mint := func(n, gap int) {
+		mu.Lock()
+		defer mu.Unlock()
+		for i := 0; i < n; i++ {
+			index := len(items)
+			items = append(items, NftItem{
+				Address:    fmt.Sprintf("0:%064x", index+1),
+				Index:      int64(index + gap),
+				Owner:      NewOptAccountAddress(AccountAddress{Address: alice}),
+				Collection: NewOptNftItemCollection(NftItemCollection{Address: collection.ToRaw()}),
+				Trust:      TrustTypeNone,
+			})
+		}
+	}
+	mint(1200, 0)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		var data []byte
+		switch r.URL.Path {
+		case "/v2/nfts/collections/" + collection.ToRaw():
+			data, _ = (&NftCollection{Address: collection.ToRaw(), NextItemIndex: items[len(items)-1].Index + 1, Trust: TrustTypeNone}).MarshalJSON()
+		case "/v2/nfts/" + items[0].Address + "/history":
+			require.Equal(t, "100", r.URL.Query().Get("limit"))
+			require.Equal(t, "1700000000", r.URL.Query().Get("start_date"))
+			page := AccountEvents{Events: []AccountEvent{}}
+			switch r.URL.Query().Get("before_lt") {
+			case "":
+				page.Events = append(page.Events, AccountEvent{EventID: "b", Lt: 20}, AccountEvent{EventID: "a", Lt: 10})
+				page.NextFrom = 10
+			case "10":
+				page.Events = append(page.Events, AccountEvent{EventID: "mint", Lt: 5})
+			}
+			data, _ = page.MarshalJSON()
+		case "/v2/nfts/collections/" + collection.ToRaw() + "/items":
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			page := NftItems{NftItems: []NftItem{}}
+			for i := offset; i < offset+limit && i < len(items); i++ {
+				page.NftItems = append(page.NftItems, items[i])
+			}
+			data, _ = page.MarshalJSON()
+		case "/v2/nfts/_bulk":
+			var req GetNftItemsByAddressesReq
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			page := NftItems{NftItems: []NftItem{}}
+			for _, item := range items {
+				for _, id := range req.AccountIds {
+					if id == item.Address {
+						page.NftItems = append(page.NftItems, item)
+					}
+				}
+			}
+			data, _ = page.MarshalJSON()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	store := NewMemoryNftStore()
+	crawler := NewNftCrawler(client, collection, store)
+	ctx := context.Background()
+
+	count, err := crawler.Crawl(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1200, count)
+	require.Len(t, store.Items(collection), 1200)
+	_, ok := store.Collection(collection)
+	require.True(t, ok)
+
+	// an item is transferred and two new items are minted after a gap in the indices.
+	mu.Lock()
+	items[5].Owner = NewOptAccountAddress(AccountAddress{Address: bob})
+	transferred := ton.MustParseAccountID(items[5].Address)
+	mu.Unlock()
+	mint(2, 10)
+
+	minted, err := crawler.refresh(ctx, map[ton.AccountID]struct{}{transferred: {}, collection: {}})
+	require.NoError(t, err)
+	require.Equal(t, []string{items[1200].Address, items[1201].Address}, minted)
+	item, ok := store.Item(transferred)
+	require.True(t, ok)
+	require.Equal(t, bob, item.Owner.Value.Address)
+	require.Len(t, store.Items(collection), 1202)
+
+	// without new items nothing is followed.
+	minted, err = crawler.refresh(ctx, map[ton.AccountID]struct{}{collection: {}})
+	require.NoError(t, err)
+	require.Empty(t, minted)
+
+	history, err := crawler.ItemHistory(ctx, ton.MustParseAccountID(items[0].Address), time.Unix(1700000000, 0), time.Now())
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, "mint", history[2].EventID)
+}
+
+func TestNftSubscriptions(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		streams = map[*http.Request]int{}
+	)
+	event := ton.MustParseAccountID("0:0000000000000000000000000000000000000000000000000000000000000001")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		streams[r] = len(strings.Split(r.URL.Query().Get("accounts"), ","))
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			delete(streams, r)
+			mu.Unlock()
+		}()
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: message\ndata: {\"account_id\":%q,\"lt\":1,\"tx_hash\":\"aa\"}\n\n", event.ToRaw())
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := &changedAccounts{accounts: map[ton.AccountID]struct{}{}}
+	subs := &nftSubscriptions{
+		ctx:       ctx,
+		streaming: NewStreamingAPI(WithStreamingEndpoint(srv.URL)),
+		handler:   func(data TransactionEventData) { changed.add(data.AccountID) },
+		fail:      func() { t.Error("subscription failed") },
+	}
+	next := 0
+	follow := func(n int) {
+		var accounts []string
+		for i := 0; i < n; i++ {
+			next++
+			accounts = append(accounts, fmt.Sprintf("0:%064x", next))
+		}
+		subs.follow(accounts)
+	}
+	// requireStreams waits until the server has the streams with the given numbers of accounts open.
+	requireStreams := func(sizes ...int) {
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			var open []int
+			for _, n := range streams {
+				open = append(open, n)
+			}
+			sort.Ints(open)
+			return fmt.Sprint(open) == fmt.Sprint(sizes)
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	follow(1200)
+	requireStreams(200, 500, 500)
+	require.Equal(t, 3, subs.size())
+	// new accounts fill the last stream before another one is opened.
+	follow(10)
+	requireStreams(210, 500, 500)
+	follow(350)
+	requireStreams(60, 500, 500, 500)
+	require.Equal(t, 4, subs.size())
+	require.Contains(t, changed.take(), event)
+
+	cancel()
+	subs.wg.Wait()
+	requireStreams()
+	require.NoError(t, subs.err())
+}
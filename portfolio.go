@@ -0,0 +1,295 @@
+package tonapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tonkeeper/tongo/ton"
+)
+
+const (
+	// gramToken is the name of the native coin in GetRates.
+	gramToken = "gram"
+	// tonDecimals is the number of decimals of the native coin.
+	tonDecimals = 9
+	// jettonBalancesPageSize is the maximum page size of GetAccountJettonsBalances.
+	jettonBalancesPageSize = 1000
+)
+
+// AssetKind is the kind of a PortfolioAsset.
+type AssetKind string
+
+const (
+	AssetTon           AssetKind = "ton"
+	AssetJetton        AssetKind = "jetton"
+	AssetExtraCurrency AssetKind = "extra_currency"
+	AssetStaking       AssetKind = "staking"
+	AssetDefi          AssetKind = "defi"
+)
+
+// PriceSource tells where the price of a PortfolioAsset comes from.
+type PriceSource string
+
+const (
+	// PriceSourceRates is a price returned by GetRates.
+	PriceSourceRates PriceSource = "rates"
+	// PriceSourceJettonBalance is a price returned along with the jetton balance by GetAccountJettonsBalances.
+	PriceSourceJettonBalance PriceSource = "jetton_balance"
+	// PriceSourceExtraCurrency is a price returned by the ExtraCurrencyRatesFunc set with WithPortfolioExtraCurrencyRates.
+	PriceSourceExtraCurrency PriceSource = "extra_currency"
+	// PriceSourceNone means the asset has no known price and is not included in Portfolio.Total.
+	PriceSourceNone PriceSource = "none"
+)
+
+// PortfolioAsset is a valued position of an account.
+type PortfolioAsset struct {
+	Kind   AssetKind
+	Symbol string
+	// Address is the jetton master for jettons and jetton positions,
+	// the pool for staking and DeFi positions and empty for TON and extra currencies.
+	Address string
+	// CurrencyID is the id of an extra currency.
+	CurrencyID int32
+	// Provider is the staking pool or DeFi provider name.
+	Provider string
+	// Amount is in the smallest units of the asset, Decimals tells how many of them make a whole unit.
+	Amount   *big.Int
+	Decimals int
+	// Price is the price of a whole unit in the portfolio currency.
+	Price       float64
+	PriceSource PriceSource
+	// Value is the amount multiplied by the price. It is negative for borrowed assets.
+	Value float64
+	// Diff24h and Diff7d are price changes as reported by TokenRates, for example "-1.25%".
+	Diff24h string
+	Diff7d  string
+}
+
+// Portfolio is a valued report of all assets of an account.
+type Portfolio struct {
+	Account  ton.AccountID
+	Currency string
+	Assets   []PortfolioAsset
+	// Total is the sum of values of all priced assets.
+	Total float64
+}
+
+// ExtraCurrencyRatesFunc returns the rates of an extra currency in the fiat currency.
+// Rates without a price in the fiat currency leave the extra currency unpriced.
+type ExtraCurrencyRatesFunc func(ctx context.Context, currency EcPreview, fiat string) (TokenRates, error)
+
+// PortfolioOption configures Portfolio.
+type PortfolioOption func(o *portfolioOptions)
+
+type portfolioOptions struct {
+	extraCurrencyRates ExtraCurrencyRatesFunc
+}
+
+// WithPortfolioExtraCurrencyRates sets the function pricing extra currencies.
+// GetRates doesn't know extra currencies, so without it they are reported with PriceSourceNone.
+func WithPortfolioExtraCurrencyRates(rates ExtraCurrencyRatesFunc) PortfolioOption {
+	return func(o *portfolioOptions) {
+		o.extraCurrencyRates = rates
+	}
+}
+
+// Portfolio aggregates TON, jettons, extra currencies, staking positions from GetAccountNominatorsPools
+// and DeFi positions from GetAccountDefiAssets into one report valued in the fiat currency, e.g. "usd".
+// Assets without a known price are reported with PriceSourceNone and are not included in the total.
+func (c *Client) Portfolio(ctx context.Context, account ton.AccountID, fiat string, opts ...PortfolioOption) (*Portfolio, error) {
+	var options portfolioOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	fiat = strings.ToLower(fiat)
+	info, err := c.GetAccount(ctx, GetAccountParams{AccountID: account.ToRaw()})
+	if err != nil {
+		return nil, err
+	}
+	jettons, err := c.allJettonBalances(ctx, account, fiat)
+	if err != nil {
+		return nil, err
+	}
+	staking, err := c.GetAccountNominatorsPools(ctx, GetAccountNominatorsPoolsParams{AccountID: account.ToRaw()})
+	if err != nil {
+		return nil, err
+	}
+	defi, err := c.GetAccountDefiAssets(ctx, GetAccountDefiAssetsParams{AccountID: account.ToRaw()})
+	if err != nil {
+		return nil, err
+	}
+
+	// jettons locked in DeFi are not necessarily held by the account, so their rates are requested separately.
+	tokens := []string{gramToken}
+	for _, asset := range defi.Assets {
+		if jetton, ok := asset.LockedAsset.Jetton.Get(); ok && asset.LockedAsset.Type == DefiLockedAssetTypeJetton {
+			tokens = append(tokens, jetton.Address)
+		}
+	}
+	rates, err := c.GetRates(ctx, GetRatesParams{Tokens: tokens, Currencies: []string{fiat}})
+	if err != nil {
+		return nil, err
+	}
+	tonRates, _ := findTokenRates(rates.Rates, gramToken)
+
+	portfolio := &Portfolio{Account: account, Currency: fiat}
+	add := func(asset PortfolioAsset, tokenRates TokenRates, source PriceSource) {
+		asset.PriceSource = PriceSourceNone
+		if price, ok := ratePrice(tokenRates, fiat); ok {
+			asset.Price = price
+			asset.PriceSource = source
+			asset.Value = assetValue(asset.Amount, asset.Decimals, price)
+			if asset.Amount.Sign() < 0 {
+				asset.Value = -asset.Value
+			}
+			asset.Diff24h = rateDiff(tokenRates.Diff24h.Value, fiat)
+			asset.Diff7d = rateDiff(tokenRates.Diff7d.Value, fiat)
+			portfolio.Total += asset.Value
+		}
+		portfolio.Assets = append(portfolio.Assets, asset)
+	}
+
+	add(PortfolioAsset{Kind: AssetTon, Symbol: "TON", Amount: big.NewInt(info.Balance), Decimals: tonDecimals}, tonRates, PriceSourceRates)
+	for _, ec := range info.ExtraBalance {
+		amount, ok := new(big.Int).SetString(ec.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q of extra currency %v", ec.Amount, ec.Preview.ID)
+		}
+		var ecRates TokenRates
+		if options.extraCurrencyRates != nil {
+			ecRates, err = options.extraCurrencyRates(ctx, ec.Preview, fiat)
+			if err != nil {
+				return nil, fmt.Errorf("rates of extra currency %v: %w", ec.Preview.ID, err)
+			}
+		}
+		add(PortfolioAsset{
+			Kind:       AssetExtraCurrency,
+			Symbol:     ec.Preview.Symbol,
+			CurrencyID: ec.Preview.ID,
+			Amount:     amount,
+			Decimals:   ec.Preview.Decimals,
+		}, ecRates, PriceSourceExtraCurrency)
+	}
+	for _, balance := range jettons {
+		amount, ok := new(big.Int).SetString(balance.Balance, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid balance %q of jetton %v", balance.Balance, balance.Jetton.Address)
+		}
+		add(PortfolioAsset{
+			Kind:     AssetJetton,
+			Symbol:   balance.Jetton.Symbol,
+			Address:  balance.Jetton.Address,
+			Amount:   amount,
+			Decimals: balance.Jetton.Decimals,
+		}, balance.Price.Value, PriceSourceJettonBalance)
+	}
+	for _, pool := range staking.Pools {
+		add(PortfolioAsset{
+			Kind:     AssetStaking,
+			Symbol:   "TON",
+			Address:  pool.Pool,
+			Amount:   big.NewInt(pool.Amount + pool.PendingDeposit + pool.ReadyWithdraw),
+			Decimals: tonDecimals,
+		}, tonRates, PriceSourceRates)
+	}
+	for _, asset := range defi.Assets {
+		amount, ok := new(big.Int).SetString(asset.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q of defi asset", asset.Amount)
+		}
+		if asset.Type == DefiAssetTypeLendingBorrow {
+			amount.Neg(amount)
+		}
+		position := PortfolioAsset{
+			Kind:     AssetDefi,
+			Address:  asset.PoolAddress.Value,
+			Provider: asset.DefiProvider.Name,
+			Amount:   amount,
+		}
+		// jettons locked without a preview are reported without a price.
+		var tokenRates TokenRates
+		switch jetton, ok := asset.LockedAsset.Jetton.Get(); {
+		case asset.LockedAsset.Type == DefiLockedAssetTypeNative:
+			position.Symbol = "TON"
+			position.Decimals = tonDecimals
+			tokenRates = tonRates
+		case asset.LockedAsset.Type == DefiLockedAssetTypeJetton && ok:
+			position.Symbol = jetton.Symbol
+			position.Decimals = jetton.Decimals
+			tokenRates, _ = findTokenRates(rates.Rates, jetton.Address)
+		}
+		add(position, tokenRates, PriceSourceRates)
+	}
+	return portfolio, nil
+}
+
+// allJettonBalances pages through GetAccountJettonsBalances.
+func (c *Client) allJettonBalances(ctx context.Context, account ton.AccountID, fiat string) ([]JettonBalance, error) {
+	var balances []JettonBalance
+	for offset := 0; ; offset += jettonBalancesPageSize {
+		page, err := c.GetAccountJettonsBalances(ctx, GetAccountJettonsBalancesParams{
+			AccountID:  account.ToRaw(),
+			Currencies: []string{fiat},
+			Limit:      NewOptInt(jettonBalancesPageSize),
+			Offset:     NewOptInt(offset),
+		})
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, page.Balances...)
+		if len(page.Balances) < jettonBalancesPageSize {
+			return balances, nil
+		}
+	}
+}
+
+// findTokenRates looks up the rates of a token by its name or by its jetton master address in any form.
+func findTokenRates(rates GetRatesOKRates, token string) (TokenRates, bool) {
+	for key, r := range rates {
+		// the native coin used to be called TON.
+		if strings.EqualFold(key, token) || (token == gramToken && strings.EqualFold(key, "ton")) {
+			return r, true
+		}
+	}
+	id, err := ton.ParseAccountID(token)
+	if err != nil {
+		return TokenRates{}, false
+	}
+	for key, r := range rates {
+		if other, err := ton.ParseAccountID(key); err == nil && other == id {
+			return r, true
+		}
+	}
+	return TokenRates{}, false
+}
+
+// ratePrice returns the price in the currency, currencies are matched case-insensitively.
+func ratePrice(rates TokenRates, currency string) (float64, bool) {
+	for key, price := range rates.Prices.Value {
+		if strings.EqualFold(key, currency) {
+			return price, true
+		}
+	}
+	return 0, false
+}
+
+func rateDiff(diff map[string]string, currency string) string {
+	for key, value := range diff {
+		if strings.EqualFold(key, currency) {
+			return value
+		}
+	}
+	return ""
+}
+
+// assetValue converts an amount in the smallest units to whole units and multiplies it by the price.
+func assetValue(amount *big.Int, decimals int, price float64) float64 {
+	value := new(big.Float).SetInt(new(big.Int).Abs(amount))
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	value.Quo(value, unit)
+	value.Mul(value, big.NewFloat(price))
+	result, _ := value.Float64()
+	return result
+}
@@ -0,0 +1,125 @@
+package tonapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+func TestPortfolio(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	usdt := "0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe"
+	pool := "0:2222222222222222222222222222222222222222222222222222222222222222"
+	usdtPreview := JettonPreview{Address: usdt, Symbol: "USD₮", Decimals: 6, Verification: JettonVerificationTypeWhitelist}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var data []byte
+		switch r.URL.Path {
+		case "/v2/accounts/" + account.ToRaw():
+			data, _ = (&Account{
+				Address: account.ToRaw(),
+				Balance: 2_000_000_000,
+				Status:  AccountStatusActive,
+				ExtraBalance: []ExtraCurrency{
+					{Amount: "1000", Preview: EcPreview{ID: 100, Symbol: "ECHIDNA", Decimals: 2}},
+				},
+			}).MarshalJSON()
+		case "/v2/accounts/" + account.ToRaw() + "/jettons":
+			require.Equal(t, "usd", r.URL.Query().Get("currencies"))
+			data, _ = (&JettonsBalances{Balances: []JettonBalance{{
+				Balance: "5000000",
+				Jetton:  usdtPreview,
+				Price: NewOptTokenRates(TokenRates{
+					Prices:  NewOptTokenRatesPrices(TokenRatesPrices{"USD": 1}),
+					Diff24h: NewOptTokenRatesDiff24h(TokenRatesDiff24h{"USD": "+0.01%"}),
+				}),
+			}}}).MarshalJSON()
+		case "/v2/staking/nominator/" + account.ToRaw() + "/pools":
+			data, _ = (&AccountStaking{Pools: []AccountStakingInfo{{Pool: pool, Amount: 1_000_000_000}}}).MarshalJSON()
+		case "/v2/accounts/" + account.ToRaw() + "/defi/assets":
+			data, _ = (&DefiAssets{Assets: []DefiAsset{
+				{
+					Type:        DefiAssetTypeLendingBorrow,
+					Amount:      "2000000",
+					PoolAddress: NewOptString(pool),
+					LockedAsset: DefiLockedAsset{Type: DefiLockedAssetTypeJetton, Jetton: NewOptJettonPreview(usdtPreview)},
+				},
+				{
+					Type:        DefiAssetTypeLendingSupply,
+					Amount:      "500000000",
+					PoolAddress: NewOptString(pool),
+					LockedAsset: DefiLockedAsset{Type: DefiLockedAssetTypeNative},
+				},
+				{
+					Type:        DefiAssetTypeLendingSupply,
+					Amount:      "7",
+					PoolAddress: NewOptString(pool),
+					LockedAsset: DefiLockedAsset{Type: DefiLockedAssetTypeJetton},
+				},
+			}}).MarshalJSON()
+		case "/v2/rates":
+			data, _ = (&GetRatesOK{Rates: GetRatesOKRates{
+				"TON": {
+					Prices:  NewOptTokenRatesPrices(TokenRatesPrices{"USD": 3}),
+					Diff24h: NewOptTokenRatesDiff24h(TokenRatesDiff24h{"USD": "-1.50%"}),
+					Diff7d:  NewOptTokenRatesDiff7d(TokenRatesDiff7d{"USD": "+4.00%"}),
+				},
+				usdt: {Prices: NewOptTokenRatesPrices(TokenRatesPrices{"USD": 1})},
+			}}).MarshalJSON()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	ctx := context.Background()
+	portfolio, err := client.Portfolio(ctx, account, "USD")
+	require.NoError(t, err)
+
+	require.Len(t, portfolio.Assets, 7)
+	tonAsset, ec, jetton, staking, defi := portfolio.Assets[0], portfolio.Assets[1], portfolio.Assets[2], portfolio.Assets[3], portfolio.Assets[4]
+	require.Equal(t, AssetTon, tonAsset.Kind)
+	require.Equal(t, 6.0, tonAsset.Value)
+	require.Equal(t, "-1.50%", tonAsset.Diff24h)
+	require.Equal(t, "+4.00%", tonAsset.Diff7d)
+	require.Equal(t, PriceSourceNone, ec.PriceSource)
+	require.Equal(t, big.NewInt(1000), ec.Amount)
+	require.Equal(t, PriceSourceJettonBalance, jetton.PriceSource)
+	require.Equal(t, 5.0, jetton.Value)
+	require.Equal(t, 3.0, staking.Value)
+	require.Equal(t, -2.0, defi.Value)
+	// native DeFi assets are TON, jettons without a preview have no price.
+	require.Equal(t, "TON", portfolio.Assets[5].Symbol)
+	require.Equal(t, 1.5, portfolio.Assets[5].Value)
+	require.Equal(t, PriceSourceNone, portfolio.Assets[6].PriceSource)
+	require.Empty(t, portfolio.Assets[6].Symbol)
+	require.Equal(t, 13.5, portfolio.Total)
+
+	// extra currencies are priced one by one.
+	portfolio, err = client.Portfolio(ctx, account, "USD", WithPortfolioExtraCurrencyRates(func(ctx context.Context, currency EcPreview, fiat string) (TokenRates, error) {
+		require.Equal(t, int32(100), currency.ID)
+		require.Equal(t, "usd", fiat)
+		return TokenRates{Prices: NewOptTokenRatesPrices(TokenRatesPrices{"USD": 0.5})}, nil
+	}))
+	require.NoError(t, err)
+	ec = portfolio.Assets[1]
+	require.Equal(t, PriceSourceExtraCurrency, ec.PriceSource)
+	require.Equal(t, 5.0, ec.Value)
+	require.Equal(t, 18.5, portfolio.Total)
+
+	_, err = client.Portfolio(ctx, account, "USD", WithPortfolioExtraCurrencyRates(func(context.Context, EcPreview, string) (TokenRates, error) {
+		return TokenRates{}, errors.New("no rates")
+	}))
+	require.ErrorContains(t, err, "no rates")
+}
@@ -0,0 +1,396 @@
+package tonapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+const (
+	// maxChartPoints is the maximum number of points returned by GetChartRates.
+	maxChartPoints = 200
+	// defaultPriceWindow is the time range fetched with one GetChartRates request.
+	// 200 points a day gives a price every 7 minutes or so.
+	defaultPriceWindow = 24 * time.Hour
+)
+
+// ErrNoPrice is returned when there are no chart points close enough to the requested time.
+var ErrNoPrice = errors.New("no price for the requested time")
+
+// PriceOracle answers what a token was worth in a currency at a point in time.
+// It fetches chart points with GetChartRates in fixed windows, caches them and interpolates between points.
+// Tokens are jetton master addresses or "gram" for the native coin.
+type PriceOracle struct {
+	client *Client
+	window time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[chartKey][]chartPoint
+}
+
+type chartKey struct {
+	token    string
+	currency string
+	start    int64
+}
+
+type chartPoint struct {
+	time  int64
+	price float64
+}
+
+// PriceOracleOption configures a PriceOracle.
+type PriceOracleOption func(o *PriceOracle)
+
+// WithPriceOracleWindow sets the time range fetched with one GetChartRates request. Defaults to 24 hours.
+// Since a request returns at most 200 points, a smaller window gives more precise prices at the cost of more requests.
+// Windows shorter than a second are ignored.
+func WithPriceOracleWindow(window time.Duration) PriceOracleOption {
+	return func(o *PriceOracle) {
+		if window >= time.Second {
+			o.window = window
+		}
+	}
+}
+
+// NewPriceOracle returns a PriceOracle with an empty cache.
+func NewPriceOracle(client *Client, opts ...PriceOracleOption) *PriceOracle {
+	o := &PriceOracle{
+		client: client,
+		window: defaultPriceWindow,
+		now:    time.Now,
+		cache:  map[chartKey][]chartPoint{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Price returns the price of a whole unit of the token in the currency at the given time,
+// linearly interpolated between the closest chart points.
+// If the time is outside the available points, the closest point within one window is used, otherwise ErrNoPrice is returned.
+func (o *PriceOracle) Price(ctx context.Context, token, currency string, at time.Time) (float64, error) {
+	currency = strings.ToLower(currency)
+	window := int64(o.window / time.Second)
+	ts := at.Unix()
+	start := ts - ts%window
+
+	points, err := o.points(ctx, token, currency, start)
+	if err != nil {
+		return 0, err
+	}
+	// the neighbouring windows are needed to interpolate near the window edges.
+	if len(points) == 0 || ts < points[0].time {
+		previous, err := o.points(ctx, token, currency, start-window)
+		if err != nil {
+			return 0, err
+		}
+		points = append(append([]chartPoint{}, previous...), points...)
+	}
+	if (len(points) == 0 || ts > points[len(points)-1].time) && start+window <= o.now().Unix() {
+		next, err := o.points(ctx, token, currency, start+window)
+		if err != nil {
+			return 0, err
+		}
+		points = append(points[:len(points):len(points)], next...)
+	}
+	return interpolatePrice(points, ts, window)
+}
+
+// Convert returns the value of an amount in the smallest units of the token in the currency at the given time.
+func (o *PriceOracle) Convert(ctx context.Context, token, currency string, amount *big.Int, decimals int, at time.Time) (float64, error) {
+	price, err := o.Price(ctx, token, currency, at)
+	if err != nil {
+		return 0, err
+	}
+	value := assetValue(amount, decimals, price)
+	if amount.Sign() < 0 {
+		value = -value
+	}
+	return value, nil
+}
+
+// ActionValue is the value of an asset moved by an action of an event at the time of the event.
+type ActionValue struct {
+	// Action is the index of the action in the event.
+	Action   int
+	Type     ActionType
+	Token    string
+	Symbol   string
+	Amount   *big.Int
+	Decimals int
+	Price    float64
+	Value    float64
+}
+
+// ConvertEvent values the assets moved by the actions of a trace event at the event time.
+// Assets without a price, like extra currencies or jettons without chart points around the event, are skipped.
+func (o *PriceOracle) ConvertEvent(ctx context.Context, event Event, currency string) ([]ActionValue, error) {
+	return o.convertActions(ctx, event.Actions, time.Unix(event.Timestamp, 0), currency)
+}
+
+// ConvertAccountEvent values the assets moved by the actions of an account event at the event time.
+// Assets without a price, like extra currencies or jettons without chart points around the event, are skipped.
+func (o *PriceOracle) ConvertAccountEvent(ctx context.Context, event AccountEvent, currency string) ([]ActionValue, error) {
+	return o.convertActions(ctx, event.Actions, time.Unix(event.Timestamp, 0), currency)
+}
+
+func (o *PriceOracle) convertActions(ctx context.Context, actions []Action, at time.Time, currency string) ([]ActionValue, error) {
+	var values []ActionValue
+	for i, action := range actions {
+		for _, asset := range actionAssets(action) {
+			if asset.Token == "" {
+				continue
+			}
+			price, err := o.Price(ctx, asset.Token, currency, at)
+			if errors.Is(err, ErrNoPrice) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("action %v: %w", i, err)
+			}
+			values = append(values, ActionValue{
+				Action:   i,
+				Type:     action.Type,
+				Token:    asset.Token,
+				Symbol:   asset.Symbol,
+				Amount:   asset.Amount,
+				Decimals: asset.Decimals,
+				Price:    price,
+				Value:    assetValue(asset.Amount, asset.Decimals, price),
+			})
+		}
+	}
+	return values, nil
+}
+
+// points returns the cached chart points of the window starting at start, fetching them if needed.
+func (o *PriceOracle) points(ctx context.Context, token, currency string, start int64) ([]chartPoint, error) {
+	key := chartKey{token: token, currency: currency, start: start}
+	o.mu.Lock()
+	points, ok := o.cache[key]
+	o.mu.Unlock()
+	if ok {
+		return points, nil
+	}
+	end := start + int64(o.window/time.Second)
+	res, err := o.client.GetChartRates(ctx, GetChartRatesParams{
+		Token:       token,
+		Currency:    NewOptString(currency),
+		StartDate:   NewOptInt64(start),
+		EndDate:     NewOptInt64(end),
+		PointsCount: NewOptInt(maxChartPoints),
+	})
+	if err != nil {
+		return nil, err
+	}
+	points = make([]chartPoint, 0, len(res.Points))
+	for _, p := range res.Points {
+		if len(p) < 2 {
+			continue
+		}
+		points = append(points, chartPoint{time: int64(p[0]), price: p[1]})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].time < points[j].time })
+	// the current window is still being filled, so it is not cached.
+	if end <= o.now().Unix() {
+		o.mu.Lock()
+		o.cache[key] = points
+		o.mu.Unlock()
+	}
+	return points, nil
+}
+
+// interpolatePrice returns the price at ts from points sorted by time.
+func interpolatePrice(points []chartPoint, ts, maxGap int64) (float64, error) {
+	if len(points) == 0 {
+		return 0, ErrNoPrice
+	}
+	i := sort.Search(len(points), func(i int) bool { return points[i].time >= ts })
+	switch {
+	case i < len(points) && points[i].time == ts:
+		return points[i].price, nil
+	case i == 0:
+		if points[0].time-ts > maxGap {
+			return 0, ErrNoPrice
+		}
+		return points[0].price, nil
+	case i == len(points):
+		last := points[len(points)-1]
+		if ts-last.time > maxGap {
+			return 0, ErrNoPrice
+		}
+		return last.price, nil
+	}
+	before, after := points[i-1], points[i]
+	ratio := float64(ts-before.time) / float64(after.time-before.time)
+	return before.price + (after.price-before.price)*ratio, nil
+}
+
+// actionAsset is an amount of an asset moved by an action.
+type actionAsset struct {
+	// Token is "gram" for the native coin, a jetton master address for jettons and empty for extra currencies and NFTs.
+	Token      string
+	Symbol     string
+	CurrencyID int32
+	// Nft is the address of a transferred NFT item.
+	Nft      string
+	Amount   *big.Int
+	Decimals int
+	// Sender and Recipient are the accounts the asset moves between, empty if unknown.
+	Sender    string
+	Recipient string
+	Comment   string
+}
+
+// actionAssets returns the assets moved by TON, jetton, extra currency, NFT, staking, swap, purchase,
+// subscription, liquidity deposit and contract call actions.
+// Swaps have two assets, the one the user sends to the router and the one it receives.
+func actionAssets(action Action) []actionAsset {
+	tonAsset := func(amount int64, sender, recipient, comment string) []actionAsset {
+		return []actionAsset{{
+			Token:     gramToken,
+			Symbol:    "TON",
+			Amount:    big.NewInt(amount),
+			Decimals:  tonDecimals,
+			Sender:    sender,
+			Recipient: recipient,
+			Comment:   comment,
+		}}
+	}
+	jetton := func(amount string, preview JettonPreview, sender, recipient, comment string) []actionAsset {
+		value, ok := new(big.Int).SetString(amount, 10)
+		if !ok {
+			return nil
+		}
+		return []actionAsset{{
+			Token:     preview.Address,
+			Symbol:    preview.Symbol,
+			Amount:    value,
+			Decimals:  preview.Decimals,
+			Sender:    sender,
+			Recipient: recipient,
+			Comment:   comment,
+		}}
+	}
+	price := func(p Price, sender, recipient string) []actionAsset {
+		switch p.CurrencyType {
+		case CurrencyTypeNative:
+			value, ok := new(big.Int).SetString(p.Value, 10)
+			if !ok {
+				return nil
+			}
+			return []actionAsset{{Token: gramToken, Symbol: "TON", Amount: value, Decimals: tonDecimals, Sender: sender, Recipient: recipient}}
+		case CurrencyTypeJetton:
+			return jetton(p.Value, JettonPreview{Address: p.Jetton.Value, Symbol: p.TokenName, Decimals: p.Decimals}, sender, recipient, "")
+		}
+		return nil
+	}
+	switch action.Type {
+	case ActionTypeTonTransfer:
+		a := action.TonTransfer.Value
+		return tonAsset(a.Amount, a.Sender.Address, a.Recipient.Address, a.Comment.Value)
+	case ActionTypeJettonTransfer:
+		a := action.JettonTransfer.Value
+		return jetton(a.Amount, a.Jetton, a.Sender.Value.Address, a.Recipient.Value.Address, a.Comment.Value)
+	case ActionTypeJettonBurn:
+		a := action.JettonBurn.Value
+		return jetton(a.Amount, a.Jetton, a.Sender.Address, "", "")
+	case ActionTypeJettonMint:
+		a := action.JettonMint.Value
+		return jetton(a.Amount, a.Jetton, "", a.Recipient.Address, "")
+	case ActionTypeNftItemTransfer:
+		a := action.NftItemTransfer.Value
+		return []actionAsset{{
+			Nft:       a.Nft,
+			Symbol:    "NFT",
+			Amount:    big.NewInt(1),
+			Sender:    a.Sender.Value.Address,
+			Recipient: a.Recipient.Value.Address,
+			Comment:   a.Comment.Value,
+		}}
+	case ActionTypeExtraCurrencyTransfer:
+		a := action.ExtraCurrencyTransfer.Value
+		value, ok := new(big.Int).SetString(a.Amount, 10)
+		if !ok {
+			return nil
+		}
+		return []actionAsset{{
+			Symbol:     a.Currency.Symbol,
+			CurrencyID: a.Currency.ID,
+			Amount:     value,
+			Decimals:   a.Currency.Decimals,
+			Sender:     a.Sender.Address,
+			Recipient:  a.Recipient.Address,
+			Comment:    a.Comment.Value,
+		}}
+	case ActionTypeDepositStake:
+		a := action.DepositStake.Value
+		return tonAsset(a.Amount, a.Staker.Address, a.Pool.Address, "")
+	case ActionTypeWithdrawStake:
+		a := action.WithdrawStake.Value
+		return tonAsset(a.Amount, a.Pool.Address, a.Staker.Address, "")
+	case ActionTypeSmartContractExec:
+		a := action.SmartContractExec.Value
+		return tonAsset(a.GramAttached, a.Executor.Address, a.Contract.Address, "")
+	case ActionTypeElectionsDepositStake:
+		a := action.ElectionsDepositStake.Value
+		return tonAsset(a.Amount, a.Staker.Address, "", "")
+	case ActionTypeElectionsRecoverStake:
+		a := action.ElectionsRecoverStake.Value
+		return tonAsset(a.Amount, "", a.Staker.Address, "")
+	case ActionTypeJettonSwap:
+		a := action.JettonSwap.Value
+		user, router := a.UserWallet.Address, a.Router.Address
+		var assets []actionAsset
+		if in, ok := a.JettonMasterIn.Get(); ok {
+			assets = append(assets, jetton(a.AmountIn, in, user, router, "")...)
+		} else if gramIn, ok := a.GramIn.Get(); ok {
+			assets = append(assets, tonAsset(gramIn, user, router, "")...)
+		} else if tonIn, ok := a.TonIn.Get(); ok {
+			assets = append(assets, tonAsset(tonIn, user, router, "")...)
+		}
+		if out, ok := a.JettonMasterOut.Get(); ok {
+			assets = append(assets, jetton(a.AmountOut, out, router, user, "")...)
+		} else if gramOut, ok := a.GramOut.Get(); ok {
+			assets = append(assets, tonAsset(gramOut, router, user, "")...)
+		} else if tonOut, ok := a.TonOut.Get(); ok {
+			assets = append(assets, tonAsset(tonOut, router, user, "")...)
+		}
+		return assets
+	case ActionTypeNftPurchase:
+		a := action.NftPurchase.Value
+		return append(price(a.Amount, a.Buyer.Address, a.Seller.Address), actionAsset{
+			Nft:       a.Nft.Address,
+			Symbol:    "NFT",
+			Amount:    big.NewInt(1),
+			Sender:    a.Seller.Address,
+			Recipient: a.Buyer.Address,
+		})
+	case ActionTypeAuctionBid:
+		a := action.AuctionBid.Value
+		return price(a.Amount, a.Bidder.Address, a.Auction.Address)
+	case ActionTypePurchase:
+		a := action.Purchase.Value
+		return price(a.Amount, a.Source.Address, a.Destination.Address)
+	case ActionTypeSubscribe:
+		a := action.Subscribe.Value
+		return price(a.Price, a.Subscriber.Address, a.Beneficiary.Address)
+	case ActionTypeLiquidityDeposit:
+		a := action.LiquidityDeposit.Value
+		var assets []actionAsset
+		for _, token := range a.Tokens {
+			assets = append(assets, price(token.Price, a.From.Address, token.Vault)...)
+		}
+		return assets
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package tonapi
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceOracle(t *testing.T) {
+	usdt := "0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe"
+	unlisted := "0:1111111111111111111111111111111111111111111111111111111111111111"
+	var requests int32
+	// the price of TON grows by 1 every hour, points are returned newest first.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		query := r.URL.Query()
+		require.Equal(t, "eur", query.Get("currency"))
+		start, _ := strconv.ParseInt(query.Get("start_date"), 10, 64)
+		end, _ := strconv.ParseInt(query.Get("end_date"), 10, 64)
+		res := GetChartRatesOK{Points: ChartPoints{}}
+		for ts := end - end%3600; ts >= start && query.Get("token") != unlisted; ts -= 3600 {
+			price := float64(ts) / 3600
+			if query.Get("token") == usdt {
+				price = 0.9
+			}
+			res.Points = append(res.Points, []float64{float64(ts), price})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := res.MarshalJSON()
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	oracle := NewPriceOracle(client)
+	ctx := context.Background()
+
+	day := int64(1_700_006_400) // midnight UTC
+	at := time.Unix(day+10*3600+1800, 0)
+	price, err := oracle.Price(ctx, gramToken, "EUR", at)
+	require.NoError(t, err)
+	require.InDelta(t, float64(day)/3600+10.5, price, 1e-9)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// cached.
+	_, err = oracle.Price(ctx, gramToken, "eur", at.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	event := AccountEvent{
+		Timestamp: at.Unix(),
+		Actions: []Action{
+			{Type: ActionTypeTonTransfer, TonTransfer: NewOptTonTransferAction(TonTransferAction{Amount: 2_000_000_000})},
+			{Type: ActionTypeJettonTransfer, JettonTransfer: NewOptJettonTransferAction(JettonTransferAction{
+				Amount: "10000000",
+				Jetton: JettonPreview{Address: usdt, Symbol: "USD₮", Decimals: 6},
+			})},
+			// a jetton without chart points is skipped.
+			{Type: ActionTypeJettonTransfer, JettonTransfer: NewOptJettonTransferAction(JettonTransferAction{
+				Amount: "1",
+				Jetton: JettonPreview{Address: unlisted, Symbol: "NEW", Decimals: 9},
+			})},
+		},
+	}
+	values, err := oracle.ConvertAccountEvent(ctx, event, "eur")
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+	require.InDelta(t, 2*price, values[0].Value, 1e-6)
+	require.Equal(t, big.NewInt(10_000_000), values[1].Amount)
+	require.InDelta(t, 9.0, values[1].Value, 1e-9)
+
+	// windows shorter than a second fall back to the default.
+	price, err = NewPriceOracle(client, WithPriceOracleWindow(time.Millisecond)).Price(ctx, gramToken, "eur", at)
+	require.NoError(t, err)
+	require.InDelta(t, float64(day)/3600+10.5, price, 1e-9)
+
+	_, err = interpolatePrice([]chartPoint{{time: 100, price: 1}}, 100+3600*48, 3600*24)
+	require.ErrorIs(t, err, ErrNoPrice)
+}
+
+func TestActionAssets(t *testing.T) {
+	buyer, seller := "0:1111111111111111111111111111111111111111111111111111111111111111", "0:2222222222222222222222222222222222222222222222222222222222222222"
+	usdt := "0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe"
+	assets := actionAssets(Action{Type: ActionTypeNftPurchase, NftPurchase: NewOptNftPurchaseAction(NftPurchaseAction{
+		Amount: Price{CurrencyType: CurrencyTypeJetton, Value: "5000000", Decimals: 6, TokenName: "USD₮", Jetton: NewOptString(usdt)},
+		Nft:    NftItem{Address: "0:33"},
+		Seller: AccountAddress{Address: seller},
+		Buyer:  AccountAddress{Address: buyer},
+	})})
+	require.Len(t, assets, 2)
+	require.Equal(t, actionAsset{Token: usdt, Symbol: "USD₮", Amount: big.NewInt(5_000_000), Decimals: 6, Sender: buyer, Recipient: seller}, assets[0])
+	require.Equal(t, actionAsset{Nft: "0:33", Symbol: "NFT", Amount: big.NewInt(1), Sender: seller, Recipient: buyer}, assets[1])
+
+	assets = actionAssets(Action{Type: ActionTypeAuctionBid, AuctionBid: NewOptAuctionBidAction(AuctionBidAction{
+		Amount:  Price{CurrencyType: CurrencyTypeNative, Value: "1000000000", Decimals: 9, TokenName: "TON"},
+		Bidder:  AccountAddress{Address: buyer},
+		Auction: AccountAddress{Address: seller},
+	})})
+	require.Equal(t, []actionAsset{{Token: gramToken, Symbol: "TON", Amount: big.NewInt(1_000_000_000), Decimals: tonDecimals, Sender: buyer, Recipient: seller}}, assets)
+
+	// prices in fiat don't move assets on chain.
+	require.Empty(t, actionAssets(Action{Type: ActionTypePurchase, Purchase: NewOptPurchaseAction(PurchaseAction{
+		Amount: Price{CurrencyType: CurrencyTypeFiat, Value: "10"},
+	})}))
+}
@@ -0,0 +1,337 @@
+package tonapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ht "github.com/ogen-go/ogen/http"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitTier describes the request budget of a tonapi plan.
+// Check your plan at https://tonconsole.com to get the actual numbers.
+type RateLimitTier struct {
+	Name string
+	// RPS is the number of requests per second allowed for all endpoints.
+	RPS   float64
+	Burst int
+	// HeavyRPS is a separate budget for heavy endpoints: emulation, get methods, gasless estimation and bulk requests.
+	// Heavy requests are counted against both budgets.
+	HeavyRPS   float64
+	HeavyBurst int
+}
+
+var (
+	// TierAnonymous is a budget of requests without an API key.
+	TierAnonymous = RateLimitTier{Name: "anonymous", RPS: 1, Burst: 1, HeavyRPS: 1, HeavyBurst: 1}
+	// TierLite is a budget of the Lite plan.
+	TierLite = RateLimitTier{Name: "lite", RPS: 10, Burst: 10, HeavyRPS: 5, HeavyBurst: 5}
+	// TierPro is a budget of the Pro plan.
+	TierPro = RateLimitTier{Name: "pro", RPS: 100, Burst: 100, HeavyRPS: 50, HeavyBurst: 50}
+)
+
+// RequestPriority defines the order in which requests waiting for the rate limiter are let through.
+type RequestPriority int
+
+const (
+	// PriorityLow is for background work like crawling that can wait.
+	PriorityLow RequestPriority = iota
+	// PriorityNormal is the default priority.
+	PriorityNormal
+	// PriorityHigh is for latency-sensitive requests. Sending messages to the blockchain always has this priority.
+	PriorityHigh
+
+	numPriorities = 3
+)
+
+type priorityKey struct{}
+
+// WithRequestPriority returns a context that makes RateLimiter let requests through with the given priority.
+func WithRequestPriority(ctx context.Context, priority RequestPriority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func requestPriority(req *http.Request) RequestPriority {
+	if isSendRequest(req) {
+		return PriorityHigh
+	}
+	if p, ok := req.Context().Value(priorityKey{}).(RequestPriority); ok && p >= PriorityLow && p <= PriorityHigh {
+		return p
+	}
+	return PriorityNormal
+}
+
+// isHeavyRequest returns true if the request hits an endpoint with a separate budget.
+func isHeavyRequest(req *http.Request) bool {
+	path := req.URL.Path
+	return strings.HasSuffix(path, "/_bulk") ||
+		strings.HasSuffix(path, "/emulate") ||
+		strings.Contains(path, "/methods/") ||
+		strings.HasPrefix(path, "/v2/gasless/estimate/")
+}
+
+// RateLimiter is a client-side rate limiter aware of tonapi tiers.
+//
+// A single RateLimiter can be shared across multiple Client instances using the same API key,
+// so all of them stay within the budget of the key together:
+//
+//	limiter := tonapi.NewRateLimiter(tonapi.TierLite)
+//	client, err := tonapi.NewClient(tonapi.TonApiURL, tonapi.WithToken(token), tonapi.WithClient(limiter.Wrap(http.DefaultClient)))
+//
+// When several requests are waiting, requests with a higher priority are let through first (see WithRequestPriority).
+// When tonapi responds with 429 Too Many Requests, the limiter halves its rate and honors the Retry-After header,
+// then gradually restores the rate while responses are successful (2xx).
+type RateLimiter struct {
+	recoveryInterval time.Duration
+
+	general *limiterBucket
+	heavy   *limiterBucket
+
+	// mu protects the fields below.
+	mu           sync.Mutex
+	tier         RateLimitTier
+	factor       float64
+	lastAdjusted time.Time
+}
+
+type RateLimiterOptions struct {
+	recoveryInterval time.Duration
+}
+
+type RateLimiterOption func(*RateLimiterOptions)
+
+// WithRateLimiterRecoveryInterval configures how often the rate is increased back after being throttled.
+// The default is 5 seconds.
+func WithRateLimiterRecoveryInterval(interval time.Duration) RateLimiterOption {
+	return func(o *RateLimiterOptions) {
+		o.recoveryInterval = interval
+	}
+}
+
+// NewRateLimiter returns a new RateLimiter with the budget of the given tier.
+func NewRateLimiter(tier RateLimitTier, opts ...RateLimiterOption) *RateLimiter {
+	options := &RateLimiterOptions{
+		recoveryInterval: 5 * time.Second,
+	}
+	for _, o := range opts {
+		o(options)
+	}
+	if tier.HeavyRPS == 0 {
+		tier.HeavyRPS, tier.HeavyBurst = tier.RPS, tier.Burst
+	}
+	return &RateLimiter{
+		recoveryInterval: options.recoveryInterval,
+		general:          newLimiterBucket(tier.RPS, tier.Burst),
+		heavy:            newLimiterBucket(tier.HeavyRPS, tier.HeavyBurst),
+		tier:             tier,
+		factor:           1,
+	}
+}
+
+// Wrap returns an HTTP client waiting for the limiter before sending each request with next.
+// Use it with WithClient.
+func (l *RateLimiter) Wrap(next ht.Client) ht.Client {
+	return &rateLimitedClient{limiter: l, next: next}
+}
+
+// SetTier changes the budget, for example, after upgrading the plan.
+func (l *RateLimiter) SetTier(tier RateLimitTier) {
+	if tier.HeavyRPS == 0 {
+		tier.HeavyRPS, tier.HeavyBurst = tier.RPS, tier.Burst
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tier = tier
+	l.applyLocked()
+}
+
+// Tier returns the current budget.
+func (l *RateLimiter) Tier() RateLimitTier {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.tier
+}
+
+// Limit returns the current general rate which can be lower than the tier's RPS after 429 responses.
+func (l *RateLimiter) Limit() rate.Limit {
+	return l.general.limiter.Limit()
+}
+
+// Wait blocks until a request of the given kind and priority can be sent or the context is done.
+func (l *RateLimiter) Wait(ctx context.Context, heavy bool, priority RequestPriority) error {
+	if heavy {
+		if err := l.heavy.wait(ctx, priority); err != nil {
+			return err
+		}
+	}
+	return l.general.wait(ctx, priority)
+}
+
+// throttled slows the limiter down after a 429 response.
+func (l *RateLimiter) throttled(retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.factor /= 2
+	if l.factor < 0.05 {
+		l.factor = 0.05
+	}
+	l.lastAdjusted = time.Now()
+	l.applyLocked()
+	if retryAfter > 0 {
+		l.general.pause(retryAfter)
+		l.heavy.pause(retryAfter)
+	}
+}
+
+// succeeded gradually restores the rate after the limiter was slowed down.
+func (l *RateLimiter) succeeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.factor >= 1 || time.Since(l.lastAdjusted) < l.recoveryInterval {
+		return
+	}
+	l.factor += 0.1
+	if l.factor > 1 {
+		l.factor = 1
+	}
+	l.lastAdjusted = time.Now()
+	l.applyLocked()
+}
+
+func (l *RateLimiter) applyLocked() {
+	l.general.limiter.SetLimit(rate.Limit(l.tier.RPS * l.factor))
+	l.heavy.limiter.SetLimit(rate.Limit(l.tier.HeavyRPS * l.factor))
+}
+
+type rateLimitedClient struct {
+	limiter *RateLimiter
+	next    ht.Client
+}
+
+func (c *rateLimitedClient) Do(req *http.Request) (*http.Response, error) {
+	if err := c.limiter.Wait(req.Context(), isHeavyRequest(req), requestPriority(req)); err != nil {
+		return nil, err
+	}
+	resp, err := c.next.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		c.limiter.throttled(parseRetryAfter(resp.Header.Get("Retry-After")))
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		c.limiter.succeeded()
+	}
+	return resp, nil
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// limiterBucket hands out tokens of a rate.Limiter to waiting requests in the order of their priority.
+type limiterBucket struct {
+	limiter *rate.Limiter
+
+	// mu protects the fields below.
+	mu          sync.Mutex
+	queues      [numPriorities][]chan struct{}
+	dispatching bool
+	pausedUntil time.Time
+}
+
+func newLimiterBucket(rps float64, burst int) *limiterBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiterBucket{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+}
+
+func (b *limiterBucket) wait(ctx context.Context, priority RequestPriority) error {
+	ready := make(chan struct{})
+	b.mu.Lock()
+	b.queues[priority] = append(b.queues[priority], ready)
+	if !b.dispatching {
+		b.dispatching = true
+		go b.dispatch()
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		queue := b.queues[priority]
+		for i, ch := range queue {
+			if ch == ready {
+				b.queues[priority] = append(queue[:i], queue[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// the token has been granted concurrently, let the request through.
+		return nil
+	}
+}
+
+func (b *limiterBucket) pause(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until := time.Now().Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// dispatch runs while there are waiting requests.
+// It waits for a token first and only then picks a request,
+// so a high priority request arriving meanwhile overtakes the ones already waiting.
+func (b *limiterBucket) dispatch() {
+	for {
+		b.mu.Lock()
+		if b.empty() {
+			b.dispatching = false
+			b.mu.Unlock()
+			return
+		}
+		pause := time.Until(b.pausedUntil)
+		b.mu.Unlock()
+
+		if pause > 0 {
+			time.Sleep(pause)
+		}
+		time.Sleep(b.limiter.Reserve().Delay())
+
+		b.mu.Lock()
+		for p := numPriorities - 1; p >= 0; p-- {
+			if len(b.queues[p]) > 0 {
+				close(b.queues[p][0])
+				b.queues[p] = b.queues[p][1:]
+				break
+			}
+		}
+		b.mu.Unlock()
+	}
+}
+
+func (b *limiterBucket) empty() bool {
+	for _, queue := range b.queues {
+		if len(queue) > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package tonapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestLimiterBucketPriority(t *testing.T) {
+	bucket := newLimiterBucket(50, 1)
+	// requests queue up while the bucket is paused and are let through by priority.
+	bucket.pause(100 * time.Millisecond)
+	var (
+		mu    sync.Mutex
+		order []RequestPriority
+		wg    sync.WaitGroup
+	)
+	for _, p := range []RequestPriority{PriorityLow, PriorityNormal, PriorityLow, PriorityHigh} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, bucket.wait(context.Background(), p))
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, []RequestPriority{PriorityHigh, PriorityNormal, PriorityLow, PriorityLow}, order)
+
+	// a canceled request leaves the queue.
+	bucket.pause(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, bucket.wait(ctx, PriorityHigh), context.DeadlineExceeded)
+	bucket.mu.Lock()
+	require.True(t, bucket.empty())
+	bucket.mu.Unlock()
+}
+
+func TestRequestPriority(t *testing.T) {
+	request := func(ctx context.Context, method, path string) *http.Request {
+		req, err := http.NewRequestWithContext(ctx, method, "https://tonapi.io"+path, nil)
+		require.NoError(t, err)
+		return req
+	}
+	ctx := context.Background()
+	require.Equal(t, PriorityNormal, requestPriority(request(ctx, http.MethodGet, "/v2/accounts/x")))
+	require.Equal(t, PriorityLow, requestPriority(request(WithRequestPriority(ctx, PriorityLow), http.MethodGet, "/v2/accounts/x")))
+	require.Equal(t, PriorityNormal, requestPriority(request(WithRequestPriority(ctx, RequestPriority(7)), http.MethodGet, "/v2/accounts/x")))
+	require.Equal(t, PriorityHigh, requestPriority(request(WithRequestPriority(ctx, PriorityLow), http.MethodPost, "/v2/blockchain/message")))
+}
+
+func TestRateLimiterHeavyBudget(t *testing.T) {
+	for path, heavy := range map[string]bool{
+		"/v2/accounts/_bulk":                      true,
+		"/v2/events/emulate":                      true,
+		"/v2/blockchain/accounts/x/methods/seqno": true,
+		"/v2/gasless/estimate/0:11":               true,
+		"/v2/accounts/x/events":                   false,
+	} {
+		require.Equal(t, heavy, isHeavyRequest(&http.Request{URL: &url.URL{Path: path}}), path)
+	}
+
+	limiter := NewRateLimiter(RateLimitTier{RPS: 1000, Burst: 10, HeavyRPS: 10, HeavyBurst: 1})
+	ctx := context.Background()
+	require.NoError(t, limiter.Wait(ctx, true, PriorityNormal))
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, limiter.Wait(ctx, false, PriorityNormal))
+	}
+	require.Less(t, time.Since(start), 50*time.Millisecond)
+	require.NoError(t, limiter.Wait(ctx, true, PriorityNormal))
+	require.Greater(t, time.Since(start), 50*time.Millisecond)
+
+	// without a heavy budget, the general one is used.
+	require.Equal(t, 5.0, NewRateLimiter(RateLimitTier{RPS: 5, Burst: 2}).Tier().HeavyRPS)
+}
+
+func TestRateLimiterThrottling(t *testing.T) {
+	var statuses = []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusOK}
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statuses[requests])
+		requests++
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	limiter := NewRateLimiter(TierLite, WithRateLimiterRecoveryInterval(0))
+	client, err := NewClient(srv.URL, &Security{}, WithClient(limiter.Wrap(http.DefaultClient)))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.Request(ctx, http.MethodGet, "v2/status", nil, nil)
+	require.Error(t, err)
+	require.Equal(t, rate.Limit(5), limiter.Limit())
+	// server errors don't restore the rate.
+	_, err = client.Request(ctx, http.MethodGet, "v2/status", nil, nil)
+	require.Error(t, err)
+	require.Equal(t, rate.Limit(5), limiter.Limit())
+	_, err = client.Request(ctx, http.MethodGet, "v2/status", nil, nil)
+	require.NoError(t, err)
+	require.InDelta(t, 6, float64(limiter.Limit()), 1e-9)
+	require.Equal(t, 3, requests)
+
+	// the rate is never throttled below 5% of the tier.
+	for i := 0; i < 10; i++ {
+		limiter.throttled(0)
+	}
+	require.InDelta(t, 0.5, float64(limiter.Limit()), 1e-9)
+	limiter.SetTier(TierPro)
+	require.InDelta(t, 5, float64(limiter.Limit()), 1e-9)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	require.Zero(t, parseRetryAfter(""))
+	require.Zero(t, parseRetryAfter("soon"))
+	require.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	after := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	require.Greater(t, after, 58*time.Second)
+	require.LessOrEqual(t, after, time.Minute)
+
+	// Retry-After pauses both budgets.
+	limiter := NewRateLimiter(TierPro)
+	limiter.throttled(100 * time.Millisecond)
+	start := time.Now()
+	require.NoError(t, limiter.Wait(context.Background(), true, PriorityHigh))
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
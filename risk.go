@@ -0,0 +1,284 @@
+package tonapi
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tonkeeper/tongo/ton"
+)
+
+// RiskViolationKind identifies the rule of a RiskPolicy that was violated.
+type RiskViolationKind string
+
+const (
+	RiskViolationDrain        RiskViolationKind = "drain"
+	RiskViolationTonLimit     RiskViolationKind = "ton_limit"
+	RiskViolationJettonLimit  RiskViolationKind = "jetton_limit"
+	RiskViolationNft          RiskViolationKind = "nft"
+	RiskViolationFiatLimit    RiskViolationKind = "fiat_limit"
+	RiskViolationCounterparty RiskViolationKind = "counterparty"
+	RiskViolationActionType   RiskViolationKind = "action_type"
+	// RiskViolationInvalidAddress is an address of the policy or of the event that does not parse,
+	// so the counterparty rules cannot be checked.
+	RiskViolationInvalidAddress RiskViolationKind = "invalid_address"
+	// RiskViolationUnclassifiedAction is an action whose counterparty is unknown to the policy.
+	RiskViolationUnclassifiedAction RiskViolationKind = "unclassified_action"
+)
+
+// RiskViolation describes a single rule of a RiskPolicy that was violated.
+type RiskViolation struct {
+	Kind RiskViolationKind `json:"kind"`
+	// Subject is the jetton, NFT, account or action type the violation is about, if any.
+	Subject string `json:"subject,omitempty"`
+	// Limit and Actual are the configured limit and the value at risk, if the rule is a limit.
+	Limit  string `json:"limit,omitempty"`
+	Actual string `json:"actual,omitempty"`
+}
+
+func (v RiskViolation) String() string {
+	s := string(v.Kind)
+	if v.Subject != "" {
+		s += " " + v.Subject
+	}
+	if v.Limit != "" || v.Actual != "" {
+		s += fmt.Sprintf(": %v exceeds %v", v.Actual, v.Limit)
+	}
+	return s
+}
+
+// RiskPolicyError is returned by RiskPolicy.Check when the consequences violate the policy.
+type RiskPolicyError struct {
+	Violations []RiskViolation
+}
+
+func (e *RiskPolicyError) Error() string {
+	items := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		items = append(items, v.String())
+	}
+	return "risk policy violated: " + strings.Join(items, "; ")
+}
+
+// RiskPolicy is a declarative set of rules evaluated against the Risk reported by emulation or multisig orders.
+// The zero value forbids everything that can be at risk: TON, jettons, NFTs and draining the wallet.
+// A policy can be loaded from JSON:
+//
+//	{
+//	  "max_gram": 10000000000,
+//	  "max_jettons": {"0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe": "1000000000"},
+//	  "max_total_equivalent": 5000,
+//	  "allowed_counterparties": ["0:97146a46acc2654127947f14c4a4b14273e954f78bc017790b41208b0043200b"],
+//	  "blocked_action_types": ["NftPurchase", "AuctionBid"]
+//	}
+type RiskPolicy struct {
+	// MaxGram is the maximum amount of nanotons that may leave the wallet.
+	MaxGram int64 `json:"max_gram"`
+	// MaxJettons maps jetton master addresses to the maximum quantity of the jetton that may leave the wallet.
+	// Jettons not listed are forbidden.
+	MaxJettons map[string]string `json:"max_jettons,omitempty"`
+	// AllowNfts allows NFTs to be transferred out of the wallet.
+	AllowNfts bool `json:"allow_nfts"`
+	// AllowTransferAllRemainingBalance allows messages which can sweep the whole wallet balance.
+	AllowTransferAllRemainingBalance bool `json:"allow_transfer_all_remaining_balance"`
+	// MaxTotalEquivalent limits the fiat equivalent of all assets at risk. Zero means no limit.
+	// The currency is the one requested when emulating.
+	MaxTotalEquivalent float64 `json:"max_total_equivalent,omitempty"`
+	// AllowedCounterparties lists accounts the wallet can send TON, jettons, NFTs to or call.
+	// Empty means any counterparty is allowed. Counterparties are only known when evaluating MessageConsequences.
+	// The rule fails closed: addresses that do not parse and actions whose counterparty is unknown are violations.
+	AllowedCounterparties []string `json:"allowed_counterparties,omitempty"`
+	// BlockedActionTypes lists action types that must not appear in the emulated event.
+	BlockedActionTypes []ActionType `json:"blocked_action_types,omitempty"`
+}
+
+// Check evaluates the consequences and returns a *RiskPolicyError if the policy is violated.
+func (p *RiskPolicy) Check(consequences *MessageConsequences) error {
+	if violations := p.Evaluate(consequences); len(violations) > 0 {
+		return &RiskPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Evaluate returns all violations of the policy by the emulated message:
+// the risk limits, the counterparties of outgoing transfers and the action types of the event.
+func (p *RiskPolicy) Evaluate(consequences *MessageConsequences) []RiskViolation {
+	violations := p.EvaluateRisk(consequences.Risk)
+	for _, blocked := range p.BlockedActionTypes {
+		for _, action := range consequences.Event.Actions {
+			if action.Type == blocked {
+				violations = append(violations, RiskViolation{Kind: RiskViolationActionType, Subject: string(action.Type)})
+				break
+			}
+		}
+	}
+	if len(p.AllowedCounterparties) == 0 {
+		return violations
+	}
+	allowed := make(map[ton.AccountID]struct{}, len(p.AllowedCounterparties))
+	for _, address := range p.AllowedCounterparties {
+		account, err := ton.ParseAccountID(address)
+		if err != nil {
+			violations = append(violations, RiskViolation{Kind: RiskViolationInvalidAddress, Subject: address})
+			continue
+		}
+		allowed[account] = struct{}{}
+	}
+	wallet, err := ton.ParseAccountID(consequences.Event.Account.Address)
+	if err != nil {
+		return append(violations, RiskViolation{Kind: RiskViolationInvalidAddress, Subject: consequences.Event.Account.Address})
+	}
+	reported := map[RiskViolation]struct{}{}
+	report := func(v RiskViolation) {
+		if _, ok := reported[v]; !ok {
+			reported[v] = struct{}{}
+			violations = append(violations, v)
+		}
+	}
+	for _, action := range consequences.Event.Actions {
+		from, to, ok := actionParties(action)
+		if !ok {
+			report(RiskViolation{Kind: RiskViolationUnclassifiedAction, Subject: string(action.Type)})
+			continue
+		}
+		if from == "" {
+			// the action moves nothing out of an account, or its initiator is unknown and so not the wallet.
+			continue
+		}
+		sender, err := ton.ParseAccountID(from)
+		if err != nil {
+			report(RiskViolation{Kind: RiskViolationInvalidAddress, Subject: from})
+			continue
+		}
+		if sender != wallet {
+			continue
+		}
+		counterparty, err := ton.ParseAccountID(to)
+		if err != nil {
+			report(RiskViolation{Kind: RiskViolationInvalidAddress, Subject: to})
+			continue
+		}
+		if _, ok := allowed[counterparty]; !ok {
+			report(RiskViolation{Kind: RiskViolationCounterparty, Subject: counterparty.ToRaw()})
+		}
+	}
+	return violations
+}
+
+// EvaluateMultisigOrder returns all violations of the policy by the risk of a multisig order.
+// Orders carry no actions, so only the risk limits are checked.
+func (p *RiskPolicy) EvaluateMultisigOrder(order *MultisigOrder) []RiskViolation {
+	return p.EvaluateRisk(order.Risk)
+}
+
+// EvaluateRisk returns all violations of the risk limits of the policy.
+func (p *RiskPolicy) EvaluateRisk(risk Risk) []RiskViolation {
+	var violations []RiskViolation
+	if risk.TransferAllRemainingBalance && !p.AllowTransferAllRemainingBalance {
+		violations = append(violations, RiskViolation{Kind: RiskViolationDrain})
+	}
+	if risk.Gram > p.MaxGram {
+		violations = append(violations, RiskViolation{
+			Kind:   RiskViolationTonLimit,
+			Limit:  fmt.Sprintf("%d", p.MaxGram),
+			Actual: fmt.Sprintf("%d", risk.Gram),
+		})
+	}
+	limits := make(map[ton.AccountID]string, len(p.MaxJettons))
+	for address, limit := range p.MaxJettons {
+		if master, err := ton.ParseAccountID(address); err == nil {
+			limits[master] = limit
+		}
+	}
+	for _, jetton := range risk.Jettons {
+		violation := RiskViolation{Kind: RiskViolationJettonLimit, Subject: jetton.Jetton.Address, Limit: "0", Actual: jetton.Quantity}
+		master, err := ton.ParseAccountID(jetton.Jetton.Address)
+		if err == nil {
+			if limit, ok := limits[master]; ok {
+				violation.Limit = limit
+			}
+		}
+		quantity, ok1 := new(big.Int).SetString(jetton.Quantity, 10)
+		limit, ok2 := new(big.Int).SetString(violation.Limit, 10)
+		if ok1 && ok2 && quantity.Cmp(limit) <= 0 {
+			continue
+		}
+		violations = append(violations, violation)
+	}
+	if !p.AllowNfts {
+		for _, nft := range risk.Nfts {
+			violations = append(violations, RiskViolation{Kind: RiskViolationNft, Subject: nft.Address})
+		}
+	}
+	if total, ok := risk.TotalEquivalent.Get(); ok && p.MaxTotalEquivalent > 0 && float64(total) > p.MaxTotalEquivalent {
+		violations = append(violations, RiskViolation{
+			Kind:   RiskViolationFiatLimit,
+			Limit:  fmt.Sprintf("%v", p.MaxTotalEquivalent),
+			Actual: fmt.Sprintf("%v", total),
+		})
+	}
+	return violations
+}
+
+// actionParties returns the account initiating the action and the account receiving value from it or called by it.
+// from is empty for actions that move no value out of an account and for actions whose initiator is not known.
+// ok is false for action types the policy cannot classify.
+func actionParties(action Action) (from, to string, ok bool) {
+	optional := func(address OptAccountAddress) string {
+		return address.Value.Address
+	}
+	switch action.Type {
+	case ActionTypeTonTransfer:
+		a := action.TonTransfer.Value
+		return a.Sender.Address, a.Recipient.Address, true
+	case ActionTypeExtraCurrencyTransfer:
+		a := action.ExtraCurrencyTransfer.Value
+		return a.Sender.Address, a.Recipient.Address, true
+	case ActionTypeJettonTransfer:
+		a := action.JettonTransfer.Value
+		return optional(a.Sender), optional(a.Recipient), true
+	case ActionTypeFlawedJettonTransfer:
+		a := action.FlawedJettonTransfer.Value
+		return optional(a.Sender), optional(a.Recipient), true
+	case ActionTypeNftItemTransfer:
+		a := action.NftItemTransfer.Value
+		return optional(a.Sender), optional(a.Recipient), true
+	case ActionTypeSmartContractExec:
+		a := action.SmartContractExec.Value
+		return a.Executor.Address, a.Contract.Address, true
+	case ActionTypeJettonSwap:
+		a := action.JettonSwap.Value
+		return a.UserWallet.Address, a.Router.Address, true
+	case ActionTypeNftPurchase:
+		a := action.NftPurchase.Value
+		return a.Buyer.Address, a.Seller.Address, true
+	case ActionTypeAuctionBid:
+		a := action.AuctionBid.Value
+		return a.Bidder.Address, a.Auction.Address, true
+	case ActionTypeDepositStake:
+		a := action.DepositStake.Value
+		return a.Staker.Address, a.Pool.Address, true
+	case ActionTypeWithdrawStakeRequest:
+		a := action.WithdrawStakeRequest.Value
+		return a.Staker.Address, a.Pool.Address, true
+	case ActionTypeSubscribe:
+		a := action.Subscribe.Value
+		return a.Subscriber.Address, a.Beneficiary.Address, true
+	case ActionTypeUnSubscribe:
+		a := action.UnSubscribe.Value
+		return a.Subscriber.Address, a.Beneficiary.Address, true
+	case ActionTypeDomainRenew:
+		a := action.DomainRenew.Value
+		return a.Renewer.Address, a.ContractAddress, true
+	case ActionTypePurchase:
+		a := action.Purchase.Value
+		return a.Source.Address, a.Destination.Address, true
+	case ActionTypeGasRelay:
+		a := action.GasRelay.Value
+		return a.Relayer.Address, a.Target.Address, true
+	case ActionTypeContractDeploy, ActionTypeJettonMint, ActionTypeWithdrawStake, ActionTypeElectionsRecoverStake:
+		// value only comes into accounts.
+		return "", "", true
+	}
+	return "", "", false
+}
@@ -0,0 +1,119 @@
+package tonapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiskPolicy(t *testing.T) {
+	wallet := "0:1111111111111111111111111111111111111111111111111111111111111111"
+	friend := "0:2222222222222222222222222222222222222222222222222222222222222222"
+	stranger := "0:3333333333333333333333333333333333333333333333333333333333333333"
+	usdt := "0:b113a994b5024a16719f69139328eb759596c38a25f59028b146fecdc3621dfe"
+	other := "0:4444444444444444444444444444444444444444444444444444444444444444"
+
+	var policy RiskPolicy
+	err := json.Unmarshal([]byte(`{
+		"max_gram": 1000000000,
+		"max_jettons": {"`+usdt+`": "100000000"},
+		"max_total_equivalent": 150,
+		"allowed_counterparties": ["`+friend+`"],
+		"blocked_action_types": ["NftPurchase"]
+	}`), &policy)
+	require.NoError(t, err)
+
+	transfer := func(to string) Action {
+		return Action{
+			Type: ActionTypeTonTransfer,
+			TonTransfer: NewOptTonTransferAction(TonTransferAction{
+				Sender:    AccountAddress{Address: wallet},
+				Recipient: AccountAddress{Address: to},
+			}),
+		}
+	}
+	consequences := &MessageConsequences{
+		Risk: Risk{
+			Gram:            500_000_000,
+			Jettons:         []JettonQuantity{{Quantity: "100000000", Jetton: JettonPreview{Address: usdt}}},
+			TotalEquivalent: NewOptFloat32(100),
+		},
+		Event: AccountEvent{
+			Account: AccountAddress{Address: wallet},
+			Actions: []Action{transfer(friend)},
+		},
+	}
+	require.NoError(t, policy.Check(consequences))
+
+	consequences.Risk = Risk{
+		TransferAllRemainingBalance: true,
+		Gram:                        2_000_000_000,
+		Jettons: []JettonQuantity{
+			{Quantity: "100000001", Jetton: JettonPreview{Address: usdt}},
+			{Quantity: "1", Jetton: JettonPreview{Address: other}},
+		},
+		Nfts:            []NftItem{{Address: other}},
+		TotalEquivalent: NewOptFloat32(200),
+	}
+	consequences.Event.Actions = append(consequences.Event.Actions,
+		transfer(stranger), transfer(stranger), Action{Type: ActionTypeNftPurchase, NftPurchase: NewOptNftPurchaseAction(NftPurchaseAction{
+			Buyer:  AccountAddress{Address: wallet},
+			Seller: AccountAddress{Address: friend},
+		})})
+
+	var kinds []RiskViolationKind
+	for _, v := range policy.Evaluate(consequences) {
+		kinds = append(kinds, v.Kind)
+	}
+	require.Equal(t, []RiskViolationKind{
+		RiskViolationDrain,
+		RiskViolationTonLimit,
+		RiskViolationJettonLimit,
+		RiskViolationJettonLimit,
+		RiskViolationNft,
+		RiskViolationFiatLimit,
+		RiskViolationActionType,
+		RiskViolationCounterparty,
+	}, kinds)
+
+	order := &MultisigOrder{Risk: Risk{Gram: 2_000_000_000}}
+	require.Equal(t, []RiskViolation{{Kind: RiskViolationTonLimit, Limit: "1000000000", Actual: "2000000000"}},
+		policy.EvaluateMultisigOrder(order))
+
+	// the counterparty rule fails closed.
+	evaluate := func(policy RiskPolicy, account string, actions ...Action) []RiskViolation {
+		return policy.Evaluate(&MessageConsequences{Event: AccountEvent{Account: AccountAddress{Address: account}, Actions: actions}})
+	}
+	allowFriend := RiskPolicy{AllowedCounterparties: []string{friend}}
+	require.Equal(t, []RiskViolation{{Kind: RiskViolationInvalidAddress, Subject: "not an address"}},
+		evaluate(RiskPolicy{AllowedCounterparties: []string{friend, "not an address"}}, wallet, transfer(friend)))
+	require.Equal(t, []RiskViolation{{Kind: RiskViolationInvalidAddress, Subject: "garbage"}},
+		evaluate(allowFriend, "garbage", transfer(stranger)))
+	require.Equal(t, []RiskViolation{{Kind: RiskViolationInvalidAddress, Subject: "garbage"}},
+		evaluate(allowFriend, wallet, transfer("garbage")))
+	require.Equal(t, []RiskViolation{{Kind: RiskViolationUnclassifiedAction, Subject: string(ActionTypeElectionsDepositStake)}},
+		evaluate(allowFriend, wallet, Action{Type: ActionTypeElectionsDepositStake}, Action{Type: ActionTypeElectionsDepositStake}))
+
+	// value-moving actions other than transfers are checked too.
+	swap := Action{Type: ActionTypeJettonSwap, JettonSwap: NewOptJettonSwapAction(JettonSwapAction{
+		UserWallet: AccountAddress{Address: wallet},
+		Router:     AccountAddress{Address: stranger},
+	})}
+	stake := Action{Type: ActionTypeDepositStake, DepositStake: NewOptDepositStakeAction(DepositStakeAction{
+		Staker: AccountAddress{Address: wallet},
+		Pool:   AccountAddress{Address: other},
+	})}
+	purchase := Action{Type: ActionTypeNftPurchase, NftPurchase: NewOptNftPurchaseAction(NftPurchaseAction{
+		Buyer:  AccountAddress{Address: wallet},
+		Seller: AccountAddress{Address: friend},
+	})}
+	incoming := Action{Type: ActionTypeWithdrawStake, WithdrawStake: NewOptWithdrawStakeAction(WithdrawStakeAction{
+		Staker: AccountAddress{Address: wallet},
+		Pool:   AccountAddress{Address: other},
+	})}
+	require.Equal(t, []RiskViolation{
+		{Kind: RiskViolationCounterparty, Subject: stranger},
+		{Kind: RiskViolationCounterparty, Subject: other},
+	}, evaluate(allowFriend, wallet, swap, stake, purchase, incoming))
+}
@@ -0,0 +1,53 @@
+package tonapi
+
+import (
+	"strings"
+)
+
+// operationRoute binds an operation to its id, HTTP method and path template from api/openapi.yml.
+// The routes of all operations of Invoker are generated into routes_gen.go.
+type operationRoute struct {
+	Operation OperationName
+	ID        string
+	Method    string
+	Path      string
+}
+
+// matchOperation returns the operation serving the given request path.
+// When several path templates match, the one with more literal segments wins,
+// so "/v2/accounts/_bulk" is not mistaken for "/v2/accounts/{account_id}".
+func matchOperation(method, path string) (operationRoute, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var best operationRoute
+	bestLiterals := -1
+	for _, route := range operationRoutes {
+		if route.Method != method {
+			continue
+		}
+		if literals, ok := matchPathTemplate(route.Path, segments); ok && literals > bestLiterals {
+			best, bestLiterals = route, literals
+		}
+	}
+	return best, bestLiterals >= 0
+}
+
+func matchPathTemplate(template string, segments []string) (int, bool) {
+	parts := strings.Split(strings.Trim(template, "/"), "/")
+	if len(parts) != len(segments) {
+		return 0, false
+	}
+	literals := 0
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if part != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
@@ -0,0 +1,126 @@
+// Code generated by specgen from api/openapi.yml, DO NOT EDIT.
+
+package tonapi
+
+import "net/http"
+
+var operationRoutes = []operationRoute{
+	{AccountDnsBackResolveOperation, "accountDnsBackResolve", http.MethodGet, "/v2/accounts/{account_id}/dns/backresolve"},
+	{AddressParseOperation, "addressParse", http.MethodGet, "/v2/address/{account_id}/parse"},
+	{BlockchainAccountInspectOperation, "blockchainAccountInspect", http.MethodGet, "/v2/blockchain/accounts/{account_id}/inspect"},
+	{DecodeMessageOperation, "decodeMessage", http.MethodPost, "/v2/message/decode"},
+	{DnsResolveOperation, "dnsResolve", http.MethodGet, "/v2/dns/{domain_name}/resolve"},
+	{DownloadBlockchainBlockBocOperation, "downloadBlockchainBlockBoc", http.MethodGet, "/v2/blockchain/blocks/{block_id}/boc"},
+	{EmulateMessageToAccountEventOperation, "emulateMessageToAccountEvent", http.MethodPost, "/v2/accounts/{account_id}/events/emulate"},
+	{EmulateMessageToEventOperation, "emulateMessageToEvent", http.MethodPost, "/v2/events/emulate"},
+	{EmulateMessageToTraceOperation, "emulateMessageToTrace", http.MethodPost, "/v2/traces/emulate"},
+	{EmulateMessageToWalletOperation, "emulateMessageToWallet", http.MethodPost, "/v2/wallet/emulate"},
+	{ExecGetMethodForBlockchainAccountOperation, "execGetMethodForBlockchainAccount", http.MethodGet, "/v2/blockchain/accounts/{account_id}/methods/{method_name}"},
+	{ExecGetMethodWithBodyForBlockchainAccountOperation, "execGetMethodWithBodyForBlockchainAccount", http.MethodPost, "/v2/blockchain/accounts/{account_id}/methods/{method_name}"},
+	{GaslessConfigOperation, "gaslessConfig", http.MethodGet, "/v2/gasless/config"},
+	{GaslessEstimateOperation, "gaslessEstimate", http.MethodPost, "/v2/gasless/estimate/{master_id}"},
+	{GaslessSendOperation, "gaslessSend", http.MethodPost, "/v2/gasless/send"},
+	{GetAccountOperation, "getAccount", http.MethodGet, "/v2/accounts/{account_id}"},
+	{GetAccountDefiAssetsOperation, "getAccountDefiAssets", http.MethodGet, "/v2/accounts/{account_id}/defi/assets"},
+	{GetAccountDiffOperation, "getAccountDiff", http.MethodGet, "/v2/accounts/{account_id}/diff"},
+	{GetAccountDnsExpiringOperation, "getAccountDnsExpiring", http.MethodGet, "/v2/accounts/{account_id}/dns/expiring"},
+	{GetAccountEventOperation, "getAccountEvent", http.MethodGet, "/v2/accounts/{account_id}/events/{event_id}"},
+	{GetAccountEventsOperation, "getAccountEvents", http.MethodGet, "/v2/accounts/{account_id}/events"},
+	{GetAccountExtraCurrencyHistoryByIDOperation, "getAccountExtraCurrencyHistoryByID", http.MethodGet, "/v2/accounts/{account_id}/extra-currency/{id}/history"},
+	{GetAccountInfoByStateInitOperation, "getAccountInfoByStateInit", http.MethodPost, "/v2/tonconnect/stateinit"},
+	{GetAccountJettonBalanceOperation, "getAccountJettonBalance", http.MethodGet, "/v2/accounts/{account_id}/jettons/{jetton_id}"},
+	{GetAccountJettonHistoryByIDOperation, "getAccountJettonHistoryByID", http.MethodGet, "/v2/accounts/{account_id}/jettons/{jetton_id}/history"},
+	{GetAccountJettonsBalancesOperation, "getAccountJettonsBalances", http.MethodGet, "/v2/accounts/{account_id}/jettons"},
+	{GetAccountJettonsHistoryOperation, "getAccountJettonsHistory", http.MethodGet, "/v2/accounts/{account_id}/jettons/history"},
+	{GetAccountMultisigsOperation, "getAccountMultisigs", http.MethodGet, "/v2/accounts/{account_id}/multisigs"},
+	{GetAccountNftHistoryOperation, "getAccountNftHistory", http.MethodGet, "/v2/accounts/{account_id}/nfts/history"},
+	{GetAccountNftItemsOperation, "getAccountNftItems", http.MethodGet, "/v2/accounts/{account_id}/nfts"},
+	{GetAccountNominatorsPoolsOperation, "getAccountNominatorsPools", http.MethodGet, "/v2/staking/nominator/{account_id}/pools"},
+	{GetAccountPublicKeyOperation, "getAccountPublicKey", http.MethodGet, "/v2/accounts/{account_id}/publickey"},
+	{GetAccountSeqnoOperation, "getAccountSeqno", http.MethodGet, "/v2/wallet/{account_id}/seqno"},
+	{GetAccountSubscriptionsOperation, "getAccountSubscriptions", http.MethodGet, "/v2/accounts/{account_id}/subscriptions"},
+	{GetAccountTracesOperation, "getAccountTraces", http.MethodGet, "/v2/accounts/{account_id}/traces"},
+	{GetAccountsOperation, "getAccounts", http.MethodPost, "/v2/accounts/_bulk"},
+	{GetAllAuctionsOperation, "getAllAuctions", http.MethodGet, "/v2/dns/auctions"},
+	{GetAllRawShardsInfoOperation, "getAllRawShardsInfo", http.MethodGet, "/v2/liteserver/get_all_shards_info/{block_id}"},
+	{GetBlockchainAccountTransactionsOperation, "getBlockchainAccountTransactions", http.MethodGet, "/v2/blockchain/accounts/{account_id}/transactions"},
+	{GetBlockchainBlockOperation, "getBlockchainBlock", http.MethodGet, "/v2/blockchain/blocks/{block_id}"},
+	{GetBlockchainBlockTransactionsOperation, "getBlockchainBlockTransactions", http.MethodGet, "/v2/blockchain/blocks/{block_id}/transactions"},
+	{GetBlockchainConfigOperation, "getBlockchainConfig", http.MethodGet, "/v2/blockchain/config"},
+	{GetBlockchainConfigFromBlockOperation, "getBlockchainConfigFromBlock", http.MethodGet, "/v2/blockchain/masterchain/{masterchain_seqno}/config"},
+	{GetBlockchainMasterchainBlocksOperation, "getBlockchainMasterchainBlocks", http.MethodGet, "/v2/blockchain/masterchain/{masterchain_seqno}/blocks"},
+	{GetBlockchainMasterchainHeadOperation, "getBlockchainMasterchainHead", http.MethodGet, "/v2/blockchain/masterchain-head"},
+	{GetBlockchainMasterchainShardsOperation, "getBlockchainMasterchainShards", http.MethodGet, "/v2/blockchain/masterchain/{masterchain_seqno}/shards"},
+	{GetBlockchainMasterchainTransactionsOperation, "getBlockchainMasterchainTransactions", http.MethodGet, "/v2/blockchain/masterchain/{masterchain_seqno}/transactions"},
+	{GetBlockchainRawAccountOperation, "getBlockchainRawAccount", http.MethodGet, "/v2/blockchain/accounts/{account_id}"},
+	{GetBlockchainRawAccountsOperation, "getBlockchainRawAccounts", http.MethodPost, "/v2/blockchain/accounts/_bulk"},
+	{GetBlockchainTransactionOperation, "getBlockchainTransaction", http.MethodGet, "/v2/blockchain/transactions/{transaction_id}"},
+	{GetBlockchainTransactionByMessageHashOperation, "getBlockchainTransactionByMessageHash", http.MethodGet, "/v2/blockchain/messages/{msg_id}/transaction"},
+	{GetBlockchainValidatorsOperation, "getBlockchainValidators", http.MethodGet, "/v2/blockchain/validators"},
+	{GetChartRatesOperation, "getChartRates", http.MethodGet, "/v2/rates/chart"},
+	{GetDnsInfoOperation, "getDnsInfo", http.MethodGet, "/v2/dns/{domain_name}"},
+	{GetDomainBidsOperation, "getDomainBids", http.MethodGet, "/v2/dns/{domain_name}/bids"},
+	{GetEventOperation, "getEvent", http.MethodGet, "/v2/events/{event_id}"},
+	{GetExtraCurrencyInfoOperation, "getExtraCurrencyInfo", http.MethodGet, "/v2/extra-currency/{id}"},
+	{GetItemsFromCollectionOperation, "getItemsFromCollection", http.MethodGet, "/v2/nfts/collections/{account_id}/items"},
+	{GetJettonAccountHistoryByIDOperation, "getJettonAccountHistoryByID", http.MethodGet, "/v2/jettons/{jetton_id}/accounts/{account_id}/history"},
+	{GetJettonHoldersOperation, "getJettonHolders", http.MethodGet, "/v2/jettons/{account_id}/holders"},
+	{GetJettonInfoOperation, "getJettonInfo", http.MethodGet, "/v2/jettons/{account_id}"},
+	{GetJettonInfosByAddressesOperation, "getJettonInfosByAddresses", http.MethodPost, "/v2/jettons/_bulk"},
+	{GetJettonTransferPayloadOperation, "getJettonTransferPayload", http.MethodGet, "/v2/jettons/{jetton_id}/transfer/{account_id}/payload"},
+	{GetJettonsOperation, "getJettons", http.MethodGet, "/v2/jettons"},
+	{GetJettonsEventsOperation, "getJettonsEvents", http.MethodGet, "/v2/events/{event_id}/jettons"},
+	{GetLibraryByHashOperation, "getLibraryByHash", http.MethodGet, "/v2/blockchain/libraries/{hash}"},
+	{GetMarketsRatesOperation, "getMarketsRates", http.MethodGet, "/v2/rates/markets"},
+	{GetMigrationWalletsOperation, "getMigrationWallets", http.MethodPost, "/v2/migration/wallets"},
+	{GetMultisigAccountOperation, "getMultisigAccount", http.MethodGet, "/v2/multisig/{account_id}"},
+	{GetMultisigOrderOperation, "getMultisigOrder", http.MethodGet, "/v2/multisig/order/{account_id}"},
+	{GetNftCollectionOperation, "getNftCollection", http.MethodGet, "/v2/nfts/collections/{account_id}"},
+	{GetNftCollectionItemsByAddressesOperation, "getNftCollectionItemsByAddresses", http.MethodPost, "/v2/nfts/collections/_bulk"},
+	{GetNftCollectionsOperation, "getNftCollections", http.MethodGet, "/v2/nfts/collections"},
+	{GetNftHistoryByIDOperation, "getNftHistoryByID", http.MethodGet, "/v2/nfts/{account_id}/history"},
+	{GetNftItemByAddressOperation, "getNftItemByAddress", http.MethodGet, "/v2/nfts/{account_id}"},
+	{GetNftItemsByAddressesOperation, "getNftItemsByAddresses", http.MethodPost, "/v2/nfts/_bulk"},
+	{GetOpenapiJsonOperation, "getOpenapiJson", http.MethodGet, "/v2/openapi.json"},
+	{GetOpenapiYmlOperation, "getOpenapiYml", http.MethodGet, "/v2/openapi.yml"},
+	{GetOutMsgQueueSizesOperation, "getOutMsgQueueSizes", http.MethodGet, "/v2/liteserver/get_out_msg_queue_sizes"},
+	{GetPurchaseHistoryOperation, "getPurchaseHistory", http.MethodGet, "/v2/purchases/{account_id}/history"},
+	{GetRatesOperation, "getRates", http.MethodGet, "/v2/rates"},
+	{GetRawAccountStateOperation, "getRawAccountState", http.MethodGet, "/v2/liteserver/get_account_state/{account_id}"},
+	{GetRawBlockProofOperation, "getRawBlockProof", http.MethodGet, "/v2/liteserver/get_block_proof"},
+	{GetRawBlockchainBlockOperation, "getRawBlockchainBlock", http.MethodGet, "/v2/liteserver/get_block/{block_id}"},
+	{GetRawBlockchainBlockHeaderOperation, "getRawBlockchainBlockHeader", http.MethodGet, "/v2/liteserver/get_block_header/{block_id}"},
+	{GetRawBlockchainBlockStateOperation, "getRawBlockchainBlockState", http.MethodGet, "/v2/liteserver/get_state/{block_id}"},
+	{GetRawBlockchainConfigOperation, "getRawBlockchainConfig", http.MethodGet, "/v2/blockchain/config/raw"},
+	{GetRawBlockchainConfigFromBlockOperation, "getRawBlockchainConfigFromBlock", http.MethodGet, "/v2/blockchain/masterchain/{masterchain_seqno}/config/raw"},
+	{GetRawConfigOperation, "getRawConfig", http.MethodGet, "/v2/liteserver/get_config_all/{block_id}"},
+	{GetRawListBlockTransactionsOperation, "getRawListBlockTransactions", http.MethodGet, "/v2/liteserver/list_block_transactions/{block_id}"},
+	{GetRawMasterchainInfoOperation, "getRawMasterchainInfo", http.MethodGet, "/v2/liteserver/get_masterchain_info"},
+	{GetRawMasterchainInfoExtOperation, "getRawMasterchainInfoExt", http.MethodGet, "/v2/liteserver/get_masterchain_info_ext"},
+	{GetRawShardBlockProofOperation, "getRawShardBlockProof", http.MethodGet, "/v2/liteserver/get_shard_block_proof/{block_id}"},
+	{GetRawShardInfoOperation, "getRawShardInfo", http.MethodGet, "/v2/liteserver/get_shard_info/{block_id}"},
+	{GetRawTimeOperation, "getRawTime", http.MethodGet, "/v2/liteserver/get_time"},
+	{GetRawTransactionsOperation, "getRawTransactions", http.MethodGet, "/v2/liteserver/get_transactions/{account_id}"},
+	{GetReducedBlockchainBlocksOperation, "getReducedBlockchainBlocks", http.MethodGet, "/v2/blockchain/reduced/blocks"},
+	{GetRewardsApyOperation, "getRewardsApy", http.MethodGet, "/v2/rewards/apy"},
+	{GetRewardsStatsOperation, "getRewardsStats", http.MethodGet, "/v2/rewards/stats"},
+	{GetRoundRewardsOperation, "getRoundRewards", http.MethodGet, "/v2/rewards/round-rewards"},
+	{GetStakingPoolHistoryOperation, "getStakingPoolHistory", http.MethodGet, "/v2/staking/pool/{account_id}/history"},
+	{GetStakingPoolInfoOperation, "getStakingPoolInfo", http.MethodGet, "/v2/staking/pool/{account_id}"},
+	{GetStakingPoolsOperation, "getStakingPools", http.MethodGet, "/v2/staking/pools"},
+	{GetStorageProvidersOperation, "getStorageProviders", http.MethodGet, "/v2/storage/providers"},
+	{GetTonConnectPayloadOperation, "getTonConnectPayload", http.MethodGet, "/v2/tonconnect/payload"},
+	{GetTraceOperation, "getTrace", http.MethodGet, "/v2/traces/{trace_id}"},
+	{GetValidationRoundsOperation, "getValidationRounds", http.MethodGet, "/v2/rewards/validation-rounds"},
+	{GetValidatorsOperation, "getValidators", http.MethodGet, "/v2/rewards/validators"},
+	{GetWalletInfoOperation, "getWalletInfo", http.MethodGet, "/v2/wallet/{account_id}"},
+	{GetWalletsByPublicKeyOperation, "getWalletsByPublicKey", http.MethodGet, "/v2/pubkeys/{public_key}/wallets"},
+	{GetWalletsByPublicKeyBulkOperation, "getWalletsByPublicKeyBulk", http.MethodPost, "/v2/pubkeys/wallets/_bulk"},
+	{PrepareMigrationOperation, "prepareMigration", http.MethodPost, "/v2/migration/prepare"},
+	{ReindexAccountOperation, "reindexAccount", http.MethodPost, "/v2/accounts/{account_id}/reindex"},
+	{SearchAccountsOperation, "searchAccounts", http.MethodGet, "/v2/accounts/search"},
+	{SendBlockchainMessageOperation, "sendBlockchainMessage", http.MethodPost, "/v2/blockchain/message"},
+	{SendRawMessageOperation, "sendRawMessage", http.MethodPost, "/v2/liteserver/send_message"},
+	{StatusOperation, "status", http.MethodGet, "/v2/status"},
+	{TonConnectProofOperation, "tonConnectProof", http.MethodPost, "/v2/wallet/auth/proof"},
+}
@@ -0,0 +1,293 @@
+package tonapi
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/tonkeeper/tongo/boc"
+	"github.com/tonkeeper/tongo/tlb"
+	"github.com/tonkeeper/tongo/ton"
+	"github.com/tonkeeper/tongo/wallet"
+)
+
+// Simulator preflights wallet transfers with EmulateMessageToWallet.
+// Messages are signed with a throwaway key and emulated against the real wallet address,
+// so no private key is needed to see what a transfer would do.
+type Simulator struct {
+	client   *Client
+	wallet   ton.AccountID
+	version  wallet.Version
+	balances map[ton.AccountID]int64
+	params   EmulateMessageToWalletParams
+
+	// networkMu protects networkID, which is fetched by the first W5 simulation if not set.
+	networkMu sync.Mutex
+	networkID *int32
+}
+
+// SimulatorOption configures a Simulator.
+type SimulatorOption func(s *Simulator)
+
+// WithSimulatorWalletVersion sets the version of the simulated wallet. Defaults to wallet.V5R1.
+func WithSimulatorWalletVersion(version wallet.Version) SimulatorOption {
+	return func(s *Simulator) {
+		s.version = version
+	}
+}
+
+// WithSimulatorNetworkGlobalID sets the network id used to build W5 messages.
+// If not set, it is taken from the current masterchain block.
+func WithSimulatorNetworkGlobalID(id int32) SimulatorOption {
+	return func(s *Simulator) {
+		s.networkID = &id
+	}
+}
+
+// WithSimulatorBalance overrides the balance of an account during emulation.
+// It can be used to check a transfer from a wallet that is not funded yet.
+func WithSimulatorBalance(account ton.AccountID, nanoton int64) SimulatorOption {
+	return func(s *Simulator) {
+		s.balances[account] = nanoton
+	}
+}
+
+// WithSimulatorCurrency sets the fiat currency used for values in action previews.
+func WithSimulatorCurrency(currency string) SimulatorOption {
+	return func(s *Simulator) {
+		s.params.Currency = NewOptString(currency)
+	}
+}
+
+// WithSimulatorLanguage sets the language of action previews.
+func WithSimulatorLanguage(language string) SimulatorOption {
+	return func(s *Simulator) {
+		s.params.AcceptLanguage = NewOptString(language)
+	}
+}
+
+// NewSimulator returns a Simulator emulating messages sent from the given wallet.
+func NewSimulator(client *Client, account ton.AccountID, opts ...SimulatorOption) *Simulator {
+	s := &Simulator{
+		client:   client,
+		wallet:   account,
+		version:  wallet.V5R1,
+		balances: map[ton.AccountID]int64{},
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// SimulatedAction is the outcome of one action of the emulated event.
+type SimulatedAction struct {
+	Type        ActionType
+	Status      ActionStatus
+	Description string
+	// Value is the amount of the action with its symbol, e.g. "1 TON".
+	Value string
+	// FiatValue is Value in the currency set with WithSimulatorCurrency.
+	FiatValue string
+}
+
+// SimulationFailure describes a transaction of the emulated trace that failed.
+type SimulationFailure struct {
+	Account ton.AccountID
+	// Transaction is the hash of the failed transaction.
+	Transaction string
+	// Phase is "compute", "action", "bounce" or "aborted".
+	Phase       string
+	Code        int32
+	Description string
+}
+
+// SimulationResult summarizes an emulated transfer.
+type SimulationResult struct {
+	// TotalFees is the sum of fees paid by all transactions of the trace in nanotons.
+	TotalFees int64
+	// WalletFees is the amount of nanotons the wallet spends on top of its actions.
+	// A negative value means the wallet receives a refund.
+	WalletFees int64
+	Actions    []SimulatedAction
+	Risk       Risk
+	Failures   []SimulationFailure
+	// Consequences is the raw response of EmulateMessageToWallet.
+	Consequences *MessageConsequences
+}
+
+// Success reports whether every transaction of the trace and every action succeeded.
+func (r *SimulationResult) Success() bool {
+	if len(r.Failures) > 0 {
+		return false
+	}
+	for _, a := range r.Actions {
+		if a.Status != ActionStatusOk {
+			return false
+		}
+	}
+	return true
+}
+
+// Simulate builds an external message sending the given messages from the wallet and emulates it.
+//
+// Example:
+//
+//	result, err := tonapi.NewSimulator(client, account).Simulate(ctx, wallet.SimpleTransfer{
+//	    Amount:  tlb.Grams(1_000_000_000),
+//	    Address: destination,
+//	})
+func (s *Simulator) Simulate(ctx context.Context, messages ...wallet.Sendable) (*SimulationResult, error) {
+	if len(messages) == 0 {
+		return nil, errors.New("nothing to simulate")
+	}
+	msgBoc, err := s.buildMessage(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	req := &EmulateMessageToWalletReq{
+		Boc:             msgBoc,
+		AddressOverride: NewOptString(s.wallet.ToRaw()),
+	}
+	for account, balance := range s.balances {
+		req.Params = append(req.Params, EmulateMessageToWalletReqParamsItem{
+			Address: account.ToRaw(),
+			Balance: NewOptInt64(balance),
+		})
+	}
+	consequences, err := s.client.EmulateMessageToWallet(ctx, req, s.params)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeSimulation(consequences), nil
+}
+
+// buildMessage signs the messages with a throwaway key using the real seqno of the wallet.
+func (s *Simulator) buildMessage(ctx context.Context, messages []wallet.Sendable) (string, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	var walletOpts []wallet.Option
+	if s.version == wallet.V5Beta || s.version == wallet.V5R1 {
+		networkID, err := s.networkGlobalID(ctx)
+		if err != nil {
+			return "", err
+		}
+		walletOpts = append(walletOpts, wallet.WithNetworkGlobalID(networkID))
+	}
+	w, err := wallet.New(key, s.version, nil, walletOpts...)
+	if err != nil {
+		return "", err
+	}
+
+	account, err := s.client.GetAccount(ctx, GetAccountParams{AccountID: s.wallet.ToRaw()})
+	if err != nil {
+		return "", err
+	}
+	var seqno uint32
+	var init *tlb.StateInit
+	if account.Status == AccountStatusActive {
+		if seqno, err = s.client.GetSeqno(ctx, s.wallet); err != nil {
+			return "", err
+		}
+	} else if init, err = w.StateInit(); err != nil {
+		return "", err
+	}
+	body, err := w.CreateMessageBody(wallet.MessageConfig{
+		Seqno:      seqno,
+		ValidUntil: time.Now().Add(wallet.DefaultMessageLifetime),
+	}, messages...)
+	if err != nil {
+		return "", err
+	}
+	ext, err := ton.CreateExternalMessage(w.GetAddress(), body, init, tlb.VarUInteger16{})
+	if err != nil {
+		return "", err
+	}
+	cell := boc.NewCell()
+	if err := tlb.Marshal(cell, ext); err != nil {
+		return "", err
+	}
+	return cell.ToBocBase64()
+}
+
+// networkGlobalID returns the network id set with WithSimulatorNetworkGlobalID
+// or takes it from the current masterchain block once.
+func (s *Simulator) networkGlobalID(ctx context.Context) (int32, error) {
+	s.networkMu.Lock()
+	defer s.networkMu.Unlock()
+	if s.networkID == nil {
+		head, err := s.client.GetBlockchainMasterchainHead(ctx)
+		if err != nil {
+			return 0, err
+		}
+		s.networkID = &head.GlobalID
+	}
+	return *s.networkID, nil
+}
+
+func summarizeSimulation(consequences *MessageConsequences) *SimulationResult {
+	result := &SimulationResult{
+		WalletFees:   -consequences.Event.Extra,
+		Risk:         consequences.Risk,
+		Consequences: consequences,
+	}
+	for _, a := range consequences.Event.Actions {
+		result.Actions = append(result.Actions, SimulatedAction{
+			Type:        a.Type,
+			Status:      a.Status,
+			Description: a.SimplePreview.Description,
+			Value:       a.SimplePreview.Value.Value,
+			FiatValue:   a.SimplePreview.FiatValue.Value,
+		})
+	}
+	walkTrace(consequences.Trace, func(tx Transaction) {
+		result.TotalFees += tx.TotalFees
+		if failure, ok := transactionFailure(tx); ok {
+			result.Failures = append(result.Failures, failure)
+		}
+	})
+	return result
+}
+
+func walkTrace(trace Trace, fn func(tx Transaction)) {
+	fn(trace.Transaction)
+	for _, child := range trace.Children {
+		walkTrace(child, fn)
+	}
+}
+
+// transactionFailure returns the reason a transaction failed, if it did.
+func transactionFailure(tx Transaction) (SimulationFailure, bool) {
+	failure := SimulationFailure{Transaction: tx.Hash}
+	if account, err := ton.ParseAccountID(tx.Account.Address); err == nil {
+		failure.Account = account
+	}
+	if compute, ok := tx.ComputePhase.Get(); ok && !compute.Skipped && !compute.Success.Or(true) {
+		failure.Phase = "compute"
+		failure.Code = compute.ExitCode.Value
+		failure.Description = compute.ExitCodeDescription.Value
+		return failure, true
+	}
+	if action, ok := tx.ActionPhase.Get(); ok && !action.Success {
+		failure.Phase = "action"
+		failure.Code = action.ResultCode
+		failure.Description = action.ResultCodeDescription.Value
+		return failure, true
+	}
+	if bounce, ok := tx.BouncePhase.Get(); ok {
+		failure.Phase = "bounce"
+		failure.Description = fmt.Sprintf("message bounced: %v", bounce)
+		return failure, true
+	}
+	if tx.Aborted {
+		failure.Phase = "aborted"
+		return failure, true
+	}
+	return failure, false
+}
@@ -0,0 +1,175 @@
+package tonapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/tlb"
+	"github.com/tonkeeper/tongo/ton"
+	"github.com/tonkeeper/tongo/wallet"
+)
+
+func TestSummarizeSimulation(t *testing.T) {
+	wallet := "0:1111111111111111111111111111111111111111111111111111111111111111"
+	jettonWallet := "0:2222222222222222222222222222222222222222222222222222222222222222"
+	consequences := &MessageConsequences{
+		Trace: Trace{
+			Transaction: Transaction{
+				Hash:         "a",
+				Account:      AccountAddress{Address: wallet},
+				Success:      true,
+				TotalFees:    3_000_000,
+				ComputePhase: NewOptComputePhase(ComputePhase{Success: NewOptBool(true)}),
+				ActionPhase:  NewOptActionPhase(ActionPhase{Success: true}),
+			},
+			Children: []Trace{{
+				Transaction: Transaction{
+					Hash:      "b",
+					Account:   AccountAddress{Address: jettonWallet},
+					TotalFees: 1_000_000,
+					ComputePhase: NewOptComputePhase(ComputePhase{
+						Success:             NewOptBool(false),
+						ExitCode:            NewOptInt32(706),
+						ExitCodeDescription: NewOptString("not enough jettons"),
+					}),
+				},
+			}},
+		},
+		Risk: Risk{Gram: 50_000_000},
+		Event: AccountEvent{
+			Extra: -4_000_000,
+			Actions: []Action{{
+				Type:          ActionTypeJettonTransfer,
+				Status:        ActionStatusFailed,
+				SimplePreview: ActionSimplePreview{Description: "Transferring 10 USDT", Value: NewOptString("10 USDT")},
+			}},
+		},
+	}
+
+	result := summarizeSimulation(consequences)
+	require.Equal(t, int64(4_000_000), result.TotalFees)
+	require.Equal(t, int64(4_000_000), result.WalletFees)
+	require.Equal(t, int64(50_000_000), result.Risk.Gram)
+	require.Equal(t, []SimulatedAction{{
+		Type:        ActionTypeJettonTransfer,
+		Status:      ActionStatusFailed,
+		Description: "Transferring 10 USDT",
+		Value:       "10 USDT",
+	}}, result.Actions)
+	require.Len(t, result.Failures, 1)
+	require.Equal(t, "b", result.Failures[0].Transaction)
+	require.Equal(t, "compute", result.Failures[0].Phase)
+	require.Equal(t, int32(706), result.Failures[0].Code)
+	require.Equal(t, "not enough jettons", result.Failures[0].Description)
+	require.False(t, result.Success())
+}
+
+func TestSimulate(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	destination := ton.MustParseAccountID("0:2222222222222222222222222222222222222222222222222222222222222222")
+	var (
+		mu       sync.Mutex
+		status   = AccountStatusActive
+		requests = map[string]int{}
+		emulated []EmulateMessageToWalletReq
+		queries  []*http.Request
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		var data []byte
+		switch r.URL.Path {
+		case "/v2/blockchain/masterchain-head":
+			data, _ = (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: 1, GlobalID: -3}).MarshalJSON()
+		case "/v2/accounts/" + account.ToRaw():
+			data, _ = (&Account{Address: account.ToRaw(), Status: status}).MarshalJSON()
+		case "/v2/wallet/" + account.ToRaw() + "/seqno":
+			data, _ = (&Seqno{Seqno: 7}).MarshalJSON()
+		case "/v2/wallet/emulate":
+			var req EmulateMessageToWalletReq
+			require.NoError(t, req.UnmarshalJSON(mustReadAll(t, r)))
+			emulated = append(emulated, req)
+			queries = append(queries, r)
+			data, _ = (&MessageConsequences{
+				Trace: Trace{Transaction: consumerTestTransaction(1)},
+				Event: AccountEvent{Account: AccountAddress{Address: account.ToRaw()}, Actions: []Action{}, Extra: -1000},
+			}).MarshalJSON()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			data = []byte(`{"error":"not found"}`)
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, &Security{})
+	require.NoError(t, err)
+	ctx := context.Background()
+	transfer := wallet.SimpleTransfer{Amount: tlb.Grams(1_000_000_000), Address: destination}
+
+	_, err = NewSimulator(client, account).Simulate(ctx)
+	require.Error(t, err)
+
+	// simulations run concurrently, the network id is fetched once.
+	simulator := NewSimulator(client, account,
+		WithSimulatorBalance(account, 5_000_000_000),
+		WithSimulatorCurrency("usd"),
+		WithSimulatorLanguage("ru"))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := simulator.Simulate(ctx, transfer)
+			require.NoError(t, err)
+			require.Equal(t, int64(1000), result.WalletFees)
+			require.True(t, result.Success())
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 1, requests["/v2/blockchain/masterchain-head"])
+	require.Equal(t, 4, requests["/v2/wallet/"+account.ToRaw()+"/seqno"])
+	require.Len(t, emulated, 4)
+
+	// the message is emulated against the real wallet with the overrides.
+	req := emulated[0]
+	require.Equal(t, account.ToRaw(), req.AddressOverride.Value)
+	require.Equal(t, []EmulateMessageToWalletReqParamsItem{{Address: account.ToRaw(), Balance: NewOptInt64(5_000_000_000)}}, req.Params)
+	require.Equal(t, "usd", queries[0].URL.Query().Get("currency"))
+	require.Equal(t, "ru", queries[0].Header.Get("Accept-Language"))
+	msg := decodeExternalMessage(t, req.Boc)
+	require.False(t, msg.Init.Exists)
+
+	// an undeployed wallet is emulated with its state init, the network id isn't fetched when set.
+	mu.Lock()
+	status, emulated, requests = AccountStatusUninit, nil, map[string]int{}
+	mu.Unlock()
+	_, err = NewSimulator(client, account, WithSimulatorNetworkGlobalID(-239)).Simulate(ctx, transfer)
+	require.NoError(t, err)
+	require.Zero(t, requests["/v2/blockchain/masterchain-head"])
+	require.Zero(t, requests["/v2/wallet/"+account.ToRaw()+"/seqno"])
+	require.Empty(t, emulated[0].Params)
+	require.Equal(t, account.ToRaw(), emulated[0].AddressOverride.Value)
+	require.True(t, decodeExternalMessage(t, emulated[0].Boc).Init.Exists)
+}
+
+func decodeExternalMessage(t *testing.T, msgBoc string) tlb.Message {
+	cell, err := deserializeSingleCell(msgBoc)
+	require.NoError(t, err)
+	var msg tlb.Message
+	require.NoError(t, tlb.Unmarshal(cell, &msg))
+	require.Equal(t, tlb.SumType("ExtInMsgInfo"), msg.Info.SumType)
+	return msg
+}
+
+func mustReadAll(t *testing.T, r *http.Request) []byte {
+	data, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	return data
+}
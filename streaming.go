@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	sse "github.com/r3labs/sse/v2"
 	"github.com/tonkeeper/tongo"
@@ -55,15 +57,19 @@ type BlockHandler func(data BlockEventData)
 
 // StreamingAPI provides a convenient way to receive events happening on the TON blockchain.
 type StreamingAPI struct {
-	logger   Logger
-	apiKey   string
-	endpoint string
+	logger     StructuredLogger
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+	telemetry  *streamingTelemetry
 }
 
 type StreamingOptions struct {
-	logger   Logger
-	apiKey   string
-	endpoint string
+	logger     StructuredLogger
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+	telemetry  clientConfig
 }
 
 type StreamingOption func(*StreamingOptions)
@@ -92,12 +98,30 @@ func WithStreamingToken(apiKey string) StreamingOption {
 	}
 }
 
+// WithStreamingLogger configures a StreamingAPI instance to report errors to the given logger.
 func WithStreamingLogger(logger Logger) StreamingOption {
+	return func(o *StreamingOptions) {
+		o.logger = errorfLogger{logger: logger}
+	}
+}
+
+// WithStreamingStructuredLogger configures a StreamingAPI instance to use the given leveled logger.
+// Errors are reported at the error level, connection state changes at the info level
+// and subscription requests at the debug level.
+func WithStreamingStructuredLogger(logger StructuredLogger) StreamingOption {
 	return func(o *StreamingOptions) {
 		o.logger = logger
 	}
 }
 
+// WithStreamingHTTPClient configures a StreamingAPI instance to make SSE requests with the given client,
+// for example to route them through a proxy or to record them in tests. Websocket connections are not affected.
+func WithStreamingHTTPClient(client *http.Client) StreamingOption {
+	return func(o *StreamingOptions) {
+		o.httpClient = client
+	}
+}
+
 func NewStreamingAPI(opts ...StreamingOption) *StreamingAPI {
 	options := &StreamingOptions{
 		endpoint: TonApiURL,
@@ -107,9 +131,11 @@ func NewStreamingAPI(opts ...StreamingOption) *StreamingAPI {
 		o(options)
 	}
 	return &StreamingAPI{
-		logger:   options.logger,
-		apiKey:   options.apiKey,
-		endpoint: options.endpoint,
+		logger:     options.logger,
+		apiKey:     options.apiKey,
+		endpoint:   options.endpoint,
+		httpClient: options.httpClient,
+		telemetry:  newStreamingTelemetry(options.telemetry, options.logger),
 	}
 }
 
@@ -166,11 +192,22 @@ type WebsocketConfigurator func(ws Websocket) error
 //     If the configurator returns an error, the connection will be closed and the function will return the error.
 //
 // The configurator is called when the underlying websocket connection is established.
-func (s *StreamingAPI) WebsocketHandleRequests(ctx context.Context, fn WebsocketConfigurator) error {
+func (s *StreamingAPI) WebsocketHandleRequests(ctx context.Context, fn WebsocketConfigurator) (err error) {
+	ctx, span := s.telemetry.startSession(ctx, transportWebsocket, "jsonrpc")
+	defer func() {
+		s.telemetry.endSession(span, err)
+	}()
 	ws, err := websocketConnect(ctx, s.endpoint, s.apiKey)
 	if err != nil {
 		return err
 	}
+	ws.telemetry = s.telemetry
+	ws.logger = s.logger
+	s.logger.Info("websocket connection established", "endpoint", s.endpoint)
+	defer s.logger.Info("websocket connection closed", "endpoint", s.endpoint)
+	state := s.telemetry.newConnectionState(transportWebsocket, "jsonrpc")
+	state.setConnected(ctx, true)
+	defer state.setConnected(ctx, false)
 	return ws.runJsonRPC(ctx, fn)
 }
 
@@ -186,14 +223,15 @@ func (s *StreamingAPI) SubscribeToTraces(ctx context.Context, accounts []string,
 	}
 	url := fmt.Sprintf("%s/v2/sse/accounts/traces?accounts=%s", s.endpoint, accountsQueryStr)
 
-	return s.subscribe(ctx, url, s.apiKey, func(data []byte) {
+	return s.subscribe(ctx, "traces", url, func(data []byte) error {
 		eventData := TraceEventData{}
 		if err := json.Unmarshal(data, &eventData); err != nil {
 			// this should never happen but anyway
-			s.logger.Errorf("sse connection received invalid trace event data: %v", err)
-			return
+			s.logger.Error("sse connection received invalid trace event data", "error", err)
+			return err
 		}
 		handler(eventData)
+		return nil
 	})
 }
 
@@ -206,14 +244,15 @@ func (s *StreamingAPI) SubscribeToMempool(ctx context.Context, accounts []string
 	if len(accounts) > 0 {
 		url += "?accounts=" + strings.Join(accounts, ",")
 	}
-	return s.subscribe(ctx, url, s.apiKey, func(data []byte) {
+	return s.subscribe(ctx, "mempool", url, func(data []byte) error {
 		eventData := MempoolEventData{}
 		if err := json.Unmarshal(data, &eventData); err != nil {
 			// this should never happen but anyway
-			s.logger.Errorf("sse connection received invalid mempool event data: %v", err)
-			return
+			s.logger.Error("sse connection received invalid mempool event data", "error", err)
+			return err
 		}
 		handler(eventData)
+		return nil
 	})
 }
 
@@ -235,14 +274,15 @@ func (s *StreamingAPI) SubscribeToTransactions(ctx context.Context, accounts []s
 	if len(operations) > 0 {
 		url += "&operations=" + strings.Join(operations, ",")
 	}
-	return s.subscribe(ctx, url, s.apiKey, func(data []byte) {
+	return s.subscribe(ctx, "transactions", url, func(data []byte) error {
 		eventData := TransactionEventData{}
 		if err := json.Unmarshal(data, &eventData); err != nil {
 			// this should never happen but anyway
-			s.logger.Errorf("sse connection received invalid transaction event data: %v", err)
-			return
+			s.logger.Error("sse connection received invalid transaction event data", "error", err)
+			return err
 		}
 		handler(eventData)
+		return nil
 	})
 }
 
@@ -256,30 +296,57 @@ func (s *StreamingAPI) SubscribeToBlocks(ctx context.Context, workchain *int, ha
 	if workchain != nil {
 		url = fmt.Sprintf("%s/v2/sse/blocks?workchain=%d", s.endpoint, *workchain)
 	}
-	return s.subscribe(ctx, url, s.apiKey, func(data []byte) {
+	return s.subscribe(ctx, "blocks", url, func(data []byte) error {
 		eventData := BlockEventData{}
 		if err := json.Unmarshal(data, &eventData); err != nil {
 			// this should never happen but anyway
-			s.logger.Errorf("sse connection received invalid block event data: %v", err)
-			return
+			s.logger.Error("sse connection received invalid block event data", "error", err)
+			return err
 		}
 		handler(eventData)
+		return nil
 	})
 }
 
-func (s *StreamingAPI) subscribe(ctx context.Context, url string, apiKey string, handler func(data []byte)) error {
+func (s *StreamingAPI) subscribe(ctx context.Context, stream string, url string, handler func(data []byte) error) (err error) {
+	ctx, span := s.telemetry.startSession(ctx, transportSSE, stream)
+	defer func() {
+		s.telemetry.endSession(span, err)
+	}()
+	state := s.telemetry.newConnectionState(transportSSE, stream)
+	defer state.setConnected(ctx, false)
+
 	client := sse.NewClient(url)
-	if len(apiKey) > 0 {
+	if s.httpClient != nil {
+		client.Connection = s.httpClient
+	}
+	if len(s.apiKey) > 0 {
 		client.Headers = map[string]string{
 			"Authorization": fmt.Sprintf("bearer %s", s.apiKey),
 		}
 	}
+	client.OnConnect(func(*sse.Client) {
+		s.logger.Info("sse connection established", "stream", stream)
+		state.setConnected(ctx, true)
+	})
+	client.OnDisconnect(func(*sse.Client) {
+		s.logger.Warn("sse connection lost", "stream", stream)
+		state.setConnected(ctx, false)
+	})
+	client.ReconnectNotify = func(err error, next time.Duration) {
+		s.logger.Warn("sse connection failed, reconnecting", "stream", stream, "error", err, "backoff", next)
+	}
 	return client.SubscribeWithContext(ctx, "", func(msg *sse.Event) {
 		switch string(msg.Event) {
 		case "heartbeat":
 			return
 		case "message":
-			handler(msg.Data)
+			s.telemetry.handle(ctx, transportSSE, stream, func() error {
+				return handler(msg.Data)
+			})
+		default:
+			s.logger.Debug("sse connection received unknown event", "stream", stream, "event", string(msg.Event))
+			s.telemetry.drop(ctx, transportSSE, stream, dropUnknownEvent)
 		}
 	})
 }
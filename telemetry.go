@@ -0,0 +1,268 @@
+package tonapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	ht "github.com/ogen-go/ogen/http"
+	"github.com/ogen-go/ogen/otelogen"
+)
+
+const (
+	// customRequestOperation names spans and logs of requests made with Client.Request.
+	customRequestOperation = "Request"
+	// customRequestOperationID labels metrics of requests made with Client.Request that don't match any known operation.
+	customRequestOperationID = "request"
+
+	statusClassKey = attribute.Key("http.response.status_class")
+	transportKey   = attribute.Key("tonapi.streaming.transport")
+	eventTypeKey   = attribute.Key("tonapi.streaming.event_type")
+	dropReasonKey  = attribute.Key("tonapi.streaming.drop_reason")
+
+	// dropDecodeError labels events that could not be decoded, dropUnknownEvent labels events of unknown types.
+	dropDecodeError  = "decode_error"
+	dropUnknownEvent = "unknown_event"
+
+	transportSSE       = "sse"
+	transportWebsocket = "websocket"
+)
+
+// requestAttributes describes a request with the attributes the generated operations use,
+// so metrics of Client.Request are recorded in the same series as the operation it calls.
+func requestAttributes(method, path string) []attribute.KeyValue {
+	operationID := customRequestOperationID
+	attrs := make([]attribute.KeyValue, 0, 3)
+	if route, ok := matchOperation(method, path); ok {
+		operationID = route.ID
+		attrs = append(attrs, semconv.HTTPRouteKey.String(route.Path))
+	}
+	return append(attrs,
+		otelogen.OperationID(operationID),
+		semconv.HTTPRequestMethodKey.String(method),
+	)
+}
+
+// statusClass returns "2xx", "4xx" and so on for the given status code or "error" if the request failed without a response.
+func statusClass(statusCode int) string {
+	if statusCode < 100 {
+		return "error"
+	}
+	return strconv.Itoa(statusCode/100) + "xx"
+}
+
+// HTTPMetrics records the number and duration of HTTP requests sent by a Client, labelled by operation,
+// status code class ("2xx", "4xx", "5xx" or "error" if there is no response) and server address.
+// It covers both generated operations and Client.Request:
+//
+//	metrics, err := tonapi.NewHTTPMetrics(tonapi.WithMeterProvider(mp))
+//	client, err := tonapi.NewClient(tonapi.TonApiURL, tonapi.WithToken(token),
+//	    tonapi.WithMeterProvider(mp), tonapi.WithClient(metrics.Wrap(http.DefaultClient)))
+type HTTPMetrics struct {
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// NewHTTPMetrics returns a new HTTPMetrics using the meter provider configured with WithMeterProvider.
+// By default, the global provider is used.
+func NewHTTPMetrics(opts ...Option) (*HTTPMetrics, error) {
+	var cfg clientConfig
+	for _, opt := range opts {
+		opt.applyClient(&cfg)
+	}
+	cfg.initOTEL()
+	m := &HTTPMetrics{}
+	var err error
+	if m.requests, err = cfg.Meter.Int64Counter("tonapi.client.http.requests",
+		metric.WithDescription("Number of HTTP requests sent to tonapi"),
+		metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if m.duration, err = cfg.Meter.Float64Histogram("tonapi.client.http.duration",
+		metric.WithDescription("Duration of HTTP requests sent to tonapi"),
+		metric.WithUnit("ms")); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Wrap returns an HTTP client recording the requests sent with next. Use it with WithClient.
+func (m *HTTPMetrics) Wrap(next ht.Client) ht.Client {
+	return &instrumentedClient{metrics: m, next: next}
+}
+
+type instrumentedClient struct {
+	metrics *HTTPMetrics
+	next    ht.Client
+}
+
+func (c *instrumentedClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.next.Do(req)
+	elapsed := time.Since(start)
+
+	statusCode := 0
+	if err == nil {
+		statusCode = resp.StatusCode
+	}
+	attrs := metric.WithAttributes(append(requestAttributes(req.Method, req.URL.Path),
+		statusClassKey.String(statusClass(statusCode)),
+		semconv.ServerAddress(req.URL.Hostname()),
+	)...)
+	ctx := req.Context()
+	c.metrics.requests.Add(ctx, 1, attrs)
+	c.metrics.duration.Record(ctx, float64(elapsed)/float64(time.Millisecond), attrs)
+	return resp, err
+}
+
+// WithStreamingTelemetry configures a StreamingAPI instance to report metrics and spans
+// using the providers configured with WithMeterProvider and WithTracerProvider.
+// By default, the global providers are used.
+//
+// Example:
+//
+//	streaming := tonapi.NewStreamingAPI(
+//	    tonapi.WithStreamingTelemetry(tonapi.WithMeterProvider(mp), tonapi.WithTracerProvider(tp)),
+//	)
+func WithStreamingTelemetry(opts ...Option) StreamingOption {
+	return func(o *StreamingOptions) {
+		for _, opt := range opts {
+			opt.applyClient(&o.telemetry)
+		}
+	}
+}
+
+// streamingTelemetry records metrics and spans of SSE and websocket sessions.
+type streamingTelemetry struct {
+	tracer          trace.Tracer
+	connected       metric.Int64UpDownCounter
+	reconnects      metric.Int64Counter
+	events          metric.Int64Counter
+	dropped         metric.Int64Counter
+	handlerDuration metric.Float64Histogram
+}
+
+func newStreamingTelemetry(cfg clientConfig, logger StructuredLogger) *streamingTelemetry {
+	cfg.initOTEL()
+	t, err := createStreamingInstruments(cfg.Meter)
+	if err != nil {
+		logger.Error("failed to create streaming metrics", "error", err)
+		t, _ = createStreamingInstruments(noop.NewMeterProvider().Meter(""))
+	}
+	t.tracer = cfg.Tracer
+	return t
+}
+
+func createStreamingInstruments(meter metric.Meter) (t *streamingTelemetry, err error) {
+	t = &streamingTelemetry{}
+	if t.connected, err = meter.Int64UpDownCounter("tonapi.streaming.connected",
+		metric.WithDescription("Number of open streaming connections"),
+		metric.WithUnit("{connection}")); err != nil {
+		return nil, err
+	}
+	if t.reconnects, err = meter.Int64Counter("tonapi.streaming.reconnects",
+		metric.WithDescription("Number of times a streaming connection was re-established"),
+		metric.WithUnit("{connection}")); err != nil {
+		return nil, err
+	}
+	if t.events, err = meter.Int64Counter("tonapi.streaming.events",
+		metric.WithDescription("Number of received streaming events"),
+		metric.WithUnit("{event}")); err != nil {
+		return nil, err
+	}
+	if t.dropped, err = meter.Int64Counter("tonapi.streaming.dropped",
+		metric.WithDescription("Number of streaming events dropped because they could not be decoded or have an unknown type"),
+		metric.WithUnit("{event}")); err != nil {
+		return nil, err
+	}
+	if t.handlerDuration, err = meter.Float64Histogram("tonapi.streaming.handler.duration",
+		metric.WithDescription("Duration of streaming event handlers"),
+		metric.WithUnit("ms")); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// startSession starts a span covering a streaming session.
+func (t *streamingTelemetry) startSession(ctx context.Context, transport, stream string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, transport+" "+stream,
+		trace.WithAttributes(transportKey.String(transport)),
+		trace.WithSpanKind(trace.SpanKindClient),
+	)
+}
+
+// endSession ends the session span recording the error the session finished with.
+func (t *streamingTelemetry) endSession(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// connectionState tracks whether a session is connected to keep tonapi.streaming.connected consistent
+// regardless of how the connection ends.
+type connectionState struct {
+	telemetry *streamingTelemetry
+	attrs     metric.MeasurementOption
+
+	mu            sync.Mutex
+	connected     bool
+	everConnected bool
+}
+
+func (t *streamingTelemetry) newConnectionState(transport, stream string) *connectionState {
+	return &connectionState{
+		telemetry: t,
+		attrs:     metric.WithAttributes(transportKey.String(transport), attribute.String("tonapi.streaming.stream", stream)),
+	}
+}
+
+func (c *connectionState) setConnected(ctx context.Context, connected bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.connected == connected {
+		return
+	}
+	c.connected = connected
+	if !connected {
+		c.telemetry.connected.Add(ctx, -1, c.attrs)
+		return
+	}
+	c.telemetry.connected.Add(ctx, 1, c.attrs)
+	if c.everConnected {
+		c.telemetry.reconnects.Add(ctx, 1, c.attrs)
+	}
+	c.everConnected = true
+}
+
+// handle runs an event handler, recording the event, its handler latency or the fact it was dropped
+// because the handler failed to decode it.
+func (t *streamingTelemetry) handle(ctx context.Context, transport, eventType string, fn func() error) {
+	start := time.Now()
+	if err := fn(); err != nil {
+		t.drop(ctx, transport, eventType, dropDecodeError)
+		return
+	}
+	attrs := metric.WithAttributes(transportKey.String(transport), eventTypeKey.String(eventType))
+	t.events.Add(ctx, 1, attrs)
+	t.handlerDuration.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), attrs)
+}
+
+// drop records an event dropped for the given reason.
+func (t *streamingTelemetry) drop(ctx context.Context, transport, eventType, reason string) {
+	t.dropped.Add(ctx, 1, metric.WithAttributes(
+		transportKey.String(transport),
+		eventTypeKey.String(eventType),
+		dropReasonKey.String(reason),
+	))
+}
@@ -0,0 +1,200 @@
+package tonapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ogen-go/ogen/otelogen"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// recordingMeter keeps the measurements of counters and histograms by instrument name.
+type recordingMeter struct {
+	noop.Meter
+	mu           sync.Mutex
+	measurements map[string][]attribute.Set
+}
+
+func (m *recordingMeter) record(name string, attrs attribute.Set) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.measurements[name] = append(m.measurements[name], attrs)
+}
+
+func (m *recordingMeter) get(name string) []attribute.Set {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.measurements[name]
+}
+
+func (m *recordingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return recordingInt64Counter{name: name, meter: m}, nil
+}
+
+func (m *recordingMeter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return recordingFloat64Histogram{name: name, meter: m}, nil
+}
+
+type recordingInt64Counter struct {
+	noop.Int64Counter
+	name  string
+	meter *recordingMeter
+}
+
+func (c recordingInt64Counter) Add(_ context.Context, _ int64, opts ...metric.AddOption) {
+	c.meter.record(c.name, metric.NewAddConfig(opts).Attributes())
+}
+
+type recordingFloat64Histogram struct {
+	noop.Float64Histogram
+	name  string
+	meter *recordingMeter
+}
+
+func (h recordingFloat64Histogram) Record(_ context.Context, _ float64, opts ...metric.RecordOption) {
+	h.meter.record(h.name, metric.NewRecordConfig(opts).Attributes())
+}
+
+type recordingMeterProvider struct {
+	noop.MeterProvider
+	meter *recordingMeter
+}
+
+func (p recordingMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter { return p.meter }
+
+func newRecordingMeterProvider() recordingMeterProvider {
+	return recordingMeterProvider{meter: &recordingMeter{measurements: map[string][]attribute.Set{}}}
+}
+
+func TestMatchOperation(t *testing.T) {
+	for _, tt := range []struct {
+		method, path string
+		operation    OperationName
+	}{
+		{http.MethodGet, "/v2/accounts/0:11", GetAccountOperation},
+		{http.MethodPost, "/v2/accounts/_bulk", GetAccountsOperation},
+		{http.MethodGet, "/v2/accounts/0:11/events/abc", GetAccountEventOperation},
+		{http.MethodGet, "/v2/blockchain/masterchain-head/", GetBlockchainMasterchainHeadOperation},
+		{http.MethodPost, "/v2/blockchain/accounts/0:11/methods/seqno", ExecGetMethodWithBodyForBlockchainAccountOperation},
+	} {
+		route, ok := matchOperation(tt.method, tt.path)
+		require.True(t, ok, tt.path)
+		require.Equal(t, tt.operation, route.Operation, tt.path)
+	}
+	_, ok := matchOperation(http.MethodGet, "/v2/accounts/")
+	require.False(t, ok)
+	_, ok = matchOperation(http.MethodDelete, "/v2/accounts/0:11")
+	require.False(t, ok)
+}
+
+func TestRequestMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/blockchain/masterchain-head" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: 1}).MarshalJSON()
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	provider := newRecordingMeterProvider()
+	client, err := NewClient(srv.URL, &Security{}, WithMeterProvider(provider))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Client.Request is recorded once, in the same series as the generated operation.
+	_, err = client.GetBlockchainMasterchainHead(ctx)
+	require.NoError(t, err)
+	_, err = client.Request(ctx, http.MethodGet, "v2/blockchain/masterchain-head", nil, nil)
+	require.NoError(t, err)
+	requests := provider.meter.get(otelogen.ClientRequestCount)
+	require.Len(t, requests, 2)
+	require.Equal(t, requests[0], requests[1])
+	operation, _ := requests[1].Value(otelogen.OperationIDKey)
+	require.Equal(t, "getBlockchainMasterchainHead", operation.AsString())
+	require.Len(t, provider.meter.get(otelogen.ClientDuration), 2)
+	require.Empty(t, provider.meter.get(otelogen.ClientErrorsCount))
+
+	_, err = client.Request(ctx, http.MethodGet, "v2/custom", nil, nil)
+	require.Error(t, err)
+	failed := provider.meter.get(otelogen.ClientErrorsCount)
+	require.Len(t, failed, 1)
+	operation, _ = failed[0].Value(otelogen.OperationIDKey)
+	require.Equal(t, customRequestOperationID, operation.AsString())
+}
+
+func TestHTTPMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/blockchain/masterchain-head" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := (&BlockchainBlock{WorkchainID: -1, Shard: "8000000000000000", Seqno: 1}).MarshalJSON()
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	provider := newRecordingMeterProvider()
+	metrics, err := NewHTTPMetrics(WithMeterProvider(provider))
+	require.NoError(t, err)
+	client, err := NewClient(srv.URL, &Security{}, WithClient(metrics.Wrap(http.DefaultClient)))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.GetBlockchainMasterchainHead(ctx)
+	require.NoError(t, err)
+	_, err = client.Request(ctx, http.MethodGet, "v2/custom", nil, nil)
+	require.Error(t, err)
+	unreachable, err := NewClient("http://127.0.0.1:1", &Security{}, WithClient(metrics.Wrap(http.DefaultClient)))
+	require.NoError(t, err)
+	_, err = unreachable.Request(ctx, http.MethodGet, "v2/status", nil, nil)
+	require.Error(t, err)
+
+	requests := provider.meter.get("tonapi.client.http.requests")
+	require.Len(t, requests, 3)
+	require.Len(t, provider.meter.get("tonapi.client.http.duration"), 3)
+	for i, tt := range []struct {
+		operation, statusClass string
+	}{
+		{"getBlockchainMasterchainHead", "2xx"},
+		{customRequestOperationID, "4xx"},
+		{"status", "error"},
+	} {
+		operation, _ := requests[i].Value(otelogen.OperationIDKey)
+		require.Equal(t, tt.operation, operation.AsString())
+		class, _ := requests[i].Value(statusClassKey)
+		require.Equal(t, tt.statusClass, class.AsString())
+		address, _ := requests[i].Value(semconv.ServerAddressKey)
+		require.Equal(t, "127.0.0.1", address.AsString())
+	}
+}
+
+func TestStreamingTelemetryDropped(t *testing.T) {
+	provider := newRecordingMeterProvider()
+	telemetry := newStreamingTelemetry(clientConfig{otelConfig: otelConfig{MeterProvider: provider}}, noopLogger{})
+	ctx := context.Background()
+
+	telemetry.handle(ctx, transportSSE, "traces", func() error { return nil })
+	telemetry.handle(ctx, transportSSE, "traces", func() error { return errors.New("invalid json") })
+	telemetry.drop(ctx, transportWebsocket, "new_method", dropUnknownEvent)
+	require.Len(t, provider.meter.get("tonapi.streaming.events"), 1)
+	dropped := provider.meter.get("tonapi.streaming.dropped")
+	require.Len(t, dropped, 2)
+	reason, _ := dropped[0].Value(dropReasonKey)
+	require.Equal(t, dropDecodeError, reason.AsString())
+	reason, _ = dropped[1].Value(dropReasonKey)
+	require.Equal(t, dropUnknownEvent, reason.AsString())
+	eventType, _ := dropped[1].Value(eventTypeKey)
+	require.Equal(t, "new_method", eventType.AsString())
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"io"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -13,6 +14,9 @@ import (
 	ht "github.com/ogen-go/ogen/http"
 	"github.com/tonkeeper/tongo"
 	"github.com/tonkeeper/tongo/tlb"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Custom interface {
@@ -67,19 +71,39 @@ func (c *Client) GetAccountState(ctx context.Context, accountID tongo.AccountID)
 
 // Request sends an HTTP request with the given method, URL, parameters, and data,
 // and returns the response as a json.RawMessage.
-func (c *Client) Request(ctx context.Context, method, endpoint string, query map[string][]string, data []byte) (json.RawMessage, error) {
+func (c *Client) Request(ctx context.Context, method, endpoint string, query map[string][]string, data []byte) (_ json.RawMessage, err error) {
 	const contentType = "application/json"
 
+	// Parse the full URL by resolving the endpoint relative to the server URL
+	u := c.serverURL.ResolveReference(&url.URL{Path: endpoint})
+
+	// Label metrics and the span like the generated operation with the same route, if any
+	otelAttrs := requestAttributes(method, u.Path)
+
 	// Start measuring the request duration
 	startTime := time.Now()
 	defer func() {
 		// Use floating point division here for higher precision (instead of Millisecond method).
 		elapsedDuration := time.Since(startTime)
-		c.duration.Record(ctx, float64(elapsedDuration)/float64(time.Millisecond))
+		c.duration.Record(ctx, float64(elapsedDuration)/float64(time.Millisecond), metric.WithAttributes(otelAttrs...))
 	}()
 
-	// Parse the full URL by resolving the endpoint relative to the server URL
-	u := c.serverURL.ResolveReference(&url.URL{Path: endpoint})
+	// Increment request counter
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	ctx, span := c.cfg.Tracer.Start(ctx, customRequestOperation,
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	defer func() {
+		if err != nil {
+			// Increment the error counter
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
 
 	// Add query parameters to the URL if any
 	if query != nil {
@@ -95,8 +119,6 @@ func (c *Client) Request(ctx context.Context, method, endpoint string, query map
 	// Create the request
 	req, err := ht.NewRequest(ctx, method, u)
 	if err != nil {
-		// Increment the error counter
-		c.errors.Add(ctx, 1)
 		return nil, err
 	}
 	if data != nil {
@@ -109,26 +131,18 @@ func (c *Client) Request(ctx context.Context, method, endpoint string, query map
 	// Send the request using the baseClient's HTTP client
 	resp, err := c.cfg.Client.Do(req) // Use the appropriate client or config
 	if err != nil {
-		// Increment the error counter
-		c.errors.Add(ctx, 1)
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	c.requests.Add(ctx, 1)
-
 	// Check if the response status code indicates an error
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		// Increment the error counter
-		c.errors.Add(ctx, 1)
 		return nil, errors.New(resp.Status)
 	}
 
 	// Read the response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		// Increment the error counter
-		c.errors.Add(ctx, 1)
 		return nil, err
 	}
 
@@ -136,8 +150,6 @@ func (c *Client) Request(ctx context.Context, method, endpoint string, query map
 	var jsonResponse json.RawMessage
 	err = json.Unmarshal(body, &jsonResponse)
 	if err != nil {
-		// Increment the error counter
-		c.errors.Add(ctx, 1)
 		return nil, err
 	}
 
@@ -158,3 +170,57 @@ func TraceInProgress(t *Trace) bool {
 	}
 	return false
 }
+
+// WaitForTrace polls GetTrace until the trace with the given ID is indexed and finished or the context is done.
+// The trace ID can be a transaction hash or a normalized hash of an external message.
+//
+// The delay between polls starts at pollInterval and doubles after every poll up to 8 times pollInterval.
+// Network errors, 429 and 5xx responses are retried, WaitForTrace gives up after 5 of them in a row.
+// Other error responses are returned right away.
+func (c *Client) WaitForTrace(ctx context.Context, traceID string, pollInterval time.Duration) (*Trace, error) {
+	const (
+		maxBackoffFactor = 8
+		maxFailures      = 5
+	)
+	backoff := pollInterval
+	failures := 0
+	for {
+		t, err := c.GetTrace(ctx, GetTraceParams{TraceID: traceID})
+		switch {
+		case err == nil && !TraceInProgress(t):
+			return t, nil
+		case err == nil || isNotFound(err):
+			failures = 0
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case !isTransientError(err):
+			return nil, err
+		default:
+			failures++
+			if failures >= maxFailures {
+				return nil, err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxBackoffFactor*pollInterval)
+	}
+}
+
+func isNotFound(err error) bool {
+	var statusErr *ErrorStatusCode
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// isTransientError returns true if the request may succeed when retried:
+// it failed without a response or with a 429 or 5xx response.
+func isTransientError(err error) bool {
+	var statusErr *ErrorStatusCode
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,162 @@
+// Command specgen generates the table of operations served by tonapitest from api/openapi.yml.
+// With -routes, it generates the table of operation routes of package tonapi instead.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ogen-go/ogen"
+	"github.com/ogen-go/ogen/jsonschema"
+	"github.com/ogen-go/ogen/openapi"
+	"github.com/ogen-go/ogen/openapi/parser"
+)
+
+func main() {
+	spec := flag.String("spec", "../api/openapi.yml", "path to the OpenAPI spec")
+	out := flag.String("out", "spec_gen.go", "output file")
+	routes := flag.Bool("routes", false, "generate the operation routes of package tonapi")
+	flag.Parse()
+
+	data, err := os.ReadFile(*spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	root, err := ogen.Parse(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	api, err := parser.Parse(root, parser.Settings{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	operations := api.Operations
+	sort.Slice(operations, func(i, j int) bool {
+		return operationName(operations[i]) < operationName(operations[j])
+	})
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by specgen from api/openapi.yml, DO NOT EDIT.\n\n")
+	if *routes {
+		writeRoutes(&b, operations)
+	} else {
+		writeSpec(&b, operations)
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeRoutes writes the routes matched by package tonapi to label requests with their operations.
+func writeRoutes(b *bytes.Buffer, operations []*openapi.Operation) {
+	b.WriteString("package tonapi\n\n")
+	b.WriteString("import \"net/http\"\n\n")
+	b.WriteString("var operationRoutes = []operationRoute{\n")
+	for _, op := range operations {
+		fmt.Fprintf(b, "{%sOperation, %q, http.Method%s, %q},\n", operationName(op), op.OperationID, methodName(op.HTTPMethod), op.Path.String())
+	}
+	b.WriteString("}\n")
+}
+
+// writeSpec writes the operations served by tonapitest.
+func writeSpec(b *bytes.Buffer, operations []*openapi.Operation) {
+	b.WriteString("package tonapitest\n\n")
+	b.WriteString("import (\n\t\"net/http\"\n\n\t\"github.com/tonkeeper/tonapi-go\"\n)\n\n")
+	b.WriteString("var specOperations = []specOperation{\n")
+	for _, op := range operations {
+		fmt.Fprintf(b, "{\nOperation: tonapi.%sOperation,\n", operationName(op))
+		fmt.Fprintf(b, "Method: http.Method%s,\n", methodName(op.HTTPMethod))
+		fmt.Fprintf(b, "Path: %q,\n", op.Path.String())
+		if len(op.Parameters) > 0 {
+			b.WriteString("Parameters: []specParameter{\n")
+			for _, p := range op.Parameters {
+				writeParameter(b, p)
+			}
+			b.WriteString("},\n")
+		}
+		if body := op.RequestBody; body != nil {
+			var types []string
+			for contentType := range body.Content {
+				types = append(types, contentType)
+			}
+			sort.Strings(types)
+			fmt.Fprintf(b, "Body: &specBody{Required: %v, ContentTypes: %#v},\n", body.Required, types)
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n")
+}
+
+// operationName returns the name ogen gives to the operation.
+func operationName(op *openapi.Operation) string {
+	return strings.ToUpper(op.OperationID[:1]) + op.OperationID[1:]
+}
+
+func methodName(method string) string {
+	method = strings.ToUpper(method)
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch:
+		return method[:1] + strings.ToLower(method[1:])
+	}
+	log.Fatalf("unsupported method %v", method)
+	return ""
+}
+
+func writeParameter(b *bytes.Buffer, p *openapi.Parameter) {
+	fmt.Fprintf(b, "{Name: %q, In: %q", p.Name, p.In)
+	if p.Required {
+		b.WriteString(", Required: true")
+	}
+	if p.In.Query() && !p.Explode {
+		b.WriteString(", Delimited: true")
+	}
+	if s := p.Schema; s != nil {
+		writeSchema(b, s)
+		if s.Type == jsonschema.Array && s.Item != nil {
+			fmt.Fprintf(b, ", Items: &specParameter{Name: %q", p.Name)
+			writeSchema(b, s.Item)
+			b.WriteString("}")
+		}
+	}
+	b.WriteString("},\n")
+}
+
+func writeSchema(b *bytes.Buffer, s *jsonschema.Schema) {
+	if s.Type != "" {
+		fmt.Fprintf(b, ", Type: %q", s.Type)
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			values = append(values, fmt.Sprint(v))
+		}
+		fmt.Fprintf(b, ", Enum: %#v", values)
+	}
+	if v, ok := num(s.Minimum); ok {
+		fmt.Fprintf(b, ", Minimum: ptr(%s)", strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	if v, ok := num(s.Maximum); ok {
+		fmt.Fprintf(b, ", Maximum: ptr(%s)", strconv.FormatFloat(v, 'f', -1, 64))
+	}
+}
+
+func num(n jsonschema.Num) (float64, bool) {
+	if len(n) == 0 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(string(n), 64)
+	return v, err == nil
+}
@@ -0,0 +1,1203 @@
+// Code generated by mockgen from oas_client_gen.go, DO NOT EDIT.
+
+package tonapitest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-faster/jx"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// MockInvoker is a tonapi.Invoker with a function field per operation.
+// Operations without a function return an error wrapping ErrNotImplemented.
+// All calls, implemented or not, are recorded and can be inspected with Calls.
+type MockInvoker struct {
+	AccountDnsBackResolveFunc                     func(ctx context.Context, params tonapi.AccountDnsBackResolveParams) (*tonapi.DomainNames, error)
+	AddressParseFunc                              func(ctx context.Context, params tonapi.AddressParseParams) (*tonapi.AddressParseOK, error)
+	BlockchainAccountInspectFunc                  func(ctx context.Context, params tonapi.BlockchainAccountInspectParams) (*tonapi.BlockchainAccountInspect, error)
+	DecodeMessageFunc                             func(ctx context.Context, request *tonapi.DecodeMessageReq) (*tonapi.DecodedMessage, error)
+	DnsResolveFunc                                func(ctx context.Context, params tonapi.DnsResolveParams) (*tonapi.DnsRecord, error)
+	DownloadBlockchainBlockBocFunc                func(ctx context.Context, params tonapi.DownloadBlockchainBlockBocParams) (*tonapi.DownloadBlockchainBlockBocOKHeaders, error)
+	EmulateMessageToAccountEventFunc              func(ctx context.Context, request *tonapi.EmulateMessageToAccountEventReq, params tonapi.EmulateMessageToAccountEventParams) (*tonapi.AccountEvent, error)
+	EmulateMessageToEventFunc                     func(ctx context.Context, request *tonapi.EmulateMessageToEventReq, params tonapi.EmulateMessageToEventParams) (*tonapi.Event, error)
+	EmulateMessageToTraceFunc                     func(ctx context.Context, request *tonapi.EmulateMessageToTraceReq, params tonapi.EmulateMessageToTraceParams) (*tonapi.Trace, error)
+	EmulateMessageToWalletFunc                    func(ctx context.Context, request *tonapi.EmulateMessageToWalletReq, params tonapi.EmulateMessageToWalletParams) (*tonapi.MessageConsequences, error)
+	ExecGetMethodForBlockchainAccountFunc         func(ctx context.Context, params tonapi.ExecGetMethodForBlockchainAccountParams) (*tonapi.MethodExecutionResult, error)
+	ExecGetMethodWithBodyForBlockchainAccountFunc func(ctx context.Context, request tonapi.OptExecGetMethodWithBodyForBlockchainAccountReq, params tonapi.ExecGetMethodWithBodyForBlockchainAccountParams) (*tonapi.MethodExecutionResult, error)
+	GaslessConfigFunc                             func(ctx context.Context) (*tonapi.GaslessConfig, error)
+	GaslessEstimateFunc                           func(ctx context.Context, request *tonapi.GaslessEstimateReq, params tonapi.GaslessEstimateParams) (*tonapi.SignRawParams, error)
+	GaslessSendFunc                               func(ctx context.Context, request *tonapi.GaslessSendReq) (*tonapi.GaslessTx, error)
+	GetAccountFunc                                func(ctx context.Context, params tonapi.GetAccountParams) (*tonapi.Account, error)
+	GetAccountDefiAssetsFunc                      func(ctx context.Context, params tonapi.GetAccountDefiAssetsParams) (*tonapi.DefiAssets, error)
+	GetAccountDiffFunc                            func(ctx context.Context, params tonapi.GetAccountDiffParams) (*tonapi.GetAccountDiffOK, error)
+	GetAccountDnsExpiringFunc                     func(ctx context.Context, params tonapi.GetAccountDnsExpiringParams) (*tonapi.DnsExpiring, error)
+	GetAccountEventFunc                           func(ctx context.Context, params tonapi.GetAccountEventParams) (*tonapi.AccountEvent, error)
+	GetAccountEventsFunc                          func(ctx context.Context, params tonapi.GetAccountEventsParams) (*tonapi.AccountEvents, error)
+	GetAccountExtraCurrencyHistoryByIDFunc        func(ctx context.Context, params tonapi.GetAccountExtraCurrencyHistoryByIDParams) (*tonapi.AccountEvents, error)
+	GetAccountInfoByStateInitFunc                 func(ctx context.Context, request *tonapi.GetAccountInfoByStateInitReq) (*tonapi.AccountInfoByStateInit, error)
+	GetAccountJettonBalanceFunc                   func(ctx context.Context, params tonapi.GetAccountJettonBalanceParams) (*tonapi.JettonBalance, error)
+	GetAccountJettonHistoryByIDFunc               func(ctx context.Context, params tonapi.GetAccountJettonHistoryByIDParams) (*tonapi.AccountEvents, error)
+	GetAccountJettonsBalancesFunc                 func(ctx context.Context, params tonapi.GetAccountJettonsBalancesParams) (*tonapi.JettonsBalances, error)
+	GetAccountJettonsHistoryFunc                  func(ctx context.Context, params tonapi.GetAccountJettonsHistoryParams) (*tonapi.JettonOperations, error)
+	GetAccountMultisigsFunc                       func(ctx context.Context, params tonapi.GetAccountMultisigsParams) (*tonapi.Multisigs, error)
+	GetAccountNftHistoryFunc                      func(ctx context.Context, params tonapi.GetAccountNftHistoryParams) (*tonapi.NftOperations, error)
+	GetAccountNftItemsFunc                        func(ctx context.Context, params tonapi.GetAccountNftItemsParams) (*tonapi.NftItems, error)
+	GetAccountNominatorsPoolsFunc                 func(ctx context.Context, params tonapi.GetAccountNominatorsPoolsParams) (*tonapi.AccountStaking, error)
+	GetAccountPublicKeyFunc                       func(ctx context.Context, params tonapi.GetAccountPublicKeyParams) (*tonapi.GetAccountPublicKeyOK, error)
+	GetAccountSeqnoFunc                           func(ctx context.Context, params tonapi.GetAccountSeqnoParams) (*tonapi.Seqno, error)
+	GetAccountSubscriptionsFunc                   func(ctx context.Context, params tonapi.GetAccountSubscriptionsParams) (*tonapi.Subscriptions, error)
+	GetAccountTracesFunc                          func(ctx context.Context, params tonapi.GetAccountTracesParams) (*tonapi.TraceIDs, error)
+	GetAccountsFunc                               func(ctx context.Context, request tonapi.OptGetAccountsReq, params tonapi.GetAccountsParams) (*tonapi.Accounts, error)
+	GetAllAuctionsFunc                            func(ctx context.Context, params tonapi.GetAllAuctionsParams) (*tonapi.Auctions, error)
+	GetAllRawShardsInfoFunc                       func(ctx context.Context, params tonapi.GetAllRawShardsInfoParams) (*tonapi.GetAllRawShardsInfoOK, error)
+	GetBlockchainAccountTransactionsFunc          func(ctx context.Context, params tonapi.GetBlockchainAccountTransactionsParams) (*tonapi.Transactions, error)
+	GetBlockchainBlockFunc                        func(ctx context.Context, params tonapi.GetBlockchainBlockParams) (*tonapi.BlockchainBlock, error)
+	GetBlockchainBlockTransactionsFunc            func(ctx context.Context, params tonapi.GetBlockchainBlockTransactionsParams) (*tonapi.Transactions, error)
+	GetBlockchainConfigFunc                       func(ctx context.Context) (*tonapi.BlockchainConfig, error)
+	GetBlockchainConfigFromBlockFunc              func(ctx context.Context, params tonapi.GetBlockchainConfigFromBlockParams) (*tonapi.BlockchainConfig, error)
+	GetBlockchainMasterchainBlocksFunc            func(ctx context.Context, params tonapi.GetBlockchainMasterchainBlocksParams) (*tonapi.BlockchainBlocks, error)
+	GetBlockchainMasterchainHeadFunc              func(ctx context.Context) (*tonapi.BlockchainBlock, error)
+	GetBlockchainMasterchainShardsFunc            func(ctx context.Context, params tonapi.GetBlockchainMasterchainShardsParams) (*tonapi.BlockchainBlockShards, error)
+	GetBlockchainMasterchainTransactionsFunc      func(ctx context.Context, params tonapi.GetBlockchainMasterchainTransactionsParams) (*tonapi.Transactions, error)
+	GetBlockchainRawAccountFunc                   func(ctx context.Context, params tonapi.GetBlockchainRawAccountParams) (*tonapi.BlockchainRawAccount, error)
+	GetBlockchainRawAccountsFunc                  func(ctx context.Context, request tonapi.OptGetBlockchainRawAccountsReq) (*tonapi.BlockchainRawAccounts, error)
+	GetBlockchainTransactionFunc                  func(ctx context.Context, params tonapi.GetBlockchainTransactionParams) (*tonapi.Transaction, error)
+	GetBlockchainTransactionByMessageHashFunc     func(ctx context.Context, params tonapi.GetBlockchainTransactionByMessageHashParams) (*tonapi.Transaction, error)
+	GetBlockchainValidatorsFunc                   func(ctx context.Context) (*tonapi.Validators, error)
+	GetChartRatesFunc                             func(ctx context.Context, params tonapi.GetChartRatesParams) (*tonapi.GetChartRatesOK, error)
+	GetDnsInfoFunc                                func(ctx context.Context, params tonapi.GetDnsInfoParams) (*tonapi.DomainInfo, error)
+	GetDomainBidsFunc                             func(ctx context.Context, params tonapi.GetDomainBidsParams) (*tonapi.DomainBids, error)
+	GetEventFunc                                  func(ctx context.Context, params tonapi.GetEventParams) (*tonapi.Event, error)
+	GetExtraCurrencyInfoFunc                      func(ctx context.Context, params tonapi.GetExtraCurrencyInfoParams) (*tonapi.EcPreview, error)
+	GetItemsFromCollectionFunc                    func(ctx context.Context, params tonapi.GetItemsFromCollectionParams) (*tonapi.NftItems, error)
+	GetJettonAccountHistoryByIDFunc               func(ctx context.Context, params tonapi.GetJettonAccountHistoryByIDParams) (*tonapi.JettonOperations, error)
+	GetJettonHoldersFunc                          func(ctx context.Context, params tonapi.GetJettonHoldersParams) (*tonapi.JettonHolders, error)
+	GetJettonInfoFunc                             func(ctx context.Context, params tonapi.GetJettonInfoParams) (*tonapi.JettonInfo, error)
+	GetJettonInfosByAddressesFunc                 func(ctx context.Context, request tonapi.OptGetJettonInfosByAddressesReq) (*tonapi.Jettons, error)
+	GetJettonTransferPayloadFunc                  func(ctx context.Context, params tonapi.GetJettonTransferPayloadParams) (*tonapi.JettonTransferPayload, error)
+	GetJettonsFunc                                func(ctx context.Context, params tonapi.GetJettonsParams) (*tonapi.Jettons, error)
+	GetJettonsEventsFunc                          func(ctx context.Context, params tonapi.GetJettonsEventsParams) (*tonapi.Event, error)
+	GetLibraryByHashFunc                          func(ctx context.Context, params tonapi.GetLibraryByHashParams) (*tonapi.BlockchainLibrary, error)
+	GetMarketsRatesFunc                           func(ctx context.Context) (*tonapi.GetMarketsRatesOK, error)
+	GetMigrationWalletsFunc                       func(ctx context.Context, request tonapi.OptGetMigrationWalletsReq, params tonapi.GetMigrationWalletsParams) (*tonapi.MigrationWallets, error)
+	GetMultisigAccountFunc                        func(ctx context.Context, params tonapi.GetMultisigAccountParams) (*tonapi.Multisig, error)
+	GetMultisigOrderFunc                          func(ctx context.Context, params tonapi.GetMultisigOrderParams) (*tonapi.MultisigOrder, error)
+	GetNftCollectionFunc                          func(ctx context.Context, params tonapi.GetNftCollectionParams) (*tonapi.NftCollection, error)
+	GetNftCollectionItemsByAddressesFunc          func(ctx context.Context, request tonapi.OptGetNftCollectionItemsByAddressesReq) (*tonapi.NftCollections, error)
+	GetNftCollectionsFunc                         func(ctx context.Context, params tonapi.GetNftCollectionsParams) (*tonapi.NftCollections, error)
+	GetNftHistoryByIDFunc                         func(ctx context.Context, params tonapi.GetNftHistoryByIDParams) (*tonapi.AccountEvents, error)
+	GetNftItemByAddressFunc                       func(ctx context.Context, params tonapi.GetNftItemByAddressParams) (*tonapi.NftItem, error)
+	GetNftItemsByAddressesFunc                    func(ctx context.Context, request tonapi.OptGetNftItemsByAddressesReq) (*tonapi.NftItems, error)
+	GetOpenapiJsonFunc                            func(ctx context.Context) (jx.Raw, error)
+	GetOpenapiYmlFunc                             func(ctx context.Context) (tonapi.GetOpenapiYmlOK, error)
+	GetOutMsgQueueSizesFunc                       func(ctx context.Context) (*tonapi.GetOutMsgQueueSizesOK, error)
+	GetPurchaseHistoryFunc                        func(ctx context.Context, params tonapi.GetPurchaseHistoryParams) (*tonapi.AccountPurchases, error)
+	GetRatesFunc                                  func(ctx context.Context, params tonapi.GetRatesParams) (*tonapi.GetRatesOK, error)
+	GetRawAccountStateFunc                        func(ctx context.Context, params tonapi.GetRawAccountStateParams) (*tonapi.GetRawAccountStateOK, error)
+	GetRawBlockProofFunc                          func(ctx context.Context, params tonapi.GetRawBlockProofParams) (*tonapi.GetRawBlockProofOK, error)
+	GetRawBlockchainBlockFunc                     func(ctx context.Context, params tonapi.GetRawBlockchainBlockParams) (*tonapi.GetRawBlockchainBlockOK, error)
+	GetRawBlockchainBlockHeaderFunc               func(ctx context.Context, params tonapi.GetRawBlockchainBlockHeaderParams) (*tonapi.GetRawBlockchainBlockHeaderOK, error)
+	GetRawBlockchainBlockStateFunc                func(ctx context.Context, params tonapi.GetRawBlockchainBlockStateParams) (*tonapi.GetRawBlockchainBlockStateOK, error)
+	GetRawBlockchainConfigFunc                    func(ctx context.Context) (*tonapi.RawBlockchainConfig, error)
+	GetRawBlockchainConfigFromBlockFunc           func(ctx context.Context, params tonapi.GetRawBlockchainConfigFromBlockParams) (*tonapi.RawBlockchainConfig, error)
+	GetRawConfigFunc                              func(ctx context.Context, params tonapi.GetRawConfigParams) (*tonapi.GetRawConfigOK, error)
+	GetRawListBlockTransactionsFunc               func(ctx context.Context, params tonapi.GetRawListBlockTransactionsParams) (*tonapi.GetRawListBlockTransactionsOK, error)
+	GetRawMasterchainInfoFunc                     func(ctx context.Context) (*tonapi.GetRawMasterchainInfoOK, error)
+	GetRawMasterchainInfoExtFunc                  func(ctx context.Context, params tonapi.GetRawMasterchainInfoExtParams) (*tonapi.GetRawMasterchainInfoExtOK, error)
+	GetRawShardBlockProofFunc                     func(ctx context.Context, params tonapi.GetRawShardBlockProofParams) (*tonapi.GetRawShardBlockProofOK, error)
+	GetRawShardInfoFunc                           func(ctx context.Context, params tonapi.GetRawShardInfoParams) (*tonapi.GetRawShardInfoOK, error)
+	GetRawTimeFunc                                func(ctx context.Context) (*tonapi.GetRawTimeOK, error)
+	GetRawTransactionsFunc                        func(ctx context.Context, params tonapi.GetRawTransactionsParams) (*tonapi.GetRawTransactionsOK, error)
+	GetReducedBlockchainBlocksFunc                func(ctx context.Context, params tonapi.GetReducedBlockchainBlocksParams) (*tonapi.ReducedBlocks, error)
+	GetRewardsApyFunc                             func(ctx context.Context) (float64, error)
+	GetRewardsStatsFunc                           func(ctx context.Context) (*tonapi.RewardsStats, error)
+	GetRoundRewardsFunc                           func(ctx context.Context, params tonapi.GetRoundRewardsParams) (*tonapi.RoundRewardsResponse, error)
+	GetStakingPoolHistoryFunc                     func(ctx context.Context, params tonapi.GetStakingPoolHistoryParams) (*tonapi.GetStakingPoolHistoryOK, error)
+	GetStakingPoolInfoFunc                        func(ctx context.Context, params tonapi.GetStakingPoolInfoParams) (*tonapi.GetStakingPoolInfoOK, error)
+	GetStakingPoolsFunc                           func(ctx context.Context, params tonapi.GetStakingPoolsParams) (*tonapi.GetStakingPoolsOK, error)
+	GetStorageProvidersFunc                       func(ctx context.Context) (*tonapi.GetStorageProvidersOK, error)
+	GetTonConnectPayloadFunc                      func(ctx context.Context) (*tonapi.GetTonConnectPayloadOK, error)
+	GetTraceFunc                                  func(ctx context.Context, params tonapi.GetTraceParams) (*tonapi.Trace, error)
+	GetValidationRoundsFunc                       func(ctx context.Context, params tonapi.GetValidationRoundsParams) (*tonapi.ValidationRoundsResponse, error)
+	GetValidatorsFunc                             func(ctx context.Context, params tonapi.GetValidatorsParams) (*tonapi.ValidatorsResponse, error)
+	GetWalletInfoFunc                             func(ctx context.Context, params tonapi.GetWalletInfoParams) (*tonapi.Wallet, error)
+	GetWalletsByPublicKeyFunc                     func(ctx context.Context, params tonapi.GetWalletsByPublicKeyParams) (*tonapi.Wallets, error)
+	GetWalletsByPublicKeyBulkFunc                 func(ctx context.Context, request tonapi.OptGetWalletsByPublicKeyBulkReq) (*tonapi.WalletsByPublicKeys, error)
+	PrepareMigrationFunc                          func(ctx context.Context, request *tonapi.MigrationPrepareRequest) (*tonapi.MigrationPrepareResponse, error)
+	ReindexAccountFunc                            func(ctx context.Context, params tonapi.ReindexAccountParams) error
+	SearchAccountsFunc                            func(ctx context.Context, params tonapi.SearchAccountsParams) (*tonapi.FoundAccounts, error)
+	SendBlockchainMessageFunc                     func(ctx context.Context, request *tonapi.SendBlockchainMessageReq) error
+	SendRawMessageFunc                            func(ctx context.Context, request *tonapi.SendRawMessageReq) (*tonapi.SendRawMessageOK, error)
+	StatusFunc                                    func(ctx context.Context) (*tonapi.ServiceStatus, error)
+	TonConnectProofFunc                           func(ctx context.Context, request *tonapi.TonConnectProofReq) (*tonapi.TonConnectProofOK, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ tonapi.Invoker = (*MockInvoker)(nil)
+
+// AccountDnsBackResolve calls AccountDnsBackResolveFunc.
+func (m *MockInvoker) AccountDnsBackResolve(ctx context.Context, params tonapi.AccountDnsBackResolveParams) (*tonapi.DomainNames, error) {
+	m.record(tonapi.AccountDnsBackResolveOperation, params)
+	if m.AccountDnsBackResolveFunc == nil {
+		return nil, notImplemented(tonapi.AccountDnsBackResolveOperation)
+	}
+	return m.AccountDnsBackResolveFunc(ctx, params)
+}
+
+// AddressParse calls AddressParseFunc.
+func (m *MockInvoker) AddressParse(ctx context.Context, params tonapi.AddressParseParams) (*tonapi.AddressParseOK, error) {
+	m.record(tonapi.AddressParseOperation, params)
+	if m.AddressParseFunc == nil {
+		return nil, notImplemented(tonapi.AddressParseOperation)
+	}
+	return m.AddressParseFunc(ctx, params)
+}
+
+// BlockchainAccountInspect calls BlockchainAccountInspectFunc.
+func (m *MockInvoker) BlockchainAccountInspect(ctx context.Context, params tonapi.BlockchainAccountInspectParams) (*tonapi.BlockchainAccountInspect, error) {
+	m.record(tonapi.BlockchainAccountInspectOperation, params)
+	if m.BlockchainAccountInspectFunc == nil {
+		return nil, notImplemented(tonapi.BlockchainAccountInspectOperation)
+	}
+	return m.BlockchainAccountInspectFunc(ctx, params)
+}
+
+// DecodeMessage calls DecodeMessageFunc.
+func (m *MockInvoker) DecodeMessage(ctx context.Context, request *tonapi.DecodeMessageReq) (*tonapi.DecodedMessage, error) {
+	m.record(tonapi.DecodeMessageOperation, request)
+	if m.DecodeMessageFunc == nil {
+		return nil, notImplemented(tonapi.DecodeMessageOperation)
+	}
+	return m.DecodeMessageFunc(ctx, request)
+}
+
+// DnsResolve calls DnsResolveFunc.
+func (m *MockInvoker) DnsResolve(ctx context.Context, params tonapi.DnsResolveParams) (*tonapi.DnsRecord, error) {
+	m.record(tonapi.DnsResolveOperation, params)
+	if m.DnsResolveFunc == nil {
+		return nil, notImplemented(tonapi.DnsResolveOperation)
+	}
+	return m.DnsResolveFunc(ctx, params)
+}
+
+// DownloadBlockchainBlockBoc calls DownloadBlockchainBlockBocFunc.
+func (m *MockInvoker) DownloadBlockchainBlockBoc(ctx context.Context, params tonapi.DownloadBlockchainBlockBocParams) (*tonapi.DownloadBlockchainBlockBocOKHeaders, error) {
+	m.record(tonapi.DownloadBlockchainBlockBocOperation, params)
+	if m.DownloadBlockchainBlockBocFunc == nil {
+		return nil, notImplemented(tonapi.DownloadBlockchainBlockBocOperation)
+	}
+	return m.DownloadBlockchainBlockBocFunc(ctx, params)
+}
+
+// EmulateMessageToAccountEvent calls EmulateMessageToAccountEventFunc.
+func (m *MockInvoker) EmulateMessageToAccountEvent(ctx context.Context, request *tonapi.EmulateMessageToAccountEventReq, params tonapi.EmulateMessageToAccountEventParams) (*tonapi.AccountEvent, error) {
+	m.record(tonapi.EmulateMessageToAccountEventOperation, request, params)
+	if m.EmulateMessageToAccountEventFunc == nil {
+		return nil, notImplemented(tonapi.EmulateMessageToAccountEventOperation)
+	}
+	return m.EmulateMessageToAccountEventFunc(ctx, request, params)
+}
+
+// EmulateMessageToEvent calls EmulateMessageToEventFunc.
+func (m *MockInvoker) EmulateMessageToEvent(ctx context.Context, request *tonapi.EmulateMessageToEventReq, params tonapi.EmulateMessageToEventParams) (*tonapi.Event, error) {
+	m.record(tonapi.EmulateMessageToEventOperation, request, params)
+	if m.EmulateMessageToEventFunc == nil {
+		return nil, notImplemented(tonapi.EmulateMessageToEventOperation)
+	}
+	return m.EmulateMessageToEventFunc(ctx, request, params)
+}
+
+// EmulateMessageToTrace calls EmulateMessageToTraceFunc.
+func (m *MockInvoker) EmulateMessageToTrace(ctx context.Context, request *tonapi.EmulateMessageToTraceReq, params tonapi.EmulateMessageToTraceParams) (*tonapi.Trace, error) {
+	m.record(tonapi.EmulateMessageToTraceOperation, request, params)
+	if m.EmulateMessageToTraceFunc == nil {
+		return nil, notImplemented(tonapi.EmulateMessageToTraceOperation)
+	}
+	return m.EmulateMessageToTraceFunc(ctx, request, params)
+}
+
+// EmulateMessageToWallet calls EmulateMessageToWalletFunc.
+func (m *MockInvoker) EmulateMessageToWallet(ctx context.Context, request *tonapi.EmulateMessageToWalletReq, params tonapi.EmulateMessageToWalletParams) (*tonapi.MessageConsequences, error) {
+	m.record(tonapi.EmulateMessageToWalletOperation, request, params)
+	if m.EmulateMessageToWalletFunc == nil {
+		return nil, notImplemented(tonapi.EmulateMessageToWalletOperation)
+	}
+	return m.EmulateMessageToWalletFunc(ctx, request, params)
+}
+
+// ExecGetMethodForBlockchainAccount calls ExecGetMethodForBlockchainAccountFunc.
+func (m *MockInvoker) ExecGetMethodForBlockchainAccount(ctx context.Context, params tonapi.ExecGetMethodForBlockchainAccountParams) (*tonapi.MethodExecutionResult, error) {
+	m.record(tonapi.ExecGetMethodForBlockchainAccountOperation, params)
+	if m.ExecGetMethodForBlockchainAccountFunc == nil {
+		return nil, notImplemented(tonapi.ExecGetMethodForBlockchainAccountOperation)
+	}
+	return m.ExecGetMethodForBlockchainAccountFunc(ctx, params)
+}
+
+// ExecGetMethodWithBodyForBlockchainAccount calls ExecGetMethodWithBodyForBlockchainAccountFunc.
+func (m *MockInvoker) ExecGetMethodWithBodyForBlockchainAccount(ctx context.Context, request tonapi.OptExecGetMethodWithBodyForBlockchainAccountReq, params tonapi.ExecGetMethodWithBodyForBlockchainAccountParams) (*tonapi.MethodExecutionResult, error) {
+	m.record(tonapi.ExecGetMethodWithBodyForBlockchainAccountOperation, request, params)
+	if m.ExecGetMethodWithBodyForBlockchainAccountFunc == nil {
+		return nil, notImplemented(tonapi.ExecGetMethodWithBodyForBlockchainAccountOperation)
+	}
+	return m.ExecGetMethodWithBodyForBlockchainAccountFunc(ctx, request, params)
+}
+
+// GaslessConfig calls GaslessConfigFunc.
+func (m *MockInvoker) GaslessConfig(ctx context.Context) (*tonapi.GaslessConfig, error) {
+	m.record(tonapi.GaslessConfigOperation)
+	if m.GaslessConfigFunc == nil {
+		return nil, notImplemented(tonapi.GaslessConfigOperation)
+	}
+	return m.GaslessConfigFunc(ctx)
+}
+
+// GaslessEstimate calls GaslessEstimateFunc.
+func (m *MockInvoker) GaslessEstimate(ctx context.Context, request *tonapi.GaslessEstimateReq, params tonapi.GaslessEstimateParams) (*tonapi.SignRawParams, error) {
+	m.record(tonapi.GaslessEstimateOperation, request, params)
+	if m.GaslessEstimateFunc == nil {
+		return nil, notImplemented(tonapi.GaslessEstimateOperation)
+	}
+	return m.GaslessEstimateFunc(ctx, request, params)
+}
+
+// GaslessSend calls GaslessSendFunc.
+func (m *MockInvoker) GaslessSend(ctx context.Context, request *tonapi.GaslessSendReq) (*tonapi.GaslessTx, error) {
+	m.record(tonapi.GaslessSendOperation, request)
+	if m.GaslessSendFunc == nil {
+		return nil, notImplemented(tonapi.GaslessSendOperation)
+	}
+	return m.GaslessSendFunc(ctx, request)
+}
+
+// GetAccount calls GetAccountFunc.
+func (m *MockInvoker) GetAccount(ctx context.Context, params tonapi.GetAccountParams) (*tonapi.Account, error) {
+	m.record(tonapi.GetAccountOperation, params)
+	if m.GetAccountFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountOperation)
+	}
+	return m.GetAccountFunc(ctx, params)
+}
+
+// GetAccountDefiAssets calls GetAccountDefiAssetsFunc.
+func (m *MockInvoker) GetAccountDefiAssets(ctx context.Context, params tonapi.GetAccountDefiAssetsParams) (*tonapi.DefiAssets, error) {
+	m.record(tonapi.GetAccountDefiAssetsOperation, params)
+	if m.GetAccountDefiAssetsFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountDefiAssetsOperation)
+	}
+	return m.GetAccountDefiAssetsFunc(ctx, params)
+}
+
+// GetAccountDiff calls GetAccountDiffFunc.
+func (m *MockInvoker) GetAccountDiff(ctx context.Context, params tonapi.GetAccountDiffParams) (*tonapi.GetAccountDiffOK, error) {
+	m.record(tonapi.GetAccountDiffOperation, params)
+	if m.GetAccountDiffFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountDiffOperation)
+	}
+	return m.GetAccountDiffFunc(ctx, params)
+}
+
+// GetAccountDnsExpiring calls GetAccountDnsExpiringFunc.
+func (m *MockInvoker) GetAccountDnsExpiring(ctx context.Context, params tonapi.GetAccountDnsExpiringParams) (*tonapi.DnsExpiring, error) {
+	m.record(tonapi.GetAccountDnsExpiringOperation, params)
+	if m.GetAccountDnsExpiringFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountDnsExpiringOperation)
+	}
+	return m.GetAccountDnsExpiringFunc(ctx, params)
+}
+
+// GetAccountEvent calls GetAccountEventFunc.
+func (m *MockInvoker) GetAccountEvent(ctx context.Context, params tonapi.GetAccountEventParams) (*tonapi.AccountEvent, error) {
+	m.record(tonapi.GetAccountEventOperation, params)
+	if m.GetAccountEventFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountEventOperation)
+	}
+	return m.GetAccountEventFunc(ctx, params)
+}
+
+// GetAccountEvents calls GetAccountEventsFunc.
+func (m *MockInvoker) GetAccountEvents(ctx context.Context, params tonapi.GetAccountEventsParams) (*tonapi.AccountEvents, error) {
+	m.record(tonapi.GetAccountEventsOperation, params)
+	if m.GetAccountEventsFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountEventsOperation)
+	}
+	return m.GetAccountEventsFunc(ctx, params)
+}
+
+// GetAccountExtraCurrencyHistoryByID calls GetAccountExtraCurrencyHistoryByIDFunc.
+func (m *MockInvoker) GetAccountExtraCurrencyHistoryByID(ctx context.Context, params tonapi.GetAccountExtraCurrencyHistoryByIDParams) (*tonapi.AccountEvents, error) {
+	m.record(tonapi.GetAccountExtraCurrencyHistoryByIDOperation, params)
+	if m.GetAccountExtraCurrencyHistoryByIDFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountExtraCurrencyHistoryByIDOperation)
+	}
+	return m.GetAccountExtraCurrencyHistoryByIDFunc(ctx, params)
+}
+
+// GetAccountInfoByStateInit calls GetAccountInfoByStateInitFunc.
+func (m *MockInvoker) GetAccountInfoByStateInit(ctx context.Context, request *tonapi.GetAccountInfoByStateInitReq) (*tonapi.AccountInfoByStateInit, error) {
+	m.record(tonapi.GetAccountInfoByStateInitOperation, request)
+	if m.GetAccountInfoByStateInitFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountInfoByStateInitOperation)
+	}
+	return m.GetAccountInfoByStateInitFunc(ctx, request)
+}
+
+// GetAccountJettonBalance calls GetAccountJettonBalanceFunc.
+func (m *MockInvoker) GetAccountJettonBalance(ctx context.Context, params tonapi.GetAccountJettonBalanceParams) (*tonapi.JettonBalance, error) {
+	m.record(tonapi.GetAccountJettonBalanceOperation, params)
+	if m.GetAccountJettonBalanceFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountJettonBalanceOperation)
+	}
+	return m.GetAccountJettonBalanceFunc(ctx, params)
+}
+
+// GetAccountJettonHistoryByID calls GetAccountJettonHistoryByIDFunc.
+func (m *MockInvoker) GetAccountJettonHistoryByID(ctx context.Context, params tonapi.GetAccountJettonHistoryByIDParams) (*tonapi.AccountEvents, error) {
+	m.record(tonapi.GetAccountJettonHistoryByIDOperation, params)
+	if m.GetAccountJettonHistoryByIDFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountJettonHistoryByIDOperation)
+	}
+	return m.GetAccountJettonHistoryByIDFunc(ctx, params)
+}
+
+// GetAccountJettonsBalances calls GetAccountJettonsBalancesFunc.
+func (m *MockInvoker) GetAccountJettonsBalances(ctx context.Context, params tonapi.GetAccountJettonsBalancesParams) (*tonapi.JettonsBalances, error) {
+	m.record(tonapi.GetAccountJettonsBalancesOperation, params)
+	if m.GetAccountJettonsBalancesFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountJettonsBalancesOperation)
+	}
+	return m.GetAccountJettonsBalancesFunc(ctx, params)
+}
+
+// GetAccountJettonsHistory calls GetAccountJettonsHistoryFunc.
+func (m *MockInvoker) GetAccountJettonsHistory(ctx context.Context, params tonapi.GetAccountJettonsHistoryParams) (*tonapi.JettonOperations, error) {
+	m.record(tonapi.GetAccountJettonsHistoryOperation, params)
+	if m.GetAccountJettonsHistoryFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountJettonsHistoryOperation)
+	}
+	return m.GetAccountJettonsHistoryFunc(ctx, params)
+}
+
+// GetAccountMultisigs calls GetAccountMultisigsFunc.
+func (m *MockInvoker) GetAccountMultisigs(ctx context.Context, params tonapi.GetAccountMultisigsParams) (*tonapi.Multisigs, error) {
+	m.record(tonapi.GetAccountMultisigsOperation, params)
+	if m.GetAccountMultisigsFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountMultisigsOperation)
+	}
+	return m.GetAccountMultisigsFunc(ctx, params)
+}
+
+// GetAccountNftHistory calls GetAccountNftHistoryFunc.
+func (m *MockInvoker) GetAccountNftHistory(ctx context.Context, params tonapi.GetAccountNftHistoryParams) (*tonapi.NftOperations, error) {
+	m.record(tonapi.GetAccountNftHistoryOperation, params)
+	if m.GetAccountNftHistoryFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountNftHistoryOperation)
+	}
+	return m.GetAccountNftHistoryFunc(ctx, params)
+}
+
+// GetAccountNftItems calls GetAccountNftItemsFunc.
+func (m *MockInvoker) GetAccountNftItems(ctx context.Context, params tonapi.GetAccountNftItemsParams) (*tonapi.NftItems, error) {
+	m.record(tonapi.GetAccountNftItemsOperation, params)
+	if m.GetAccountNftItemsFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountNftItemsOperation)
+	}
+	return m.GetAccountNftItemsFunc(ctx, params)
+}
+
+// GetAccountNominatorsPools calls GetAccountNominatorsPoolsFunc.
+func (m *MockInvoker) GetAccountNominatorsPools(ctx context.Context, params tonapi.GetAccountNominatorsPoolsParams) (*tonapi.AccountStaking, error) {
+	m.record(tonapi.GetAccountNominatorsPoolsOperation, params)
+	if m.GetAccountNominatorsPoolsFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountNominatorsPoolsOperation)
+	}
+	return m.GetAccountNominatorsPoolsFunc(ctx, params)
+}
+
+// GetAccountPublicKey calls GetAccountPublicKeyFunc.
+func (m *MockInvoker) GetAccountPublicKey(ctx context.Context, params tonapi.GetAccountPublicKeyParams) (*tonapi.GetAccountPublicKeyOK, error) {
+	m.record(tonapi.GetAccountPublicKeyOperation, params)
+	if m.GetAccountPublicKeyFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountPublicKeyOperation)
+	}
+	return m.GetAccountPublicKeyFunc(ctx, params)
+}
+
+// GetAccountSeqno calls GetAccountSeqnoFunc.
+func (m *MockInvoker) GetAccountSeqno(ctx context.Context, params tonapi.GetAccountSeqnoParams) (*tonapi.Seqno, error) {
+	m.record(tonapi.GetAccountSeqnoOperation, params)
+	if m.GetAccountSeqnoFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountSeqnoOperation)
+	}
+	return m.GetAccountSeqnoFunc(ctx, params)
+}
+
+// GetAccountSubscriptions calls GetAccountSubscriptionsFunc.
+func (m *MockInvoker) GetAccountSubscriptions(ctx context.Context, params tonapi.GetAccountSubscriptionsParams) (*tonapi.Subscriptions, error) {
+	m.record(tonapi.GetAccountSubscriptionsOperation, params)
+	if m.GetAccountSubscriptionsFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountSubscriptionsOperation)
+	}
+	return m.GetAccountSubscriptionsFunc(ctx, params)
+}
+
+// GetAccountTraces calls GetAccountTracesFunc.
+func (m *MockInvoker) GetAccountTraces(ctx context.Context, params tonapi.GetAccountTracesParams) (*tonapi.TraceIDs, error) {
+	m.record(tonapi.GetAccountTracesOperation, params)
+	if m.GetAccountTracesFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountTracesOperation)
+	}
+	return m.GetAccountTracesFunc(ctx, params)
+}
+
+// GetAccounts calls GetAccountsFunc.
+func (m *MockInvoker) GetAccounts(ctx context.Context, request tonapi.OptGetAccountsReq, params tonapi.GetAccountsParams) (*tonapi.Accounts, error) {
+	m.record(tonapi.GetAccountsOperation, request, params)
+	if m.GetAccountsFunc == nil {
+		return nil, notImplemented(tonapi.GetAccountsOperation)
+	}
+	return m.GetAccountsFunc(ctx, request, params)
+}
+
+// GetAllAuctions calls GetAllAuctionsFunc.
+func (m *MockInvoker) GetAllAuctions(ctx context.Context, params tonapi.GetAllAuctionsParams) (*tonapi.Auctions, error) {
+	m.record(tonapi.GetAllAuctionsOperation, params)
+	if m.GetAllAuctionsFunc == nil {
+		return nil, notImplemented(tonapi.GetAllAuctionsOperation)
+	}
+	return m.GetAllAuctionsFunc(ctx, params)
+}
+
+// GetAllRawShardsInfo calls GetAllRawShardsInfoFunc.
+func (m *MockInvoker) GetAllRawShardsInfo(ctx context.Context, params tonapi.GetAllRawShardsInfoParams) (*tonapi.GetAllRawShardsInfoOK, error) {
+	m.record(tonapi.GetAllRawShardsInfoOperation, params)
+	if m.GetAllRawShardsInfoFunc == nil {
+		return nil, notImplemented(tonapi.GetAllRawShardsInfoOperation)
+	}
+	return m.GetAllRawShardsInfoFunc(ctx, params)
+}
+
+// GetBlockchainAccountTransactions calls GetBlockchainAccountTransactionsFunc.
+func (m *MockInvoker) GetBlockchainAccountTransactions(ctx context.Context, params tonapi.GetBlockchainAccountTransactionsParams) (*tonapi.Transactions, error) {
+	m.record(tonapi.GetBlockchainAccountTransactionsOperation, params)
+	if m.GetBlockchainAccountTransactionsFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainAccountTransactionsOperation)
+	}
+	return m.GetBlockchainAccountTransactionsFunc(ctx, params)
+}
+
+// GetBlockchainBlock calls GetBlockchainBlockFunc.
+func (m *MockInvoker) GetBlockchainBlock(ctx context.Context, params tonapi.GetBlockchainBlockParams) (*tonapi.BlockchainBlock, error) {
+	m.record(tonapi.GetBlockchainBlockOperation, params)
+	if m.GetBlockchainBlockFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainBlockOperation)
+	}
+	return m.GetBlockchainBlockFunc(ctx, params)
+}
+
+// GetBlockchainBlockTransactions calls GetBlockchainBlockTransactionsFunc.
+func (m *MockInvoker) GetBlockchainBlockTransactions(ctx context.Context, params tonapi.GetBlockchainBlockTransactionsParams) (*tonapi.Transactions, error) {
+	m.record(tonapi.GetBlockchainBlockTransactionsOperation, params)
+	if m.GetBlockchainBlockTransactionsFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainBlockTransactionsOperation)
+	}
+	return m.GetBlockchainBlockTransactionsFunc(ctx, params)
+}
+
+// GetBlockchainConfig calls GetBlockchainConfigFunc.
+func (m *MockInvoker) GetBlockchainConfig(ctx context.Context) (*tonapi.BlockchainConfig, error) {
+	m.record(tonapi.GetBlockchainConfigOperation)
+	if m.GetBlockchainConfigFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainConfigOperation)
+	}
+	return m.GetBlockchainConfigFunc(ctx)
+}
+
+// GetBlockchainConfigFromBlock calls GetBlockchainConfigFromBlockFunc.
+func (m *MockInvoker) GetBlockchainConfigFromBlock(ctx context.Context, params tonapi.GetBlockchainConfigFromBlockParams) (*tonapi.BlockchainConfig, error) {
+	m.record(tonapi.GetBlockchainConfigFromBlockOperation, params)
+	if m.GetBlockchainConfigFromBlockFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainConfigFromBlockOperation)
+	}
+	return m.GetBlockchainConfigFromBlockFunc(ctx, params)
+}
+
+// GetBlockchainMasterchainBlocks calls GetBlockchainMasterchainBlocksFunc.
+func (m *MockInvoker) GetBlockchainMasterchainBlocks(ctx context.Context, params tonapi.GetBlockchainMasterchainBlocksParams) (*tonapi.BlockchainBlocks, error) {
+	m.record(tonapi.GetBlockchainMasterchainBlocksOperation, params)
+	if m.GetBlockchainMasterchainBlocksFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainMasterchainBlocksOperation)
+	}
+	return m.GetBlockchainMasterchainBlocksFunc(ctx, params)
+}
+
+// GetBlockchainMasterchainHead calls GetBlockchainMasterchainHeadFunc.
+func (m *MockInvoker) GetBlockchainMasterchainHead(ctx context.Context) (*tonapi.BlockchainBlock, error) {
+	m.record(tonapi.GetBlockchainMasterchainHeadOperation)
+	if m.GetBlockchainMasterchainHeadFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainMasterchainHeadOperation)
+	}
+	return m.GetBlockchainMasterchainHeadFunc(ctx)
+}
+
+// GetBlockchainMasterchainShards calls GetBlockchainMasterchainShardsFunc.
+func (m *MockInvoker) GetBlockchainMasterchainShards(ctx context.Context, params tonapi.GetBlockchainMasterchainShardsParams) (*tonapi.BlockchainBlockShards, error) {
+	m.record(tonapi.GetBlockchainMasterchainShardsOperation, params)
+	if m.GetBlockchainMasterchainShardsFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainMasterchainShardsOperation)
+	}
+	return m.GetBlockchainMasterchainShardsFunc(ctx, params)
+}
+
+// GetBlockchainMasterchainTransactions calls GetBlockchainMasterchainTransactionsFunc.
+func (m *MockInvoker) GetBlockchainMasterchainTransactions(ctx context.Context, params tonapi.GetBlockchainMasterchainTransactionsParams) (*tonapi.Transactions, error) {
+	m.record(tonapi.GetBlockchainMasterchainTransactionsOperation, params)
+	if m.GetBlockchainMasterchainTransactionsFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainMasterchainTransactionsOperation)
+	}
+	return m.GetBlockchainMasterchainTransactionsFunc(ctx, params)
+}
+
+// GetBlockchainRawAccount calls GetBlockchainRawAccountFunc.
+func (m *MockInvoker) GetBlockchainRawAccount(ctx context.Context, params tonapi.GetBlockchainRawAccountParams) (*tonapi.BlockchainRawAccount, error) {
+	m.record(tonapi.GetBlockchainRawAccountOperation, params)
+	if m.GetBlockchainRawAccountFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainRawAccountOperation)
+	}
+	return m.GetBlockchainRawAccountFunc(ctx, params)
+}
+
+// GetBlockchainRawAccounts calls GetBlockchainRawAccountsFunc.
+func (m *MockInvoker) GetBlockchainRawAccounts(ctx context.Context, request tonapi.OptGetBlockchainRawAccountsReq) (*tonapi.BlockchainRawAccounts, error) {
+	m.record(tonapi.GetBlockchainRawAccountsOperation, request)
+	if m.GetBlockchainRawAccountsFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainRawAccountsOperation)
+	}
+	return m.GetBlockchainRawAccountsFunc(ctx, request)
+}
+
+// GetBlockchainTransaction calls GetBlockchainTransactionFunc.
+func (m *MockInvoker) GetBlockchainTransaction(ctx context.Context, params tonapi.GetBlockchainTransactionParams) (*tonapi.Transaction, error) {
+	m.record(tonapi.GetBlockchainTransactionOperation, params)
+	if m.GetBlockchainTransactionFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainTransactionOperation)
+	}
+	return m.GetBlockchainTransactionFunc(ctx, params)
+}
+
+// GetBlockchainTransactionByMessageHash calls GetBlockchainTransactionByMessageHashFunc.
+func (m *MockInvoker) GetBlockchainTransactionByMessageHash(ctx context.Context, params tonapi.GetBlockchainTransactionByMessageHashParams) (*tonapi.Transaction, error) {
+	m.record(tonapi.GetBlockchainTransactionByMessageHashOperation, params)
+	if m.GetBlockchainTransactionByMessageHashFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainTransactionByMessageHashOperation)
+	}
+	return m.GetBlockchainTransactionByMessageHashFunc(ctx, params)
+}
+
+// GetBlockchainValidators calls GetBlockchainValidatorsFunc.
+func (m *MockInvoker) GetBlockchainValidators(ctx context.Context) (*tonapi.Validators, error) {
+	m.record(tonapi.GetBlockchainValidatorsOperation)
+	if m.GetBlockchainValidatorsFunc == nil {
+		return nil, notImplemented(tonapi.GetBlockchainValidatorsOperation)
+	}
+	return m.GetBlockchainValidatorsFunc(ctx)
+}
+
+// GetChartRates calls GetChartRatesFunc.
+func (m *MockInvoker) GetChartRates(ctx context.Context, params tonapi.GetChartRatesParams) (*tonapi.GetChartRatesOK, error) {
+	m.record(tonapi.GetChartRatesOperation, params)
+	if m.GetChartRatesFunc == nil {
+		return nil, notImplemented(tonapi.GetChartRatesOperation)
+	}
+	return m.GetChartRatesFunc(ctx, params)
+}
+
+// GetDnsInfo calls GetDnsInfoFunc.
+func (m *MockInvoker) GetDnsInfo(ctx context.Context, params tonapi.GetDnsInfoParams) (*tonapi.DomainInfo, error) {
+	m.record(tonapi.GetDnsInfoOperation, params)
+	if m.GetDnsInfoFunc == nil {
+		return nil, notImplemented(tonapi.GetDnsInfoOperation)
+	}
+	return m.GetDnsInfoFunc(ctx, params)
+}
+
+// GetDomainBids calls GetDomainBidsFunc.
+func (m *MockInvoker) GetDomainBids(ctx context.Context, params tonapi.GetDomainBidsParams) (*tonapi.DomainBids, error) {
+	m.record(tonapi.GetDomainBidsOperation, params)
+	if m.GetDomainBidsFunc == nil {
+		return nil, notImplemented(tonapi.GetDomainBidsOperation)
+	}
+	return m.GetDomainBidsFunc(ctx, params)
+}
+
+// GetEvent calls GetEventFunc.
+func (m *MockInvoker) GetEvent(ctx context.Context, params tonapi.GetEventParams) (*tonapi.Event, error) {
+	m.record(tonapi.GetEventOperation, params)
+	if m.GetEventFunc == nil {
+		return nil, notImplemented(tonapi.GetEventOperation)
+	}
+	return m.GetEventFunc(ctx, params)
+}
+
+// GetExtraCurrencyInfo calls GetExtraCurrencyInfoFunc.
+func (m *MockInvoker) GetExtraCurrencyInfo(ctx context.Context, params tonapi.GetExtraCurrencyInfoParams) (*tonapi.EcPreview, error) {
+	m.record(tonapi.GetExtraCurrencyInfoOperation, params)
+	if m.GetExtraCurrencyInfoFunc == nil {
+		return nil, notImplemented(tonapi.GetExtraCurrencyInfoOperation)
+	}
+	return m.GetExtraCurrencyInfoFunc(ctx, params)
+}
+
+// GetItemsFromCollection calls GetItemsFromCollectionFunc.
+func (m *MockInvoker) GetItemsFromCollection(ctx context.Context, params tonapi.GetItemsFromCollectionParams) (*tonapi.NftItems, error) {
+	m.record(tonapi.GetItemsFromCollectionOperation, params)
+	if m.GetItemsFromCollectionFunc == nil {
+		return nil, notImplemented(tonapi.GetItemsFromCollectionOperation)
+	}
+	return m.GetItemsFromCollectionFunc(ctx, params)
+}
+
+// GetJettonAccountHistoryByID calls GetJettonAccountHistoryByIDFunc.
+func (m *MockInvoker) GetJettonAccountHistoryByID(ctx context.Context, params tonapi.GetJettonAccountHistoryByIDParams) (*tonapi.JettonOperations, error) {
+	m.record(tonapi.GetJettonAccountHistoryByIDOperation, params)
+	if m.GetJettonAccountHistoryByIDFunc == nil {
+		return nil, notImplemented(tonapi.GetJettonAccountHistoryByIDOperation)
+	}
+	return m.GetJettonAccountHistoryByIDFunc(ctx, params)
+}
+
+// GetJettonHolders calls GetJettonHoldersFunc.
+func (m *MockInvoker) GetJettonHolders(ctx context.Context, params tonapi.GetJettonHoldersParams) (*tonapi.JettonHolders, error) {
+	m.record(tonapi.GetJettonHoldersOperation, params)
+	if m.GetJettonHoldersFunc == nil {
+		return nil, notImplemented(tonapi.GetJettonHoldersOperation)
+	}
+	return m.GetJettonHoldersFunc(ctx, params)
+}
+
+// GetJettonInfo calls GetJettonInfoFunc.
+func (m *MockInvoker) GetJettonInfo(ctx context.Context, params tonapi.GetJettonInfoParams) (*tonapi.JettonInfo, error) {
+	m.record(tonapi.GetJettonInfoOperation, params)
+	if m.GetJettonInfoFunc == nil {
+		return nil, notImplemented(tonapi.GetJettonInfoOperation)
+	}
+	return m.GetJettonInfoFunc(ctx, params)
+}
+
+// GetJettonInfosByAddresses calls GetJettonInfosByAddressesFunc.
+func (m *MockInvoker) GetJettonInfosByAddresses(ctx context.Context, request tonapi.OptGetJettonInfosByAddressesReq) (*tonapi.Jettons, error) {
+	m.record(tonapi.GetJettonInfosByAddressesOperation, request)
+	if m.GetJettonInfosByAddressesFunc == nil {
+		return nil, notImplemented(tonapi.GetJettonInfosByAddressesOperation)
+	}
+	return m.GetJettonInfosByAddressesFunc(ctx, request)
+}
+
+// GetJettonTransferPayload calls GetJettonTransferPayloadFunc.
+func (m *MockInvoker) GetJettonTransferPayload(ctx context.Context, params tonapi.GetJettonTransferPayloadParams) (*tonapi.JettonTransferPayload, error) {
+	m.record(tonapi.GetJettonTransferPayloadOperation, params)
+	if m.GetJettonTransferPayloadFunc == nil {
+		return nil, notImplemented(tonapi.GetJettonTransferPayloadOperation)
+	}
+	return m.GetJettonTransferPayloadFunc(ctx, params)
+}
+
+// GetJettons calls GetJettonsFunc.
+func (m *MockInvoker) GetJettons(ctx context.Context, params tonapi.GetJettonsParams) (*tonapi.Jettons, error) {
+	m.record(tonapi.GetJettonsOperation, params)
+	if m.GetJettonsFunc == nil {
+		return nil, notImplemented(tonapi.GetJettonsOperation)
+	}
+	return m.GetJettonsFunc(ctx, params)
+}
+
+// GetJettonsEvents calls GetJettonsEventsFunc.
+func (m *MockInvoker) GetJettonsEvents(ctx context.Context, params tonapi.GetJettonsEventsParams) (*tonapi.Event, error) {
+	m.record(tonapi.GetJettonsEventsOperation, params)
+	if m.GetJettonsEventsFunc == nil {
+		return nil, notImplemented(tonapi.GetJettonsEventsOperation)
+	}
+	return m.GetJettonsEventsFunc(ctx, params)
+}
+
+// GetLibraryByHash calls GetLibraryByHashFunc.
+func (m *MockInvoker) GetLibraryByHash(ctx context.Context, params tonapi.GetLibraryByHashParams) (*tonapi.BlockchainLibrary, error) {
+	m.record(tonapi.GetLibraryByHashOperation, params)
+	if m.GetLibraryByHashFunc == nil {
+		return nil, notImplemented(tonapi.GetLibraryByHashOperation)
+	}
+	return m.GetLibraryByHashFunc(ctx, params)
+}
+
+// GetMarketsRates calls GetMarketsRatesFunc.
+func (m *MockInvoker) GetMarketsRates(ctx context.Context) (*tonapi.GetMarketsRatesOK, error) {
+	m.record(tonapi.GetMarketsRatesOperation)
+	if m.GetMarketsRatesFunc == nil {
+		return nil, notImplemented(tonapi.GetMarketsRatesOperation)
+	}
+	return m.GetMarketsRatesFunc(ctx)
+}
+
+// GetMigrationWallets calls GetMigrationWalletsFunc.
+func (m *MockInvoker) GetMigrationWallets(ctx context.Context, request tonapi.OptGetMigrationWalletsReq, params tonapi.GetMigrationWalletsParams) (*tonapi.MigrationWallets, error) {
+	m.record(tonapi.GetMigrationWalletsOperation, request, params)
+	if m.GetMigrationWalletsFunc == nil {
+		return nil, notImplemented(tonapi.GetMigrationWalletsOperation)
+	}
+	return m.GetMigrationWalletsFunc(ctx, request, params)
+}
+
+// GetMultisigAccount calls GetMultisigAccountFunc.
+func (m *MockInvoker) GetMultisigAccount(ctx context.Context, params tonapi.GetMultisigAccountParams) (*tonapi.Multisig, error) {
+	m.record(tonapi.GetMultisigAccountOperation, params)
+	if m.GetMultisigAccountFunc == nil {
+		return nil, notImplemented(tonapi.GetMultisigAccountOperation)
+	}
+	return m.GetMultisigAccountFunc(ctx, params)
+}
+
+// GetMultisigOrder calls GetMultisigOrderFunc.
+func (m *MockInvoker) GetMultisigOrder(ctx context.Context, params tonapi.GetMultisigOrderParams) (*tonapi.MultisigOrder, error) {
+	m.record(tonapi.GetMultisigOrderOperation, params)
+	if m.GetMultisigOrderFunc == nil {
+		return nil, notImplemented(tonapi.GetMultisigOrderOperation)
+	}
+	return m.GetMultisigOrderFunc(ctx, params)
+}
+
+// GetNftCollection calls GetNftCollectionFunc.
+func (m *MockInvoker) GetNftCollection(ctx context.Context, params tonapi.GetNftCollectionParams) (*tonapi.NftCollection, error) {
+	m.record(tonapi.GetNftCollectionOperation, params)
+	if m.GetNftCollectionFunc == nil {
+		return nil, notImplemented(tonapi.GetNftCollectionOperation)
+	}
+	return m.GetNftCollectionFunc(ctx, params)
+}
+
+// GetNftCollectionItemsByAddresses calls GetNftCollectionItemsByAddressesFunc.
+func (m *MockInvoker) GetNftCollectionItemsByAddresses(ctx context.Context, request tonapi.OptGetNftCollectionItemsByAddressesReq) (*tonapi.NftCollections, error) {
+	m.record(tonapi.GetNftCollectionItemsByAddressesOperation, request)
+	if m.GetNftCollectionItemsByAddressesFunc == nil {
+		return nil, notImplemented(tonapi.GetNftCollectionItemsByAddressesOperation)
+	}
+	return m.GetNftCollectionItemsByAddressesFunc(ctx, request)
+}
+
+// GetNftCollections calls GetNftCollectionsFunc.
+func (m *MockInvoker) GetNftCollections(ctx context.Context, params tonapi.GetNftCollectionsParams) (*tonapi.NftCollections, error) {
+	m.record(tonapi.GetNftCollectionsOperation, params)
+	if m.GetNftCollectionsFunc == nil {
+		return nil, notImplemented(tonapi.GetNftCollectionsOperation)
+	}
+	return m.GetNftCollectionsFunc(ctx, params)
+}
+
+// GetNftHistoryByID calls GetNftHistoryByIDFunc.
+func (m *MockInvoker) GetNftHistoryByID(ctx context.Context, params tonapi.GetNftHistoryByIDParams) (*tonapi.AccountEvents, error) {
+	m.record(tonapi.GetNftHistoryByIDOperation, params)
+	if m.GetNftHistoryByIDFunc == nil {
+		return nil, notImplemented(tonapi.GetNftHistoryByIDOperation)
+	}
+	return m.GetNftHistoryByIDFunc(ctx, params)
+}
+
+// GetNftItemByAddress calls GetNftItemByAddressFunc.
+func (m *MockInvoker) GetNftItemByAddress(ctx context.Context, params tonapi.GetNftItemByAddressParams) (*tonapi.NftItem, error) {
+	m.record(tonapi.GetNftItemByAddressOperation, params)
+	if m.GetNftItemByAddressFunc == nil {
+		return nil, notImplemented(tonapi.GetNftItemByAddressOperation)
+	}
+	return m.GetNftItemByAddressFunc(ctx, params)
+}
+
+// GetNftItemsByAddresses calls GetNftItemsByAddressesFunc.
+func (m *MockInvoker) GetNftItemsByAddresses(ctx context.Context, request tonapi.OptGetNftItemsByAddressesReq) (*tonapi.NftItems, error) {
+	m.record(tonapi.GetNftItemsByAddressesOperation, request)
+	if m.GetNftItemsByAddressesFunc == nil {
+		return nil, notImplemented(tonapi.GetNftItemsByAddressesOperation)
+	}
+	return m.GetNftItemsByAddressesFunc(ctx, request)
+}
+
+// GetOpenapiJson calls GetOpenapiJsonFunc.
+func (m *MockInvoker) GetOpenapiJson(ctx context.Context) (jx.Raw, error) {
+	m.record(tonapi.GetOpenapiJsonOperation)
+	if m.GetOpenapiJsonFunc == nil {
+		return *new(jx.Raw), notImplemented(tonapi.GetOpenapiJsonOperation)
+	}
+	return m.GetOpenapiJsonFunc(ctx)
+}
+
+// GetOpenapiYml calls GetOpenapiYmlFunc.
+func (m *MockInvoker) GetOpenapiYml(ctx context.Context) (tonapi.GetOpenapiYmlOK, error) {
+	m.record(tonapi.GetOpenapiYmlOperation)
+	if m.GetOpenapiYmlFunc == nil {
+		return *new(tonapi.GetOpenapiYmlOK), notImplemented(tonapi.GetOpenapiYmlOperation)
+	}
+	return m.GetOpenapiYmlFunc(ctx)
+}
+
+// GetOutMsgQueueSizes calls GetOutMsgQueueSizesFunc.
+func (m *MockInvoker) GetOutMsgQueueSizes(ctx context.Context) (*tonapi.GetOutMsgQueueSizesOK, error) {
+	m.record(tonapi.GetOutMsgQueueSizesOperation)
+	if m.GetOutMsgQueueSizesFunc == nil {
+		return nil, notImplemented(tonapi.GetOutMsgQueueSizesOperation)
+	}
+	return m.GetOutMsgQueueSizesFunc(ctx)
+}
+
+// GetPurchaseHistory calls GetPurchaseHistoryFunc.
+func (m *MockInvoker) GetPurchaseHistory(ctx context.Context, params tonapi.GetPurchaseHistoryParams) (*tonapi.AccountPurchases, error) {
+	m.record(tonapi.GetPurchaseHistoryOperation, params)
+	if m.GetPurchaseHistoryFunc == nil {
+		return nil, notImplemented(tonapi.GetPurchaseHistoryOperation)
+	}
+	return m.GetPurchaseHistoryFunc(ctx, params)
+}
+
+// GetRates calls GetRatesFunc.
+func (m *MockInvoker) GetRates(ctx context.Context, params tonapi.GetRatesParams) (*tonapi.GetRatesOK, error) {
+	m.record(tonapi.GetRatesOperation, params)
+	if m.GetRatesFunc == nil {
+		return nil, notImplemented(tonapi.GetRatesOperation)
+	}
+	return m.GetRatesFunc(ctx, params)
+}
+
+// GetRawAccountState calls GetRawAccountStateFunc.
+func (m *MockInvoker) GetRawAccountState(ctx context.Context, params tonapi.GetRawAccountStateParams) (*tonapi.GetRawAccountStateOK, error) {
+	m.record(tonapi.GetRawAccountStateOperation, params)
+	if m.GetRawAccountStateFunc == nil {
+		return nil, notImplemented(tonapi.GetRawAccountStateOperation)
+	}
+	return m.GetRawAccountStateFunc(ctx, params)
+}
+
+// GetRawBlockProof calls GetRawBlockProofFunc.
+func (m *MockInvoker) GetRawBlockProof(ctx context.Context, params tonapi.GetRawBlockProofParams) (*tonapi.GetRawBlockProofOK, error) {
+	m.record(tonapi.GetRawBlockProofOperation, params)
+	if m.GetRawBlockProofFunc == nil {
+		return nil, notImplemented(tonapi.GetRawBlockProofOperation)
+	}
+	return m.GetRawBlockProofFunc(ctx, params)
+}
+
+// GetRawBlockchainBlock calls GetRawBlockchainBlockFunc.
+func (m *MockInvoker) GetRawBlockchainBlock(ctx context.Context, params tonapi.GetRawBlockchainBlockParams) (*tonapi.GetRawBlockchainBlockOK, error) {
+	m.record(tonapi.GetRawBlockchainBlockOperation, params)
+	if m.GetRawBlockchainBlockFunc == nil {
+		return nil, notImplemented(tonapi.GetRawBlockchainBlockOperation)
+	}
+	return m.GetRawBlockchainBlockFunc(ctx, params)
+}
+
+// GetRawBlockchainBlockHeader calls GetRawBlockchainBlockHeaderFunc.
+func (m *MockInvoker) GetRawBlockchainBlockHeader(ctx context.Context, params tonapi.GetRawBlockchainBlockHeaderParams) (*tonapi.GetRawBlockchainBlockHeaderOK, error) {
+	m.record(tonapi.GetRawBlockchainBlockHeaderOperation, params)
+	if m.GetRawBlockchainBlockHeaderFunc == nil {
+		return nil, notImplemented(tonapi.GetRawBlockchainBlockHeaderOperation)
+	}
+	return m.GetRawBlockchainBlockHeaderFunc(ctx, params)
+}
+
+// GetRawBlockchainBlockState calls GetRawBlockchainBlockStateFunc.
+func (m *MockInvoker) GetRawBlockchainBlockState(ctx context.Context, params tonapi.GetRawBlockchainBlockStateParams) (*tonapi.GetRawBlockchainBlockStateOK, error) {
+	m.record(tonapi.GetRawBlockchainBlockStateOperation, params)
+	if m.GetRawBlockchainBlockStateFunc == nil {
+		return nil, notImplemented(tonapi.GetRawBlockchainBlockStateOperation)
+	}
+	return m.GetRawBlockchainBlockStateFunc(ctx, params)
+}
+
+// GetRawBlockchainConfig calls GetRawBlockchainConfigFunc.
+func (m *MockInvoker) GetRawBlockchainConfig(ctx context.Context) (*tonapi.RawBlockchainConfig, error) {
+	m.record(tonapi.GetRawBlockchainConfigOperation)
+	if m.GetRawBlockchainConfigFunc == nil {
+		return nil, notImplemented(tonapi.GetRawBlockchainConfigOperation)
+	}
+	return m.GetRawBlockchainConfigFunc(ctx)
+}
+
+// GetRawBlockchainConfigFromBlock calls GetRawBlockchainConfigFromBlockFunc.
+func (m *MockInvoker) GetRawBlockchainConfigFromBlock(ctx context.Context, params tonapi.GetRawBlockchainConfigFromBlockParams) (*tonapi.RawBlockchainConfig, error) {
+	m.record(tonapi.GetRawBlockchainConfigFromBlockOperation, params)
+	if m.GetRawBlockchainConfigFromBlockFunc == nil {
+		return nil, notImplemented(tonapi.GetRawBlockchainConfigFromBlockOperation)
+	}
+	return m.GetRawBlockchainConfigFromBlockFunc(ctx, params)
+}
+
+// GetRawConfig calls GetRawConfigFunc.
+func (m *MockInvoker) GetRawConfig(ctx context.Context, params tonapi.GetRawConfigParams) (*tonapi.GetRawConfigOK, error) {
+	m.record(tonapi.GetRawConfigOperation, params)
+	if m.GetRawConfigFunc == nil {
+		return nil, notImplemented(tonapi.GetRawConfigOperation)
+	}
+	return m.GetRawConfigFunc(ctx, params)
+}
+
+// GetRawListBlockTransactions calls GetRawListBlockTransactionsFunc.
+func (m *MockInvoker) GetRawListBlockTransactions(ctx context.Context, params tonapi.GetRawListBlockTransactionsParams) (*tonapi.GetRawListBlockTransactionsOK, error) {
+	m.record(tonapi.GetRawListBlockTransactionsOperation, params)
+	if m.GetRawListBlockTransactionsFunc == nil {
+		return nil, notImplemented(tonapi.GetRawListBlockTransactionsOperation)
+	}
+	return m.GetRawListBlockTransactionsFunc(ctx, params)
+}
+
+// GetRawMasterchainInfo calls GetRawMasterchainInfoFunc.
+func (m *MockInvoker) GetRawMasterchainInfo(ctx context.Context) (*tonapi.GetRawMasterchainInfoOK, error) {
+	m.record(tonapi.GetRawMasterchainInfoOperation)
+	if m.GetRawMasterchainInfoFunc == nil {
+		return nil, notImplemented(tonapi.GetRawMasterchainInfoOperation)
+	}
+	return m.GetRawMasterchainInfoFunc(ctx)
+}
+
+// GetRawMasterchainInfoExt calls GetRawMasterchainInfoExtFunc.
+func (m *MockInvoker) GetRawMasterchainInfoExt(ctx context.Context, params tonapi.GetRawMasterchainInfoExtParams) (*tonapi.GetRawMasterchainInfoExtOK, error) {
+	m.record(tonapi.GetRawMasterchainInfoExtOperation, params)
+	if m.GetRawMasterchainInfoExtFunc == nil {
+		return nil, notImplemented(tonapi.GetRawMasterchainInfoExtOperation)
+	}
+	return m.GetRawMasterchainInfoExtFunc(ctx, params)
+}
+
+// GetRawShardBlockProof calls GetRawShardBlockProofFunc.
+func (m *MockInvoker) GetRawShardBlockProof(ctx context.Context, params tonapi.GetRawShardBlockProofParams) (*tonapi.GetRawShardBlockProofOK, error) {
+	m.record(tonapi.GetRawShardBlockProofOperation, params)
+	if m.GetRawShardBlockProofFunc == nil {
+		return nil, notImplemented(tonapi.GetRawShardBlockProofOperation)
+	}
+	return m.GetRawShardBlockProofFunc(ctx, params)
+}
+
+// GetRawShardInfo calls GetRawShardInfoFunc.
+func (m *MockInvoker) GetRawShardInfo(ctx context.Context, params tonapi.GetRawShardInfoParams) (*tonapi.GetRawShardInfoOK, error) {
+	m.record(tonapi.GetRawShardInfoOperation, params)
+	if m.GetRawShardInfoFunc == nil {
+		return nil, notImplemented(tonapi.GetRawShardInfoOperation)
+	}
+	return m.GetRawShardInfoFunc(ctx, params)
+}
+
+// GetRawTime calls GetRawTimeFunc.
+func (m *MockInvoker) GetRawTime(ctx context.Context) (*tonapi.GetRawTimeOK, error) {
+	m.record(tonapi.GetRawTimeOperation)
+	if m.GetRawTimeFunc == nil {
+		return nil, notImplemented(tonapi.GetRawTimeOperation)
+	}
+	return m.GetRawTimeFunc(ctx)
+}
+
+// GetRawTransactions calls GetRawTransactionsFunc.
+func (m *MockInvoker) GetRawTransactions(ctx context.Context, params tonapi.GetRawTransactionsParams) (*tonapi.GetRawTransactionsOK, error) {
+	m.record(tonapi.GetRawTransactionsOperation, params)
+	if m.GetRawTransactionsFunc == nil {
+		return nil, notImplemented(tonapi.GetRawTransactionsOperation)
+	}
+	return m.GetRawTransactionsFunc(ctx, params)
+}
+
+// GetReducedBlockchainBlocks calls GetReducedBlockchainBlocksFunc.
+func (m *MockInvoker) GetReducedBlockchainBlocks(ctx context.Context, params tonapi.GetReducedBlockchainBlocksParams) (*tonapi.ReducedBlocks, error) {
+	m.record(tonapi.GetReducedBlockchainBlocksOperation, params)
+	if m.GetReducedBlockchainBlocksFunc == nil {
+		return nil, notImplemented(tonapi.GetReducedBlockchainBlocksOperation)
+	}
+	return m.GetReducedBlockchainBlocksFunc(ctx, params)
+}
+
+// GetRewardsApy calls GetRewardsApyFunc.
+func (m *MockInvoker) GetRewardsApy(ctx context.Context) (float64, error) {
+	m.record(tonapi.GetRewardsApyOperation)
+	if m.GetRewardsApyFunc == nil {
+		return *new(float64), notImplemented(tonapi.GetRewardsApyOperation)
+	}
+	return m.GetRewardsApyFunc(ctx)
+}
+
+// GetRewardsStats calls GetRewardsStatsFunc.
+func (m *MockInvoker) GetRewardsStats(ctx context.Context) (*tonapi.RewardsStats, error) {
+	m.record(tonapi.GetRewardsStatsOperation)
+	if m.GetRewardsStatsFunc == nil {
+		return nil, notImplemented(tonapi.GetRewardsStatsOperation)
+	}
+	return m.GetRewardsStatsFunc(ctx)
+}
+
+// GetRoundRewards calls GetRoundRewardsFunc.
+func (m *MockInvoker) GetRoundRewards(ctx context.Context, params tonapi.GetRoundRewardsParams) (*tonapi.RoundRewardsResponse, error) {
+	m.record(tonapi.GetRoundRewardsOperation, params)
+	if m.GetRoundRewardsFunc == nil {
+		return nil, notImplemented(tonapi.GetRoundRewardsOperation)
+	}
+	return m.GetRoundRewardsFunc(ctx, params)
+}
+
+// GetStakingPoolHistory calls GetStakingPoolHistoryFunc.
+func (m *MockInvoker) GetStakingPoolHistory(ctx context.Context, params tonapi.GetStakingPoolHistoryParams) (*tonapi.GetStakingPoolHistoryOK, error) {
+	m.record(tonapi.GetStakingPoolHistoryOperation, params)
+	if m.GetStakingPoolHistoryFunc == nil {
+		return nil, notImplemented(tonapi.GetStakingPoolHistoryOperation)
+	}
+	return m.GetStakingPoolHistoryFunc(ctx, params)
+}
+
+// GetStakingPoolInfo calls GetStakingPoolInfoFunc.
+func (m *MockInvoker) GetStakingPoolInfo(ctx context.Context, params tonapi.GetStakingPoolInfoParams) (*tonapi.GetStakingPoolInfoOK, error) {
+	m.record(tonapi.GetStakingPoolInfoOperation, params)
+	if m.GetStakingPoolInfoFunc == nil {
+		return nil, notImplemented(tonapi.GetStakingPoolInfoOperation)
+	}
+	return m.GetStakingPoolInfoFunc(ctx, params)
+}
+
+// GetStakingPools calls GetStakingPoolsFunc.
+func (m *MockInvoker) GetStakingPools(ctx context.Context, params tonapi.GetStakingPoolsParams) (*tonapi.GetStakingPoolsOK, error) {
+	m.record(tonapi.GetStakingPoolsOperation, params)
+	if m.GetStakingPoolsFunc == nil {
+		return nil, notImplemented(tonapi.GetStakingPoolsOperation)
+	}
+	return m.GetStakingPoolsFunc(ctx, params)
+}
+
+// GetStorageProviders calls GetStorageProvidersFunc.
+func (m *MockInvoker) GetStorageProviders(ctx context.Context) (*tonapi.GetStorageProvidersOK, error) {
+	m.record(tonapi.GetStorageProvidersOperation)
+	if m.GetStorageProvidersFunc == nil {
+		return nil, notImplemented(tonapi.GetStorageProvidersOperation)
+	}
+	return m.GetStorageProvidersFunc(ctx)
+}
+
+// GetTonConnectPayload calls GetTonConnectPayloadFunc.
+func (m *MockInvoker) GetTonConnectPayload(ctx context.Context) (*tonapi.GetTonConnectPayloadOK, error) {
+	m.record(tonapi.GetTonConnectPayloadOperation)
+	if m.GetTonConnectPayloadFunc == nil {
+		return nil, notImplemented(tonapi.GetTonConnectPayloadOperation)
+	}
+	return m.GetTonConnectPayloadFunc(ctx)
+}
+
+// GetTrace calls GetTraceFunc.
+func (m *MockInvoker) GetTrace(ctx context.Context, params tonapi.GetTraceParams) (*tonapi.Trace, error) {
+	m.record(tonapi.GetTraceOperation, params)
+	if m.GetTraceFunc == nil {
+		return nil, notImplemented(tonapi.GetTraceOperation)
+	}
+	return m.GetTraceFunc(ctx, params)
+}
+
+// GetValidationRounds calls GetValidationRoundsFunc.
+func (m *MockInvoker) GetValidationRounds(ctx context.Context, params tonapi.GetValidationRoundsParams) (*tonapi.ValidationRoundsResponse, error) {
+	m.record(tonapi.GetValidationRoundsOperation, params)
+	if m.GetValidationRoundsFunc == nil {
+		return nil, notImplemented(tonapi.GetValidationRoundsOperation)
+	}
+	return m.GetValidationRoundsFunc(ctx, params)
+}
+
+// GetValidators calls GetValidatorsFunc.
+func (m *MockInvoker) GetValidators(ctx context.Context, params tonapi.GetValidatorsParams) (*tonapi.ValidatorsResponse, error) {
+	m.record(tonapi.GetValidatorsOperation, params)
+	if m.GetValidatorsFunc == nil {
+		return nil, notImplemented(tonapi.GetValidatorsOperation)
+	}
+	return m.GetValidatorsFunc(ctx, params)
+}
+
+// GetWalletInfo calls GetWalletInfoFunc.
+func (m *MockInvoker) GetWalletInfo(ctx context.Context, params tonapi.GetWalletInfoParams) (*tonapi.Wallet, error) {
+	m.record(tonapi.GetWalletInfoOperation, params)
+	if m.GetWalletInfoFunc == nil {
+		return nil, notImplemented(tonapi.GetWalletInfoOperation)
+	}
+	return m.GetWalletInfoFunc(ctx, params)
+}
+
+// GetWalletsByPublicKey calls GetWalletsByPublicKeyFunc.
+func (m *MockInvoker) GetWalletsByPublicKey(ctx context.Context, params tonapi.GetWalletsByPublicKeyParams) (*tonapi.Wallets, error) {
+	m.record(tonapi.GetWalletsByPublicKeyOperation, params)
+	if m.GetWalletsByPublicKeyFunc == nil {
+		return nil, notImplemented(tonapi.GetWalletsByPublicKeyOperation)
+	}
+	return m.GetWalletsByPublicKeyFunc(ctx, params)
+}
+
+// GetWalletsByPublicKeyBulk calls GetWalletsByPublicKeyBulkFunc.
+func (m *MockInvoker) GetWalletsByPublicKeyBulk(ctx context.Context, request tonapi.OptGetWalletsByPublicKeyBulkReq) (*tonapi.WalletsByPublicKeys, error) {
+	m.record(tonapi.GetWalletsByPublicKeyBulkOperation, request)
+	if m.GetWalletsByPublicKeyBulkFunc == nil {
+		return nil, notImplemented(tonapi.GetWalletsByPublicKeyBulkOperation)
+	}
+	return m.GetWalletsByPublicKeyBulkFunc(ctx, request)
+}
+
+// PrepareMigration calls PrepareMigrationFunc.
+func (m *MockInvoker) PrepareMigration(ctx context.Context, request *tonapi.MigrationPrepareRequest) (*tonapi.MigrationPrepareResponse, error) {
+	m.record(tonapi.PrepareMigrationOperation, request)
+	if m.PrepareMigrationFunc == nil {
+		return nil, notImplemented(tonapi.PrepareMigrationOperation)
+	}
+	return m.PrepareMigrationFunc(ctx, request)
+}
+
+// ReindexAccount calls ReindexAccountFunc.
+func (m *MockInvoker) ReindexAccount(ctx context.Context, params tonapi.ReindexAccountParams) error {
+	m.record(tonapi.ReindexAccountOperation, params)
+	if m.ReindexAccountFunc == nil {
+		return notImplemented(tonapi.ReindexAccountOperation)
+	}
+	return m.ReindexAccountFunc(ctx, params)
+}
+
+// SearchAccounts calls SearchAccountsFunc.
+func (m *MockInvoker) SearchAccounts(ctx context.Context, params tonapi.SearchAccountsParams) (*tonapi.FoundAccounts, error) {
+	m.record(tonapi.SearchAccountsOperation, params)
+	if m.SearchAccountsFunc == nil {
+		return nil, notImplemented(tonapi.SearchAccountsOperation)
+	}
+	return m.SearchAccountsFunc(ctx, params)
+}
+
+// SendBlockchainMessage calls SendBlockchainMessageFunc.
+func (m *MockInvoker) SendBlockchainMessage(ctx context.Context, request *tonapi.SendBlockchainMessageReq) error {
+	m.record(tonapi.SendBlockchainMessageOperation, request)
+	if m.SendBlockchainMessageFunc == nil {
+		return notImplemented(tonapi.SendBlockchainMessageOperation)
+	}
+	return m.SendBlockchainMessageFunc(ctx, request)
+}
+
+// SendRawMessage calls SendRawMessageFunc.
+func (m *MockInvoker) SendRawMessage(ctx context.Context, request *tonapi.SendRawMessageReq) (*tonapi.SendRawMessageOK, error) {
+	m.record(tonapi.SendRawMessageOperation, request)
+	if m.SendRawMessageFunc == nil {
+		return nil, notImplemented(tonapi.SendRawMessageOperation)
+	}
+	return m.SendRawMessageFunc(ctx, request)
+}
+
+// Status calls StatusFunc.
+func (m *MockInvoker) Status(ctx context.Context) (*tonapi.ServiceStatus, error) {
+	m.record(tonapi.StatusOperation)
+	if m.StatusFunc == nil {
+		return nil, notImplemented(tonapi.StatusOperation)
+	}
+	return m.StatusFunc(ctx)
+}
+
+// TonConnectProof calls TonConnectProofFunc.
+func (m *MockInvoker) TonConnectProof(ctx context.Context, request *tonapi.TonConnectProofReq) (*tonapi.TonConnectProofOK, error) {
+	m.record(tonapi.TonConnectProofOperation, request)
+	if m.TonConnectProofFunc == nil {
+		return nil, notImplemented(tonapi.TonConnectProofOperation)
+	}
+	return m.TonConnectProofFunc(ctx, request)
+}
@@ -0,0 +1,50 @@
+package tonapitest
+
+//go:generate go run ./internal/mockgen -source ../oas_client_gen.go -out invoker_gen.go
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// ErrNotImplemented is wrapped by errors of MockInvoker operations without a function.
+var ErrNotImplemented = errors.New("tonapitest: operation is not implemented")
+
+// Call is a call of a MockInvoker operation.
+type Call struct {
+	Operation tonapi.OperationName
+	// Args are the arguments of the call without the context, usually the request and the params.
+	Args []any
+}
+
+// Calls returns the calls made so far, optionally only of the given operations.
+func (m *MockInvoker) Calls(operations ...tonapi.OperationName) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []Call
+	for _, c := range m.calls {
+		if len(operations) == 0 || containsOperation(operations, c.Operation) {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the recorded calls.
+func (m *MockInvoker) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *MockInvoker) record(operation tonapi.OperationName, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Operation: operation, Args: args})
+}
+
+func notImplemented(operation tonapi.OperationName) error {
+	return fmt.Errorf("%w: %v", ErrNotImplemented, operation)
+}
@@ -0,0 +1,37 @@
+package tonapitest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+func TestMockInvoker(t *testing.T) {
+	mock := &MockInvoker{
+		GetAccountFunc: func(ctx context.Context, params tonapi.GetAccountParams) (*tonapi.Account, error) {
+			return &tonapi.Account{Address: params.AccountID, Balance: 42}, nil
+		},
+	}
+	var invoker tonapi.Invoker = mock
+	ctx := context.Background()
+
+	account, err := invoker.GetAccount(ctx, tonapi.GetAccountParams{AccountID: "0:abc"})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), account.Balance)
+
+	_, err = invoker.GetRates(ctx, tonapi.GetRatesParams{Tokens: []string{"ton"}})
+	require.True(t, errors.Is(err, ErrNotImplemented))
+	require.ErrorContains(t, err, tonapi.GetRatesOperation)
+	_, err = invoker.GetRewardsApy(ctx)
+	require.True(t, errors.Is(err, ErrNotImplemented))
+
+	require.Len(t, mock.Calls(), 3)
+	calls := mock.Calls(tonapi.GetAccountOperation)
+	require.Equal(t, []Call{{Operation: tonapi.GetAccountOperation, Args: []any{tonapi.GetAccountParams{AccountID: "0:abc"}}}}, calls)
+	mock.Reset()
+	require.Empty(t, mock.Calls())
+}
@@ -0,0 +1,441 @@
+package tonapitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	ht "github.com/ogen-go/ogen/http"
+	"github.com/tonkeeper/tongo/ton"
+)
+
+// RecordEnv is the environment variable switching recorders from replaying golden files to recording them.
+const RecordEnv = "TONAPITEST_RECORD"
+
+// redacted replaces secrets in golden files.
+const redacted = "REDACTED"
+
+// RecorderMode tells whether a Recorder records or replays responses.
+type RecorderMode int
+
+const (
+	// RecorderReplay serves responses from the golden file without network.
+	RecorderReplay RecorderMode = iota
+	// RecorderRecord sends requests to the real server and writes the responses to the golden file.
+	RecorderRecord
+)
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(r *Recorder)
+
+// WithRecorderMode sets the mode of the recorder.
+// By default, a recorder records if RecordEnv is set and replays otherwise.
+func WithRecorderMode(mode RecorderMode) RecorderOption {
+	return func(r *Recorder) {
+		r.mode = mode
+	}
+}
+
+// WithRecorderTransport sets the transport used to send requests while recording. Defaults to http.DefaultTransport.
+func WithRecorderTransport(transport http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithRedactedValues replaces the given secrets with "REDACTED" in recorded URLs and bodies.
+// Bearer tokens of the Authorization header and the "token" query parameter are always redacted.
+func WithRedactedValues(secrets ...string) RecorderOption {
+	return func(r *Recorder) {
+		r.secrets = append(r.secrets, secrets...)
+	}
+}
+
+// Recorder is an ht.Client and an http.RoundTripper that records tonapi responses, including SSE streams,
+// into a golden file and replays them deterministically.
+// Requests are matched by operation and normalized parameters: account ids are compared in raw form,
+// the order of query parameters and of comma separated values is ignored and JSON bodies are compared semantically.
+// Websocket connections are not recorded.
+//
+//	rec := tonapitest.NewRecorder(t, "testdata/account.json")
+//	client, err := tonapi.NewClient(tonapi.TonApiURL, tonapi.WithToken(token), tonapi.WithClient(rec))
+//	streaming := tonapi.NewStreamingAPI(tonapi.WithStreamingHTTPClient(rec.HTTPClient()))
+type Recorder struct {
+	t         testing.TB
+	path      string
+	mode      RecorderMode
+	transport http.RoundTripper
+	secrets   []string
+
+	mu           sync.Mutex
+	interactions []*interaction
+	used         map[*interaction]bool
+}
+
+var _ ht.Client = (*Recorder)(nil)
+
+// interaction is a request and its response stored in a golden file.
+type interaction struct {
+	Operation string `json:"operation"`
+	Method    string `json:"method"`
+	URL       string `json:"url"`
+	// RequestBodyEncoding is empty for JSON request bodies stored as is, "text" or "base64" otherwise.
+	RequestBodyEncoding string          `json:"request_body_encoding,omitempty"`
+	RequestBody         json.RawMessage `json:"request_body,omitempty"`
+	Status              int             `json:"status"`
+	ContentType         string          `json:"content_type,omitempty"`
+	// Stream is set for SSE responses, Body then holds the events received until the recording was saved.
+	Stream bool `json:"stream,omitempty"`
+	// BodyEncoding is empty for JSON bodies stored as is, "text" or "base64" otherwise.
+	BodyEncoding string          `json:"body_encoding,omitempty"`
+	Body         json.RawMessage `json:"body,omitempty"`
+
+	key      string
+	recorded []byte
+	// secrets are redacted from the recorded body when it is saved.
+	secrets []string
+}
+
+type goldenFile struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+// NewRecorder returns a recorder of the golden file.
+// When replaying, the file is loaded immediately, when recording, it is written when the test finishes.
+func NewRecorder(t testing.TB, path string, opts ...RecorderOption) *Recorder {
+	r := &Recorder{
+		t:         t,
+		path:      path,
+		transport: http.DefaultTransport,
+		used:      map[*interaction]bool{},
+	}
+	if os.Getenv(RecordEnv) != "" {
+		r.mode = RecorderRecord
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	if r.mode == RecorderRecord {
+		t.Cleanup(func() {
+			if err := r.Save(); err != nil {
+				t.Errorf("tonapitest: %v", err)
+			}
+		})
+		return r
+	}
+	if err := r.load(); err != nil {
+		t.Fatalf("tonapitest: %v", err)
+	}
+	return r
+}
+
+// HTTPClient returns an http.Client using the recorder as transport, to be used with tonapi.WithStreamingHTTPClient.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Do implements ht.Client.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	return r.RoundTrip(req)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if r.mode == RecorderRecord {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	secrets := r.secrets
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		secrets = append(secrets, token)
+	} else if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "bearer "); ok && token != "" {
+		secrets = append(secrets, token)
+	}
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	query := req.URL.Query()
+	if query.Has("token") {
+		query.Set("token", redacted)
+	}
+	u := url.URL{Path: req.URL.Path, RawQuery: query.Encode()}
+	record := &interaction{
+		Operation:   operationOf(req.Method, req.URL.Path),
+		Method:      req.Method,
+		URL:         redact(u.String(), secrets),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Stream:      strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"),
+		secrets:     secrets,
+	}
+	record.RequestBodyEncoding, record.RequestBody = encodeBody([]byte(redact(string(body), secrets)))
+	r.mu.Lock()
+	r.interactions = append(r.interactions, record)
+	r.mu.Unlock()
+
+	if record.Stream {
+		resp.Body = &recordingBody{ReadCloser: resp.Body, recorder: r, interaction: record}
+		return resp, nil
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	record.recorded = data
+	r.mu.Unlock()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
+
+// recordingBody copies the events of an SSE stream into the interaction as they are read.
+type recordingBody struct {
+	io.ReadCloser
+	recorder    *Recorder
+	interaction *interaction
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.recorder.mu.Lock()
+		b.interaction.recorded = append(b.interaction.recorded, p[:n]...)
+		b.recorder.mu.Unlock()
+	}
+	return n, err
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	key := matchKey(req.Method, req.URL.Path, req.URL.Query(), body)
+	r.mu.Lock()
+	var found *interaction
+	for _, i := range r.interactions {
+		if i.key != key {
+			continue
+		}
+		found = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if found != nil {
+		r.used[found] = true
+	}
+	r.mu.Unlock()
+	if found == nil {
+		return nil, fmt.Errorf("tonapitest: no recorded response for %v %v in %v", req.Method, req.URL, r.path)
+	}
+
+	resp := &http.Response{
+		StatusCode: found.Status,
+		Status:     fmt.Sprintf("%d %s", found.Status, http.StatusText(found.Status)),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Request:    req,
+	}
+	if found.ContentType != "" {
+		resp.Header.Set("Content-Type", found.ContentType)
+	}
+	if found.Stream {
+		resp.Body = &replayStream{Reader: bytes.NewReader(found.recorded), ctx: req.Context()}
+	} else {
+		resp.Body = io.NopCloser(bytes.NewReader(found.recorded))
+		resp.ContentLength = int64(len(found.recorded))
+	}
+	return resp, nil
+}
+
+// replayStream returns the recorded events and then blocks like a quiet stream until the request is canceled.
+type replayStream struct {
+	*bytes.Reader
+	ctx context.Context
+}
+
+func (s *replayStream) Read(p []byte) (int, error) {
+	if s.Len() > 0 {
+		return s.Reader.Read(p)
+	}
+	<-s.ctx.Done()
+	return 0, io.EOF
+}
+
+func (s *replayStream) Close() error {
+	return nil
+}
+
+// Save writes the recorded interactions to the golden file. It is called automatically when the test finishes.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	file := goldenFile{Interactions: make([]*interaction, 0, len(r.interactions))}
+	for _, i := range r.interactions {
+		stored := *i
+		stored.BodyEncoding, stored.Body = encodeBody([]byte(redact(string(i.recorded), i.secrets)))
+		file.Interactions = append(file.Interactions, &stored)
+	}
+	r.mu.Unlock()
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+func (r *Recorder) load() error {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("%w, set %v=1 to record it", err, RecordEnv)
+	}
+	var file goldenFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%v: %w", r.path, err)
+	}
+	for _, i := range file.Interactions {
+		u, err := url.Parse(i.URL)
+		if err != nil {
+			return fmt.Errorf("%v: %w", r.path, err)
+		}
+		if i.recorded, err = decodeBody(i.BodyEncoding, i.Body); err != nil {
+			return fmt.Errorf("%v: %w", r.path, err)
+		}
+		body, err := decodeBody(i.RequestBodyEncoding, i.RequestBody)
+		if err != nil {
+			return fmt.Errorf("%v: %w", r.path, err)
+		}
+		i.key = matchKey(i.Method, u.Path, u.Query(), body)
+	}
+	r.interactions = file.Interactions
+	return nil
+}
+
+// operationOf returns the operation name of a request or its path for streaming endpoints.
+func operationOf(method, path string) string {
+	if op, _ := findOperation(method, path); op != nil {
+		return op.Operation
+	}
+	return path
+}
+
+// matchKey normalizes a request into the key used to find its recorded response.
+func matchKey(method, path string, query url.Values, body []byte) string {
+	var parts []string
+	op, params := findOperation(method, path)
+	if op != nil {
+		parts = append(parts, op.Operation)
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			parts = append(parts, name+"="+normalizeValue(params[name]))
+		}
+	} else {
+		parts = append(parts, method+" "+path)
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		if name != "token" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var values []string
+		for _, value := range query[name] {
+			for _, v := range strings.Split(value, ",") {
+				values = append(values, normalizeValue(v))
+			}
+		}
+		sort.Strings(values)
+		parts = append(parts, name+"="+strings.Join(values, ","))
+	}
+	if len(body) > 0 {
+		var v any
+		if json.Unmarshal(body, &v) == nil {
+			body, _ = json.Marshal(v)
+		}
+		parts = append(parts, string(body))
+	}
+	return strings.Join(parts, " ")
+}
+
+// normalizeValue converts account ids to the raw form.
+func normalizeValue(value string) string {
+	if account, err := ton.ParseAccountID(value); err == nil {
+		return account.ToRaw()
+	}
+	return value
+}
+
+func redact(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	return s
+}
+
+func encodeBody(data []byte) (string, json.RawMessage) {
+	switch {
+	case len(data) == 0:
+		return "", nil
+	case json.Valid(data):
+		return "", data
+	case utf8.Valid(data):
+		encoded, _ := json.Marshal(string(data))
+		return "text", encoded
+	default:
+		encoded, _ := json.Marshal(base64.StdEncoding.EncodeToString(data))
+		return "base64", encoded
+	}
+}
+
+func decodeBody(encoding string, data json.RawMessage) ([]byte, error) {
+	switch encoding {
+	case "":
+		return data, nil
+	case "text", "base64":
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		if encoding == "text" {
+			return []byte(s), nil
+		}
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return nil, fmt.Errorf("unknown body encoding %q", encoding)
+}
@@ -0,0 +1,89 @@
+package tonapitest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+func TestRecorder(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	path := filepath.Join(t.TempDir(), "golden.json")
+	token := "secret-token"
+	event := tonapi.TransactionEventData{AccountID: account, Lt: 7, TxHash: "ab"}
+
+	srv := NewServer(t)
+	srv.Handle(tonapi.GetAccountOperation, func(r *Request) (any, error) {
+		return &tonapi.Account{Address: r.PathParam("account_id"), Balance: 42, Status: tonapi.AccountStatusActive}, nil
+	})
+	srv.Respond(tonapi.GetRatesOperation, &tonapi.GetRatesOK{Rates: tonapi.GetRatesOKRates{}})
+
+	rec := NewRecorder(t, path, WithRecorderMode(RecorderRecord))
+	client, err := tonapi.NewClient(srv.URL, tonapi.WithToken(token), tonapi.WithClient(rec))
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = client.GetAccount(ctx, tonapi.GetAccountParams{AccountID: account.ToRaw()})
+	require.NoError(t, err)
+	_, err = client.GetRates(ctx, tonapi.GetRatesParams{Tokens: []string{"ton", account.ToRaw()}, Currencies: []string{"usd"}})
+	require.NoError(t, err)
+
+	streamCtx, stop := context.WithCancel(ctx)
+	received := make(chan tonapi.TransactionEventData, 1)
+	streaming := srv.Streaming(tonapi.WithStreamingHTTPClient(rec.HTTPClient()), tonapi.WithStreamingToken(token))
+	go streaming.SubscribeToTransactions(streamCtx, []string{account.ToRaw()}, nil, func(data tonapi.TransactionEventData) {
+		received <- data
+	})
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 1 }, 5*time.Second, 10*time.Millisecond)
+	srv.PublishTransaction(event)
+	require.Equal(t, event, <-received)
+	stop()
+	require.NoError(t, rec.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), token)
+	srv.Close()
+
+	// the server is gone, responses come from the golden file.
+	replay := NewRecorder(t, path, WithRecorderMode(RecorderReplay))
+	client, err = tonapi.NewClient(tonapi.TonApiURL, &tonapi.Security{}, tonapi.WithClient(replay))
+	require.NoError(t, err)
+	res, err := client.GetAccount(ctx, tonapi.GetAccountParams{AccountID: account.ToHuman(true, false)})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), res.Balance)
+	_, err = client.GetRates(ctx, tonapi.GetRatesParams{Tokens: []string{account.ToRaw(), "ton"}, Currencies: []string{"usd"}})
+	require.NoError(t, err)
+	_, err = client.GetRates(ctx, tonapi.GetRatesParams{Tokens: []string{"ton"}, Currencies: []string{"usd"}})
+	require.Error(t, err)
+
+	streaming = tonapi.NewStreamingAPI(tonapi.WithStreamingHTTPClient(replay.HTTPClient()))
+	go streaming.SubscribeToTransactions(ctx, []string{account.ToRaw()}, nil, func(data tonapi.TransactionEventData) {
+		received <- data
+	})
+	require.Equal(t, event, <-received)
+}
+
+func TestMatchKey(t *testing.T) {
+	raw := "0:1111111111111111111111111111111111111111111111111111111111111111"
+	human := ton.MustParseAccountID(raw).ToHuman(true, false)
+	require.Equal(t,
+		matchKey("GET", "/v2/accounts/"+raw+"/events", map[string][]string{"limit": {"10"}, "sort_order": {"asc"}}, nil),
+		matchKey("GET", "/v2/accounts/"+human+"/events", map[string][]string{"sort_order": {"asc"}, "limit": {"10"}, "token": {"x"}}, nil),
+	)
+	require.Equal(t,
+		matchKey("POST", "/v2/nfts/_bulk", nil, []byte(`{"account_ids": ["a", "b"]}`)),
+		matchKey("POST", "/v2/nfts/_bulk", nil, []byte(`{"account_ids":["a","b"]}`)),
+	)
+	require.NotEqual(t,
+		matchKey("GET", "/v2/accounts/"+raw+"/events", map[string][]string{"limit": {"10"}}, nil),
+		matchKey("GET", "/v2/accounts/"+raw+"/events", map[string][]string{"limit": {"20"}}, nil),
+	)
+}
@@ -0,0 +1,367 @@
+// Package tonapitest provides an in-process fake of tonapi for offline tests.
+//
+// Server serves every operation of api/openapi.yml from programmable fixtures,
+// checks incoming requests against the spec, can inject latency and error responses
+// and fakes the "/v2/sse/*" and "/v2/websocket" streaming endpoints.
+//
+//	srv := tonapitest.NewServer(t)
+//	srv.Respond(tonapi.GetAccountOperation, &tonapi.Account{Address: "0:...", Status: tonapi.AccountStatusActive})
+//	client := srv.Client()
+//	account, err := client.GetAccount(ctx, tonapi.GetAccountParams{AccountID: "0:..."})
+package tonapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// Request is a request received by Server.
+type Request struct {
+	Operation  tonapi.OperationName
+	Method     string
+	Path       string
+	PathParams map[string]string
+	Query      url.Values
+	Header     http.Header
+	Body       []byte
+}
+
+// PathParam returns a path parameter, for example "account_id".
+func (r *Request) PathParam(name string) string {
+	return r.PathParams[name]
+}
+
+// DecodeBody decodes the JSON body of the request into v, usually a pointer to a generated request type.
+func (r *Request) DecodeBody(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// HandlerFunc builds the response to a request.
+// The response is written as JSON, except for []byte which is written as is.
+// A nil response gives an empty 200 response.
+// A returned *Error is written as an error response with its status code, any other error gives a 500 response.
+type HandlerFunc func(r *Request) (any, error)
+
+// Error is an error response in the format of tonapi.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %v", e.StatusCode, e.Message)
+}
+
+// Fault changes how Server responds to requests of an operation.
+type Fault struct {
+	// Latency delays the response.
+	Latency time.Duration
+	// StatusCode, if set, replaces the response with an error response, for example 429 or 503.
+	StatusCode int
+	// RetryAfter sets the Retry-After header of the error response.
+	RetryAfter time.Duration
+	// Times limits the fault to the given number of requests. Zero means all requests.
+	Times int
+}
+
+type fault struct {
+	Fault
+	operation tonapi.OperationName
+	left      int
+}
+
+// Option configures a Server.
+type Option func(s *Server)
+
+// WithLenientValidation makes Server serve requests that violate the spec instead of responding with 400.
+// Violations are still recorded and reported.
+func WithLenientValidation() Option {
+	return func(s *Server) {
+		s.lenient = true
+	}
+}
+
+// WithoutViolationReports stops Server from failing the test on requests that violate the spec.
+// Violations are still recorded and can be checked with Violations.
+func WithoutViolationReports() Option {
+	return func(s *Server) {
+		s.silent = true
+	}
+}
+
+// Server is a fake tonapi server. Its methods are safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the server, to be used as the server URL of tonapi.NewClient.
+	URL string
+
+	t       testing.TB
+	srv     *httptest.Server
+	lenient bool
+	silent  bool
+
+	mu         sync.Mutex
+	handlers   map[tonapi.OperationName]HandlerFunc
+	faults     []*fault
+	requests   []Request
+	violations []string
+
+	streams *streamHub
+}
+
+// NewServer starts a Server that is closed when the test finishes.
+// Requests violating the spec fail the test unless WithoutViolationReports is given.
+func NewServer(t testing.TB, opts ...Option) *Server {
+	s := &Server{
+		t:        t,
+		handlers: map[tonapi.OperationName]HandlerFunc{},
+		streams:  newStreamHub(),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/sse/", s.streams.serveSSE)
+	mux.HandleFunc("/v2/websocket", s.streams.serveWebsocket)
+	mux.HandleFunc("/", s.serveOperation)
+	s.srv = httptest.NewServer(mux)
+	s.URL = s.srv.URL
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close shuts down the server and closes all streaming connections.
+func (s *Server) Close() {
+	s.streams.close()
+	s.srv.Close()
+}
+
+// Client returns a tonapi client of the server.
+func (s *Server) Client(opts ...tonapi.ClientOption) *tonapi.Client {
+	client, err := tonapi.NewClient(s.URL, &tonapi.Security{}, opts...)
+	if err != nil {
+		s.t.Fatalf("tonapitest: %v", err)
+	}
+	return client
+}
+
+// Streaming returns a streaming client of the server.
+func (s *Server) Streaming(opts ...tonapi.StreamingOption) *tonapi.StreamingAPI {
+	return tonapi.NewStreamingAPI(append([]tonapi.StreamingOption{tonapi.WithStreamingEndpoint(s.URL)}, opts...)...)
+}
+
+// Handle sets the handler of an operation, replacing the previous fixture.
+func (s *Server) Handle(operation tonapi.OperationName, handler HandlerFunc) {
+	if !knownOperation(operation) {
+		s.t.Fatalf("tonapitest: unknown operation %q", operation)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[operation] = handler
+}
+
+// Respond makes the operation respond with the same response to every request.
+// The response is a generated tonapi type or a pointer to one, it is encoded like the real API does.
+func (s *Server) Respond(operation tonapi.OperationName, response any) {
+	s.Handle(operation, func(*Request) (any, error) {
+		return response, nil
+	})
+}
+
+// Fail makes the operation respond with an error to every request.
+func (s *Server) Fail(operation tonapi.OperationName, statusCode int, message string) {
+	s.Handle(operation, func(*Request) (any, error) {
+		return nil, &Error{StatusCode: statusCode, Message: message}
+	})
+}
+
+// InjectFault adds a fault to the operation or, if the operation is empty, to all operations.
+// Faults are applied in the order they were added, one fault per request.
+func (s *Server) InjectFault(operation tonapi.OperationName, f Fault) {
+	if operation != "" && !knownOperation(operation) {
+		s.t.Fatalf("tonapitest: unknown operation %q", operation)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{Fault: f, operation: operation, left: f.Times})
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests received so far, optionally only of the given operations.
+func (s *Server) Requests(operations ...tonapi.OperationName) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var requests []Request
+	for _, r := range s.requests {
+		if len(operations) == 0 || containsOperation(operations, r.Operation) {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// Calls returns the number of requests of the operation received so far.
+func (s *Server) Calls(operation tonapi.OperationName) int {
+	return len(s.Requests(operation))
+}
+
+// Violations returns the spec violations of the requests received so far.
+func (s *Server) Violations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.violations...)
+}
+
+func containsOperation(operations []tonapi.OperationName, operation tonapi.OperationName) bool {
+	for _, o := range operations {
+		if o == operation {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) serveOperation(w http.ResponseWriter, r *http.Request) {
+	op, params := findOperation(r.Method, r.URL.Path)
+	if op == nil {
+		s.violation(fmt.Sprintf("%v %v: no such operation", r.Method, r.URL.Path))
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	request := Request{
+		Operation:  op.Operation,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		PathParams: params,
+		Query:      r.URL.Query(),
+		Header:     r.Header.Clone(),
+		Body:       body,
+	}
+	violations := op.validate(&request)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	handler := s.handlers[op.Operation]
+	f := s.takeFault(op.Operation)
+	s.mu.Unlock()
+
+	for _, v := range violations {
+		s.violation(fmt.Sprintf("%v: %v", op.Operation, v))
+	}
+	if len(violations) > 0 && !s.lenient {
+		writeError(w, http.StatusBadRequest, strings.Join(violations, "; "))
+		return
+	}
+	if f != nil {
+		if f.Latency > 0 {
+			select {
+			case <-time.After(f.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if f.StatusCode != 0 {
+			if f.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+			}
+			writeError(w, f.StatusCode, http.StatusText(f.StatusCode))
+			return
+		}
+	}
+	if handler == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Sprintf("tonapitest: no fixture for %v", op.Operation))
+		return
+	}
+	response, err := handler(&request)
+	if err != nil {
+		if e, ok := err.(*Error); ok {
+			writeError(w, e.StatusCode, e.Message)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	switch response := response.(type) {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case []byte:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(response)
+	default:
+		data, err := encodeResponse(response)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+// encodeResponse encodes a handler response as JSON.
+// Generated types implement json.Marshaler with pointer receivers, so values are encoded through a pointer to a copy,
+// otherwise encoding/json would skip their MarshalJSON and produce fields named after the Go struct.
+func encodeResponse(response any) ([]byte, error) {
+	v := reflect.ValueOf(response)
+	if v.Kind() != reflect.Pointer {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		response = ptr.Interface()
+	}
+	return json.Marshal(response)
+}
+
+// takeFault returns the first active fault of the operation. s.mu must be held.
+func (s *Server) takeFault(operation tonapi.OperationName) *fault {
+	for i, f := range s.faults {
+		if f.operation != "" && f.operation != operation {
+			continue
+		}
+		if f.Times > 0 {
+			f.left--
+			if f.left == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) violation(v string) {
+	s.mu.Lock()
+	s.violations = append(s.violations, v)
+	s.mu.Unlock()
+	if !s.silent {
+		s.t.Errorf("tonapitest: %v", v)
+	}
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	data, _ := json.Marshal(map[string]string{"error": message})
+	w.Write(data)
+}
@@ -0,0 +1,146 @@
+package tonapitest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+func TestServer(t *testing.T) {
+	account := "0:1111111111111111111111111111111111111111111111111111111111111111"
+	srv := NewServer(t, WithoutViolationReports())
+	client := srv.Client()
+	ctx := context.Background()
+
+	srv.Handle(tonapi.GetAccountOperation, func(r *Request) (any, error) {
+		return &tonapi.Account{Address: r.PathParam("account_id"), Balance: 42, Status: tonapi.AccountStatusActive}, nil
+	})
+	res, err := client.GetAccount(ctx, tonapi.GetAccountParams{AccountID: account})
+	require.NoError(t, err)
+	require.Equal(t, account, res.Address)
+	require.Equal(t, int64(42), res.Balance)
+
+	// operations without fixtures respond with 501.
+	_, err = client.GetRates(ctx, tonapi.GetRatesParams{Tokens: []string{"ton"}, Currencies: []string{"usd"}})
+	var statusErr *tonapi.ErrorStatusCode
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusNotImplemented, statusErr.StatusCode)
+
+	// requests are checked against the spec.
+	srv.Respond(tonapi.GetAccountEventsOperation, &tonapi.AccountEvents{Events: []tonapi.AccountEvent{}})
+	_, err = client.GetAccountEvents(ctx, tonapi.GetAccountEventsParams{AccountID: account, Limit: 500})
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	require.Equal(t, []string{`GetAccountEvents: query parameter "limit": 500 is greater than 100`}, srv.Violations())
+	_, err = client.GetAccountEvents(ctx, tonapi.GetAccountEventsParams{
+		AccountID: account,
+		Limit:     100,
+		SortOrder: tonapi.NewOptGetAccountEventsSortOrder(tonapi.GetAccountEventsSortOrderAsc),
+	})
+	require.NoError(t, err)
+	requests := srv.Requests(tonapi.GetAccountEventsOperation)
+	require.Len(t, requests, 2)
+	require.Equal(t, "asc", requests[1].Query.Get("sort_order"))
+
+	// faults are applied to the given number of requests.
+	srv.InjectFault(tonapi.GetAccountOperation, Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second, Times: 1})
+	srv.InjectFault("", Fault{Latency: 50 * time.Millisecond, Times: 1})
+	_, err = client.GetAccount(ctx, tonapi.GetAccountParams{AccountID: account})
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+	start := time.Now()
+	_, err = client.GetAccount(ctx, tonapi.GetAccountParams{AccountID: account})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	require.Equal(t, 3, srv.Calls(tonapi.GetAccountOperation))
+	// values are encoded like pointers.
+	srv.Respond(tonapi.GetAccountOperation, tonapi.Account{Address: account, Balance: 7, Status: tonapi.AccountStatusUninit})
+	res, err = client.GetAccount(ctx, tonapi.GetAccountParams{AccountID: account})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), res.Balance)
+	require.Equal(t, tonapi.AccountStatusUninit, res.Status)
+}
+
+func TestServerRoutes(t *testing.T) {
+	op, params := findOperation(http.MethodPost, "/v2/nfts/_bulk")
+	require.Equal(t, tonapi.GetNftItemsByAddressesOperation, op.Operation)
+	require.Empty(t, params)
+
+	op, params = findOperation(http.MethodGet, "/v2/nfts/0:abc")
+	require.Equal(t, tonapi.GetNftItemByAddressOperation, op.Operation)
+	require.Equal(t, map[string]string{"account_id": "0:abc"}, params)
+
+	op, _ = findOperation(http.MethodDelete, "/v2/nfts/0:abc")
+	require.Nil(t, op)
+	require.Len(t, Operations(), len(specOperations))
+}
+
+func TestServerStreaming(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	srv := NewServer(t)
+	streaming := srv.Streaming()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	transactions := make(chan tonapi.TransactionEventData, 16)
+	go streaming.SubscribeToTransactions(ctx, []string{account.ToRaw()}, nil, func(data tonapi.TransactionEventData) {
+		transactions <- data
+	})
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, srv.PublishTransaction(tonapi.TransactionEventData{AccountID: account, Lt: 7, TxHash: "ab"}))
+	require.Equal(t, tonapi.TransactionEventData{AccountID: account, Lt: 7, TxHash: "ab"}, <-transactions)
+
+	blocks := make(chan tonapi.BlockEventData, 1)
+	go streaming.WebsocketHandleRequests(ctx, func(ws tonapi.Websocket) error {
+		ws.SetBlockHandler(func(data tonapi.BlockEventData) {
+			blocks <- data
+		})
+		workchain := -1
+		return ws.SubscribeToBlocks(&workchain)
+	})
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 0, srv.PublishBlock(tonapi.BlockEventData{Workchain: 0, Seqno: 1}))
+	require.Equal(t, 1, srv.PublishBlock(tonapi.BlockEventData{Workchain: -1, Seqno: 2}))
+	require.Equal(t, uint32(2), (<-blocks).Seqno)
+
+	// unsubscribing from some accounts keeps the subscription to the others.
+	other := ton.MustParseAccountID("0:2222222222222222222222222222222222222222222222222222222222222222")
+	steps := make(chan func(ws tonapi.Websocket) error)
+	received := make(chan tonapi.TransactionEventData, 16)
+	go streaming.WebsocketHandleRequests(ctx, func(ws tonapi.Websocket) error {
+		ws.SetTransactionHandler(func(data tonapi.TransactionEventData) {
+			received <- data
+		})
+		for step := range steps {
+			if err := step(ws); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	steps <- func(ws tonapi.Websocket) error {
+		return ws.SubscribeToTransactions([]string{account.ToRaw(), other.ToRaw()}, nil)
+	}
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 3 }, 5*time.Second, 10*time.Millisecond)
+	steps <- func(ws tonapi.Websocket) error { return ws.UnsubscribeFromTransactions([]string{account.ToRaw()}) }
+	// the account is still followed by the SSE subscription.
+	require.Eventually(t, func() bool {
+		return srv.PublishTransaction(tonapi.TransactionEventData{AccountID: account, Lt: 8}) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 3, srv.Subscriptions())
+	require.Equal(t, 1, srv.PublishTransaction(tonapi.TransactionEventData{AccountID: other, Lt: 9}))
+	for data := range received {
+		if data.Lt == 9 {
+			break
+		}
+	}
+	steps <- func(ws tonapi.Websocket) error { return ws.UnsubscribeFromTransactions([]string{other.ToRaw()}) }
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 2 }, 5*time.Second, 10*time.Millisecond)
+}
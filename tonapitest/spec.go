@@ -0,0 +1,211 @@
+package tonapitest
+
+//go:generate go run ./internal/specgen -spec ../api/openapi.yml -out spec_gen.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// specOperation is an operation of api/openapi.yml.
+type specOperation struct {
+	Operation  tonapi.OperationName
+	Method     string
+	Path       string
+	Parameters []specParameter
+	Body       *specBody
+}
+
+// specParameter is a parameter of an operation or, for arrays, the schema of its items.
+type specParameter struct {
+	Name     string
+	In       string
+	Required bool
+	// Delimited is set for query arrays sent as a single comma separated value.
+	Delimited bool
+	Type      string
+	Items     *specParameter
+	Enum      []string
+	Minimum   *float64
+	Maximum   *float64
+}
+
+type specBody struct {
+	Required     bool
+	ContentTypes []string
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+// match returns the path parameters if the path matches the path template of the operation.
+func (op *specOperation) match(method, path string) (map[string]string, bool) {
+	if method != op.Method {
+		return nil, false
+	}
+	template := strings.Split(strings.Trim(op.Path, "/"), "/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(template) != len(parts) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, t := range template {
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			value, err := url.PathUnescape(parts[i])
+			if err != nil || value == "" {
+				return nil, false
+			}
+			params[t[1:len(t)-1]] = value
+			continue
+		}
+		if t != parts[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// findOperation returns the operation serving the request and its path parameters.
+// Templates without parameters win over templates with parameters, like "/v2/nfts/_bulk" over "/v2/nfts/{account_id}".
+func findOperation(method, path string) (*specOperation, map[string]string) {
+	var found *specOperation
+	var foundParams map[string]string
+	for i := range specOperations {
+		op := &specOperations[i]
+		params, ok := op.match(method, path)
+		if !ok {
+			continue
+		}
+		if found == nil || len(params) < len(foundParams) {
+			found, foundParams = op, params
+		}
+	}
+	return found, foundParams
+}
+
+// validate checks the parameters and the body of the request against the spec.
+func (op *specOperation) validate(r *Request) []string {
+	var violations []string
+	known := map[string]struct{}{}
+	for _, p := range op.Parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := r.PathParams[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			known[p.Name] = struct{}{}
+			values = r.Query[p.Name]
+			if p.Delimited && len(values) == 1 {
+				values = strings.Split(values[0], ",")
+			}
+		case "header":
+			values = r.Header.Values(p.Name)
+		}
+		if len(values) == 0 {
+			if p.Required {
+				violations = append(violations, fmt.Sprintf("missing required %v parameter %q", p.In, p.Name))
+			}
+			continue
+		}
+		if p.Type != "array" && len(values) > 1 {
+			violations = append(violations, fmt.Sprintf("%v parameter %q is repeated", p.In, p.Name))
+			continue
+		}
+		schema := &p
+		if p.Type == "array" && p.Items != nil {
+			schema = p.Items
+		}
+		for _, value := range values {
+			if err := schema.check(value); err != nil {
+				violations = append(violations, fmt.Sprintf("%v parameter %q: %v", p.In, p.Name, err))
+			}
+		}
+	}
+	for name := range r.Query {
+		if _, ok := known[name]; !ok {
+			violations = append(violations, fmt.Sprintf("unknown query parameter %q", name))
+		}
+	}
+
+	switch {
+	case op.Body == nil:
+		if len(r.Body) > 0 {
+			violations = append(violations, "unexpected request body")
+		}
+	case len(r.Body) == 0:
+		if op.Body.Required {
+			violations = append(violations, "missing required request body")
+		}
+	default:
+		contentType := r.Header.Get("Content-Type")
+		if i := strings.IndexByte(contentType, ';'); i >= 0 {
+			contentType = contentType[:i]
+		}
+		if !slices.Contains(op.Body.ContentTypes, strings.TrimSpace(contentType)) {
+			violations = append(violations, fmt.Sprintf("unsupported content type %q", contentType))
+		} else if contentType == "application/json" && !json.Valid(r.Body) {
+			violations = append(violations, "request body is not valid JSON")
+		}
+	}
+	return violations
+}
+
+// check validates a single value against the type, enum and range of the schema.
+func (p *specParameter) check(value string) error {
+	var number float64
+	switch p.Type {
+	case "integer":
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		number = float64(v)
+	case "number":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		number = v
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+	}
+	if len(p.Enum) > 0 && !slices.Contains(p.Enum, value) {
+		return fmt.Errorf("%q is not one of %v", value, strings.Join(p.Enum, ", "))
+	}
+	if p.Minimum != nil && number < *p.Minimum {
+		return fmt.Errorf("%v is less than %v", value, *p.Minimum)
+	}
+	if p.Maximum != nil && number > *p.Maximum {
+		return fmt.Errorf("%v is greater than %v", value, *p.Maximum)
+	}
+	return nil
+}
+
+// Operations returns the names of all operations of api/openapi.yml served by Server.
+func Operations() []tonapi.OperationName {
+	names := make([]tonapi.OperationName, 0, len(specOperations))
+	for _, op := range specOperations {
+		names = append(names, op.Operation)
+	}
+	return names
+}
+
+func knownOperation(name tonapi.OperationName) bool {
+	for _, op := range specOperations {
+		if op.Operation == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1007 @@
+// Code generated by specgen from api/openapi.yml, DO NOT EDIT.
+
+package tonapitest
+
+import (
+	"net/http"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+var specOperations = []specOperation{
+	{
+		Operation: tonapi.AccountDnsBackResolveOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/dns/backresolve",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.AddressParseOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/address/{account_id}/parse",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.BlockchainAccountInspectOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/accounts/{account_id}/inspect",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.DecodeMessageOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/message/decode",
+		Body:      &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.DnsResolveOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/dns/{domain_name}/resolve",
+		Parameters: []specParameter{
+			{Name: "domain_name", In: "path", Required: true, Type: "string"},
+			{Name: "filter", In: "query", Type: "boolean"},
+		},
+	},
+	{
+		Operation: tonapi.DownloadBlockchainBlockBocOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/blocks/{block_id}/boc",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.EmulateMessageToAccountEventOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/accounts/{account_id}/events/emulate",
+		Parameters: []specParameter{
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "ignore_signature_check", In: "query", Type: "boolean"},
+		},
+		Body: &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.EmulateMessageToEventOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/events/emulate",
+		Parameters: []specParameter{
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "ignore_signature_check", In: "query", Type: "boolean"},
+		},
+		Body: &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.EmulateMessageToTraceOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/traces/emulate",
+		Parameters: []specParameter{
+			{Name: "ignore_signature_check", In: "query", Type: "boolean"},
+		},
+		Body: &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.EmulateMessageToWalletOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/wallet/emulate",
+		Parameters: []specParameter{
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "currency", In: "query", Type: "string"},
+		},
+		Body: &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.ExecGetMethodForBlockchainAccountOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/accounts/{account_id}/methods/{method_name}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "method_name", In: "path", Required: true, Type: "string"},
+			{Name: "args", In: "query", Type: "array", Items: &specParameter{Name: "args", Type: "string"}},
+		},
+	},
+	{
+		Operation: tonapi.ExecGetMethodWithBodyForBlockchainAccountOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/blockchain/accounts/{account_id}/methods/{method_name}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "method_name", In: "path", Required: true, Type: "string"},
+		},
+		Body: &specBody{Required: false, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GaslessConfigOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/gasless/config",
+	},
+	{
+		Operation: tonapi.GaslessEstimateOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/gasless/estimate/{master_id}",
+		Parameters: []specParameter{
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "master_id", In: "path", Required: true, Type: "string"},
+		},
+		Body: &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GaslessSendOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/gasless/send",
+		Body:      &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GetAccountOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountDefiAssetsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/defi/assets",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountDiffOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/diff",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "start_date", In: "query", Required: true, Type: "integer", Maximum: ptr(2114380800)},
+			{Name: "end_date", In: "query", Required: true, Type: "integer", Maximum: ptr(2114380800)},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountDnsExpiringOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/dns/expiring",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "period", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(3660)},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountEventOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/events/{event_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "event_id", In: "path", Required: true, Type: "string"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "subject_only", In: "query", Type: "boolean"},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountEventsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/events",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "initiator", In: "query", Delimited: true, Type: "boolean"},
+			{Name: "subject_only", In: "query", Type: "boolean"},
+			{Name: "after_lt", In: "query", Type: "integer"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Required: true, Type: "integer", Minimum: ptr(1), Maximum: ptr(100)},
+			{Name: "start_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+			{Name: "end_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+			{Name: "sort_order", In: "query", Type: "string", Enum: []string{"desc", "asc"}},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountExtraCurrencyHistoryByIDOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/extra-currency/{id}/history",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "id", In: "path", Required: true, Type: "integer"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Required: true, Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "start_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+			{Name: "end_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountInfoByStateInitOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/tonconnect/stateinit",
+		Body:      &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GetAccountJettonBalanceOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/jettons/{jetton_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "jetton_id", In: "path", Required: true, Type: "string"},
+			{Name: "currencies", In: "query", Delimited: true, Type: "array", Items: &specParameter{Name: "currencies", Type: "string"}},
+			{Name: "supported_extensions", In: "query", Delimited: true, Type: "array", Items: &specParameter{Name: "supported_extensions", Type: "string"}},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountJettonHistoryByIDOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/jettons/{jetton_id}/history",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "jetton_id", In: "path", Required: true, Type: "string"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Required: true, Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "start_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+			{Name: "end_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountJettonsBalancesOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/jettons",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "currencies", In: "query", Delimited: true, Type: "array", Items: &specParameter{Name: "currencies", Type: "string"}},
+			{Name: "supported_extensions", In: "query", Delimited: true, Type: "array", Items: &specParameter{Name: "supported_extensions", Type: "string"}},
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "offset", In: "query", Type: "integer", Minimum: ptr(0)},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountJettonsHistoryOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/jettons/history",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Required: true, Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountMultisigsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/multisigs",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountNftHistoryOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/nfts/history",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Required: true, Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountNftItemsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/nfts",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "collection", In: "query", Type: "string"},
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "offset", In: "query", Type: "integer", Minimum: ptr(0)},
+			{Name: "indirect_ownership", In: "query", Type: "boolean"},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountNominatorsPoolsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/staking/nominator/{account_id}/pools",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountPublicKeyOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/publickey",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountSeqnoOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/wallet/{account_id}/seqno",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountSubscriptionsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/subscriptions",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountTracesOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/{account_id}/traces",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+		},
+	},
+	{
+		Operation: tonapi.GetAccountsOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/accounts/_bulk",
+		Parameters: []specParameter{
+			{Name: "currency", In: "query", Type: "string"},
+		},
+		Body: &specBody{Required: false, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GetAllAuctionsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/dns/auctions",
+		Parameters: []specParameter{
+			{Name: "tld", In: "query", Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetAllRawShardsInfoOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_all_shards_info/{block_id}",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainAccountTransactionsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/accounts/{account_id}/transactions",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "after_lt", In: "query", Type: "integer"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "sort_order", In: "query", Type: "string", Enum: []string{"desc", "asc"}},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainBlockOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/blocks/{block_id}",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainBlockTransactionsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/blocks/{block_id}/transactions",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainConfigOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/config",
+	},
+	{
+		Operation: tonapi.GetBlockchainConfigFromBlockOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/masterchain/{masterchain_seqno}/config",
+		Parameters: []specParameter{
+			{Name: "masterchain_seqno", In: "path", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainMasterchainBlocksOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/masterchain/{masterchain_seqno}/blocks",
+		Parameters: []specParameter{
+			{Name: "masterchain_seqno", In: "path", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainMasterchainHeadOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/masterchain-head",
+	},
+	{
+		Operation: tonapi.GetBlockchainMasterchainShardsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/masterchain/{masterchain_seqno}/shards",
+		Parameters: []specParameter{
+			{Name: "masterchain_seqno", In: "path", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainMasterchainTransactionsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/masterchain/{masterchain_seqno}/transactions",
+		Parameters: []specParameter{
+			{Name: "masterchain_seqno", In: "path", Required: true, Type: "integer"},
+			{Name: "offset", In: "query", Type: "integer", Minimum: ptr(0)},
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1)},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainRawAccountOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/accounts/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainRawAccountsOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/blockchain/accounts/_bulk",
+		Body:      &specBody{Required: false, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GetBlockchainTransactionOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/transactions/{transaction_id}",
+		Parameters: []specParameter{
+			{Name: "transaction_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainTransactionByMessageHashOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/messages/{msg_id}/transaction",
+		Parameters: []specParameter{
+			{Name: "msg_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetBlockchainValidatorsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/validators",
+	},
+	{
+		Operation: tonapi.GetChartRatesOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/rates/chart",
+		Parameters: []specParameter{
+			{Name: "token", In: "query", Required: true, Type: "string"},
+			{Name: "currency", In: "query", Type: "string"},
+			{Name: "start_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+			{Name: "end_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+			{Name: "points_count", In: "query", Type: "integer", Minimum: ptr(0), Maximum: ptr(200)},
+		},
+	},
+	{
+		Operation: tonapi.GetDnsInfoOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/dns/{domain_name}",
+		Parameters: []specParameter{
+			{Name: "domain_name", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetDomainBidsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/dns/{domain_name}/bids",
+		Parameters: []specParameter{
+			{Name: "domain_name", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetEventOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/events/{event_id}",
+		Parameters: []specParameter{
+			{Name: "event_id", In: "path", Required: true, Type: "string"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetExtraCurrencyInfoOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/extra-currency/{id}",
+		Parameters: []specParameter{
+			{Name: "id", In: "path", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetItemsFromCollectionOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/nfts/collections/{account_id}/items",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "offset", In: "query", Type: "integer", Minimum: ptr(0)},
+		},
+	},
+	{
+		Operation: tonapi.GetJettonAccountHistoryByIDOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/jettons/{jetton_id}/accounts/{account_id}/history",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "jetton_id", In: "path", Required: true, Type: "string"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Required: true, Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "start_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+			{Name: "end_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+		},
+	},
+	{
+		Operation: tonapi.GetJettonHoldersOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/jettons/{account_id}/holders",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "offset", In: "query", Type: "integer", Minimum: ptr(0), Maximum: ptr(9000)},
+		},
+	},
+	{
+		Operation: tonapi.GetJettonInfoOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/jettons/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetJettonInfosByAddressesOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/jettons/_bulk",
+		Body:      &specBody{Required: false, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GetJettonTransferPayloadOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/jettons/{jetton_id}/transfer/{account_id}/payload",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "jetton_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetJettonsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/jettons",
+		Parameters: []specParameter{
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "offset", In: "query", Type: "integer", Minimum: ptr(0)},
+			{Name: "last_account_id", In: "query", Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetJettonsEventsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/events/{event_id}/jettons",
+		Parameters: []specParameter{
+			{Name: "event_id", In: "path", Required: true, Type: "string"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetLibraryByHashOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/libraries/{hash}",
+		Parameters: []specParameter{
+			{Name: "hash", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetMarketsRatesOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/rates/markets",
+	},
+	{
+		Operation: tonapi.GetMigrationWalletsOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/migration/wallets",
+		Parameters: []specParameter{
+			{Name: "currencies", In: "query", Delimited: true, Type: "array", Items: &specParameter{Name: "currencies", Type: "string"}},
+		},
+		Body: &specBody{Required: false, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GetMultisigAccountOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/multisig/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetMultisigOrderOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/multisig/order/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetNftCollectionOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/nfts/collections/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetNftCollectionItemsByAddressesOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/nfts/collections/_bulk",
+		Body:      &specBody{Required: false, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GetNftCollectionsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/nfts/collections",
+		Parameters: []specParameter{
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "offset", In: "query", Type: "integer", Minimum: ptr(0)},
+		},
+	},
+	{
+		Operation: tonapi.GetNftHistoryByIDOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/nfts/{account_id}/history",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Required: true, Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+			{Name: "start_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+			{Name: "end_date", In: "query", Type: "integer", Maximum: ptr(2114380800)},
+		},
+	},
+	{
+		Operation: tonapi.GetNftItemByAddressOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/nfts/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetNftItemsByAddressesOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/nfts/_bulk",
+		Body:      &specBody{Required: false, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.GetOpenapiJsonOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/openapi.json",
+	},
+	{
+		Operation: tonapi.GetOpenapiYmlOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/openapi.yml",
+	},
+	{
+		Operation: tonapi.GetOutMsgQueueSizesOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_out_msg_queue_sizes",
+	},
+	{
+		Operation: tonapi.GetPurchaseHistoryOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/purchases/{account_id}/history",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(1000)},
+		},
+	},
+	{
+		Operation: tonapi.GetRatesOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/rates",
+		Parameters: []specParameter{
+			{Name: "tokens", In: "query", Required: true, Delimited: true, Type: "array", Items: &specParameter{Name: "tokens", Type: "string"}},
+			{Name: "currencies", In: "query", Required: true, Delimited: true, Type: "array", Items: &specParameter{Name: "currencies", Type: "string"}},
+		},
+	},
+	{
+		Operation: tonapi.GetRawAccountStateOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_account_state/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "target_block", In: "query", Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawBlockProofOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_block_proof",
+		Parameters: []specParameter{
+			{Name: "known_block", In: "query", Required: true, Type: "string"},
+			{Name: "target_block", In: "query", Type: "string"},
+			{Name: "mode", In: "query", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawBlockchainBlockOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_block/{block_id}",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawBlockchainBlockHeaderOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_block_header/{block_id}",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+			{Name: "mode", In: "query", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawBlockchainBlockStateOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_state/{block_id}",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawBlockchainConfigOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/config/raw",
+	},
+	{
+		Operation: tonapi.GetRawBlockchainConfigFromBlockOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/masterchain/{masterchain_seqno}/config/raw",
+		Parameters: []specParameter{
+			{Name: "masterchain_seqno", In: "path", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawConfigOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_config_all/{block_id}",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+			{Name: "mode", In: "query", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawListBlockTransactionsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/list_block_transactions/{block_id}",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+			{Name: "mode", In: "query", Required: true, Type: "integer"},
+			{Name: "count", In: "query", Required: true, Type: "integer"},
+			{Name: "account_id", In: "query", Delimited: true, Type: "string"},
+			{Name: "lt", In: "query", Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawMasterchainInfoOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_masterchain_info",
+	},
+	{
+		Operation: tonapi.GetRawMasterchainInfoExtOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_masterchain_info_ext",
+		Parameters: []specParameter{
+			{Name: "mode", In: "query", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawShardBlockProofOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_shard_block_proof/{block_id}",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawShardInfoOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_shard_info/{block_id}",
+		Parameters: []specParameter{
+			{Name: "block_id", In: "path", Required: true, Type: "string"},
+			{Name: "workchain", In: "query", Required: true, Type: "integer"},
+			{Name: "shard", In: "query", Required: true, Type: "integer"},
+			{Name: "exact", In: "query", Required: true, Type: "boolean"},
+		},
+	},
+	{
+		Operation: tonapi.GetRawTimeOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_time",
+	},
+	{
+		Operation: tonapi.GetRawTransactionsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/liteserver/get_transactions/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "count", In: "query", Required: true, Type: "integer"},
+			{Name: "lt", In: "query", Required: true, Type: "integer"},
+			{Name: "hash", In: "query", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetReducedBlockchainBlocksOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/blockchain/reduced/blocks",
+		Parameters: []specParameter{
+			{Name: "from", In: "query", Required: true, Type: "integer"},
+			{Name: "to", In: "query", Required: true, Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetRewardsApyOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/rewards/apy",
+	},
+	{
+		Operation: tonapi.GetRewardsStatsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/rewards/stats",
+	},
+	{
+		Operation: tonapi.GetRoundRewardsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/rewards/round-rewards",
+		Parameters: []specParameter{
+			{Name: "election_id", In: "query", Type: "integer"},
+			{Name: "block", In: "query", Type: "integer"},
+			{Name: "unixtime", In: "query", Type: "integer"},
+			{Name: "shallow", In: "query", Type: "boolean"},
+		},
+	},
+	{
+		Operation: tonapi.GetStakingPoolHistoryOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/staking/pool/{account_id}/history",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "before_lt", In: "query", Type: "integer"},
+			{Name: "limit", In: "query", Type: "integer", Minimum: ptr(1), Maximum: ptr(100)},
+		},
+	},
+	{
+		Operation: tonapi.GetStakingPoolInfoOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/staking/pool/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetStakingPoolsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/staking/pools",
+		Parameters: []specParameter{
+			{Name: "available_for", In: "query", Type: "string"},
+			{Name: "include_unverified", In: "query", Type: "boolean"},
+			{Name: "Accept-Language", In: "header", Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetStorageProvidersOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/storage/providers",
+	},
+	{
+		Operation: tonapi.GetTonConnectPayloadOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/tonconnect/payload",
+	},
+	{
+		Operation: tonapi.GetTraceOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/traces/{trace_id}",
+		Parameters: []specParameter{
+			{Name: "trace_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetValidationRoundsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/rewards/validation-rounds",
+		Parameters: []specParameter{
+			{Name: "election_id", In: "query", Type: "integer"},
+			{Name: "block", In: "query", Type: "integer"},
+			{Name: "unixtime", In: "query", Type: "integer"},
+		},
+	},
+	{
+		Operation: tonapi.GetValidatorsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/rewards/validators",
+		Parameters: []specParameter{
+			{Name: "seqno", In: "query", Type: "integer"},
+			{Name: "unixtime", In: "query", Type: "integer"},
+			{Name: "shallow", In: "query", Type: "boolean"},
+		},
+	},
+	{
+		Operation: tonapi.GetWalletInfoOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/wallet/{account_id}",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetWalletsByPublicKeyOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/pubkeys/{public_key}/wallets",
+		Parameters: []specParameter{
+			{Name: "public_key", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.GetWalletsByPublicKeyBulkOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/pubkeys/wallets/_bulk",
+		Body:      &specBody{Required: false, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.PrepareMigrationOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/migration/prepare",
+		Body:      &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.ReindexAccountOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/accounts/{account_id}/reindex",
+		Parameters: []specParameter{
+			{Name: "account_id", In: "path", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.SearchAccountsOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/accounts/search",
+		Parameters: []specParameter{
+			{Name: "name", In: "query", Required: true, Type: "string"},
+		},
+	},
+	{
+		Operation: tonapi.SendBlockchainMessageOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/blockchain/message",
+		Body:      &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.SendRawMessageOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/liteserver/send_message",
+		Body:      &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+	{
+		Operation: tonapi.StatusOperation,
+		Method:    http.MethodGet,
+		Path:      "/v2/status",
+	},
+	{
+		Operation: tonapi.TonConnectProofOperation,
+		Method:    http.MethodPost,
+		Path:      "/v2/wallet/auth/proof",
+		Body:      &specBody{Required: true, ContentTypes: []string{"application/json"}},
+	},
+}
@@ -0,0 +1,418 @@
+package tonapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/tonkeeper/tongo/ton"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// Streams of the streaming endpoints, named after the SSE paths.
+const (
+	streamTransactions = "transactions"
+	streamTraces       = "traces"
+	streamMempool      = "mempool"
+	streamBlocks       = "blocks"
+)
+
+// websocketMethods maps streams to the methods of websocket notifications.
+var websocketMethods = map[string]string{
+	streamTransactions: "account_transaction",
+	streamTraces:       "trace",
+	streamMempool:      "mempool_message",
+	streamBlocks:       "block",
+}
+
+// websocketTopics maps the topics of subscribe and unsubscribe methods to streams.
+var websocketTopics = map[string]string{
+	"account": streamTransactions,
+	"trace":   streamTraces,
+	"mempool": streamMempool,
+	"block":   streamBlocks,
+}
+
+// subscription is an SSE connection or a subscription of a websocket connection.
+type subscription struct {
+	stream string
+	// accounts limits the subscription to events of the accounts, nil means all events.
+	accounts  map[ton.AccountID]struct{}
+	workchain *int32
+	send      func(stream string, data []byte) bool
+}
+
+func (s *subscription) matches(accounts []ton.AccountID, workchain int32) bool {
+	if s.workchain != nil && *s.workchain != workchain {
+		return false
+	}
+	if s.accounts == nil {
+		return true
+	}
+	for _, account := range accounts {
+		if _, ok := s.accounts[account]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+type streamHub struct {
+	mu            sync.Mutex
+	subscriptions map[*subscription]struct{}
+	conns         map[*websocket.Conn]struct{}
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{
+		subscriptions: map[*subscription]struct{}{},
+		conns:         map[*websocket.Conn]struct{}{},
+		done:          make(chan struct{}),
+	}
+}
+
+func (h *streamHub) add(s *subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscriptions[s] = struct{}{}
+}
+
+func (h *streamHub) remove(s *subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscriptions, s)
+}
+
+func (h *streamHub) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscriptions)
+}
+
+// publish sends an event to the matching subscriptions and returns the number of deliveries.
+func (h *streamHub) publish(stream string, event any, accounts []ton.AccountID, workchain int32) int {
+	data, err := json.Marshal(event)
+	if err != nil {
+		panic(err)
+	}
+	h.mu.Lock()
+	var targets []*subscription
+	for s := range h.subscriptions {
+		if s.stream == stream && s.matches(accounts, workchain) {
+			targets = append(targets, s)
+		}
+	}
+	h.mu.Unlock()
+	delivered := 0
+	for _, s := range targets {
+		if s.send(stream, data) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+func (h *streamHub) close() {
+	h.closeOnce.Do(func() {
+		close(h.done)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for conn := range h.conns {
+			conn.Close()
+		}
+	})
+}
+
+// dropWebsockets closes the websocket connections without stopping the hub.
+func (h *streamHub) dropWebsockets() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conn := range h.conns {
+		conn.Close()
+	}
+}
+
+// serveSSE serves "/v2/sse/accounts/transactions", "/v2/sse/accounts/traces", "/v2/sse/mempool" and "/v2/sse/blocks".
+func (h *streamHub) serveSSE(w http.ResponseWriter, r *http.Request) {
+	sub := &subscription{}
+	query := r.URL.Query()
+	switch r.URL.Path {
+	case "/v2/sse/accounts/transactions":
+		sub.stream = streamTransactions
+	case "/v2/sse/accounts/traces":
+		sub.stream = streamTraces
+	case "/v2/sse/mempool":
+		sub.stream = streamMempool
+	case "/v2/sse/blocks":
+		sub.stream = streamBlocks
+		if value := query.Get("workchain"); value != "" {
+			workchain, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid workchain")
+				return
+			}
+			sub.workchain = new(int32)
+			*sub.workchain = int32(workchain)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if value := query.Get("accounts"); value != "" {
+		accounts, err := parseAccounts(strings.Split(value, ","))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		sub.accounts = accounts
+	} else if sub.stream == streamTransactions || sub.stream == streamTraces {
+		writeError(w, http.StatusBadRequest, "accounts are required")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	events := make(chan []byte)
+	sub.send = func(stream string, data []byte) bool {
+		select {
+		case events <- data:
+			return true
+		case <-r.Context().Done():
+			return false
+		case <-h.done:
+			return false
+		}
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	h.add(sub)
+	defer h.remove(sub)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.done:
+			return
+		case data := <-events:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// serveWebsocket serves the JSON-RPC protocol of "/v2/websocket".
+func (h *streamHub) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	select {
+	case <-h.done:
+		h.mu.Unlock()
+		conn.Close()
+		return
+	default:
+	}
+	h.conns[conn] = struct{}{}
+	h.mu.Unlock()
+
+	var writeMu sync.Mutex
+	write := func(v any) bool {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteJSON(v) == nil
+	}
+	subscriptions := map[string][]*subscription{}
+	defer func() {
+		for _, subs := range subscriptions {
+			for _, sub := range subs {
+				h.remove(sub)
+			}
+		}
+		h.mu.Lock()
+		delete(h.conns, conn)
+		h.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		var request tonapi.JsonRPCRequest
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+		method, topic, _ := strings.Cut(request.Method, "_")
+		stream, ok := websocketTopics[topic]
+		if !ok || (method != "subscribe" && method != "unsubscribe") {
+			write(tonapi.JsonRPCResponse{ID: request.ID, JSONRPC: "2.0", Result: jsonString("unknown method")})
+			continue
+		}
+		if method == "unsubscribe" {
+			remaining, err := h.unsubscribe(subscriptions[stream], request.Params)
+			if err != nil {
+				write(tonapi.JsonRPCResponse{ID: request.ID, JSONRPC: "2.0", Result: jsonString(err.Error())})
+				continue
+			}
+			subscriptions[stream] = remaining
+			write(tonapi.JsonRPCResponse{ID: request.ID, JSONRPC: "2.0", Result: jsonString("success! unsubscribed")})
+			continue
+		}
+		sub, err := websocketSubscription(stream, request.Params)
+		if err != nil {
+			write(tonapi.JsonRPCResponse{ID: request.ID, JSONRPC: "2.0", Result: jsonString(err.Error())})
+			continue
+		}
+		sub.send = func(stream string, data []byte) bool {
+			return write(tonapi.JsonRPCResponse{JSONRPC: "2.0", Method: websocketMethods[stream], Params: data})
+		}
+		subscriptions[stream] = append(subscriptions[stream], sub)
+		h.add(sub)
+		write(tonapi.JsonRPCResponse{ID: request.ID, JSONRPC: "2.0", Result: jsonString("success! subscribed")})
+	}
+}
+
+// unsubscribe removes the accounts listed in the params of an unsubscribe request from the subscriptions
+// and returns the subscriptions that still have accounts left. Subscriptions to all events
+// and requests without params are removed entirely, like the mempool and block subscriptions.
+func (h *streamHub) unsubscribe(subs []*subscription, params []string) ([]*subscription, error) {
+	var accounts map[ton.AccountID]struct{}
+	if len(params) > 0 {
+		values := make([]string, 0, len(params))
+		for _, param := range params {
+			account, _, _ := strings.Cut(param, ";")
+			values = append(values, account)
+		}
+		var err error
+		if accounts, err = parseAccounts(values); err != nil {
+			return nil, err
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var remaining []*subscription
+	for _, sub := range subs {
+		if accounts != nil && sub.accounts != nil {
+			for account := range accounts {
+				delete(sub.accounts, account)
+			}
+			if len(sub.accounts) > 0 {
+				remaining = append(remaining, sub)
+				continue
+			}
+		}
+		delete(h.subscriptions, sub)
+	}
+	return remaining, nil
+}
+
+// websocketSubscription parses the params of a subscribe request.
+func websocketSubscription(stream string, params []string) (*subscription, error) {
+	sub := &subscription{stream: stream}
+	switch stream {
+	case streamTransactions, streamTraces:
+		accounts := make([]string, 0, len(params))
+		for _, param := range params {
+			// operations of "account;operations=..." are not filtered since events do not carry them.
+			account, _, _ := strings.Cut(param, ";")
+			accounts = append(accounts, account)
+		}
+		parsed, err := parseAccounts(accounts)
+		if err != nil {
+			return nil, err
+		}
+		sub.accounts = parsed
+	case streamMempool:
+		for _, param := range params {
+			if value, ok := strings.CutPrefix(param, "accounts="); ok {
+				parsed, err := parseAccounts(strings.Split(value, ","))
+				if err != nil {
+					return nil, err
+				}
+				sub.accounts = parsed
+			}
+		}
+	case streamBlocks:
+		for _, param := range params {
+			if value, ok := strings.CutPrefix(param, "workchain="); ok {
+				workchain, err := strconv.ParseInt(value, 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("invalid workchain %q", value)
+				}
+				sub.workchain = new(int32)
+				*sub.workchain = int32(workchain)
+			}
+		}
+	}
+	return sub, nil
+}
+
+func parseAccounts(values []string) (map[ton.AccountID]struct{}, error) {
+	accounts := make(map[ton.AccountID]struct{}, len(values))
+	for _, value := range values {
+		account, err := ton.ParseAccountID(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account %q: %w", value, err)
+		}
+		accounts[account] = struct{}{}
+	}
+	return accounts, nil
+}
+
+func jsonString(s string) json.RawMessage {
+	data, _ := json.Marshal(s)
+	return data
+}
+
+// Subscriptions returns the number of active SSE connections and websocket subscriptions.
+// Tests usually wait for it to grow before publishing events, since events published earlier are lost.
+func (s *Server) Subscriptions() int {
+	return s.streams.count()
+}
+
+// DropWebsockets closes all websocket connections as if the network failed.
+// Clients can connect again right away.
+func (s *Server) DropWebsockets() {
+	s.streams.dropWebsockets()
+}
+
+// PublishTransaction sends a transaction event to the subscribers of its account
+// and returns the number of subscriptions the event was delivered to.
+func (s *Server) PublishTransaction(event tonapi.TransactionEventData) int {
+	return s.streams.publish(streamTransactions, event, []ton.AccountID{event.AccountID}, 0)
+}
+
+// PublishTrace sends a trace event to the subscribers of any of its accounts
+// and returns the number of subscriptions the event was delivered to.
+func (s *Server) PublishTrace(event tonapi.TraceEventData) int {
+	return s.streams.publish(streamTraces, event, event.AccountIDs, 0)
+}
+
+// PublishMempool sends a mempool event to the subscribers of all messages
+// and to the subscribers of any of its involved accounts.
+// It returns the number of subscriptions the event was delivered to.
+func (s *Server) PublishMempool(event tonapi.MempoolEventData) int {
+	return s.streams.publish(streamMempool, event, event.InvolvedAccounts, 0)
+}
+
+// PublishBlock sends a block event to the subscribers of its workchain
+// and returns the number of subscriptions the event was delivered to.
+func (s *Server) PublishBlock(event tonapi.BlockEventData) int {
+	return s.streams.publish(streamBlocks, event, nil, event.Workchain)
+}
@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Handler returns the REST API managing subscribers and dead letters, see the package documentation.
+// Subscriber secrets are only returned on registration.
+func (r *Relay) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /subscribers", r.handleRegister)
+	mux.HandleFunc("GET /subscribers", r.handleSubscribers)
+	mux.HandleFunc("GET /subscribers/{id}", r.handleSubscriber)
+	mux.HandleFunc("DELETE /subscribers/{id}", r.handleUnregister)
+	mux.HandleFunc("GET /dead-letters", r.handleDeadLetters)
+	mux.HandleFunc("POST /dead-letters/{id}/retry", r.handleRetry)
+	mux.HandleFunc("DELETE /dead-letters/{id}", r.handleDiscard)
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, http.StatusOK, r.Metrics())
+	})
+	if r.apiToken == "" {
+		return mux
+	}
+	expected := []byte("Bearer " + r.apiToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), expected) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		mux.ServeHTTP(w, req)
+	})
+}
+
+func (r *Relay) handleRegister(w http.ResponseWriter, req *http.Request) {
+	var s Subscriber
+	if err := json.NewDecoder(req.Body).Decode(&s); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid subscriber: "+err.Error())
+		return
+	}
+	s.CreatedAt = r.now().UTC()
+	if err := s.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s, err := r.Register(req.Context(), s)
+	switch {
+	case errors.Is(err, ErrConflict):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, s)
+}
+
+func (r *Relay) handleSubscribers(w http.ResponseWriter, req *http.Request) {
+	subscribers, err := r.Subscribers(req.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for i := range subscribers {
+		subscribers[i].Secret = ""
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"subscribers": subscribers})
+}
+
+func (r *Relay) handleSubscriber(w http.ResponseWriter, req *http.Request) {
+	subscribers, err := r.Subscribers(req.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, s := range subscribers {
+		if s.ID == req.PathValue("id") {
+			s.Secret = ""
+			writeJSON(w, http.StatusOK, s)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "subscriber not found")
+}
+
+func (r *Relay) handleUnregister(w http.ResponseWriter, req *http.Request) {
+	writeResult(w, r.Unregister(req.Context(), req.PathValue("id")))
+}
+
+func (r *Relay) handleDeadLetters(w http.ResponseWriter, req *http.Request) {
+	letters, err := r.DeadLetters(req.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"dead_letters": letters})
+}
+
+func (r *Relay) handleRetry(w http.ResponseWriter, req *http.Request) {
+	writeResult(w, r.Retry(req.Context(), req.PathValue("id")))
+}
+
+func (r *Relay) handleDiscard(w http.ResponseWriter, req *http.Request) {
+	writeResult(w, r.Discard(req.Context(), req.PathValue("id")))
+}
+
+// writeResult responds with 204 on success and maps ErrNotFound to 404.
+func writeResult(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError responds with an error in the format used by tonapi.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
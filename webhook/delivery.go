@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+)
+
+const (
+	// SignatureHeader carries the signature of a webhook request, see Sign.
+	SignatureHeader = "X-Tonapi-Signature"
+	// EventIDHeader carries the ID of the delivered event, the same for every subscriber and every attempt.
+	EventIDHeader = "X-Tonapi-Event-Id"
+	// DeliveryIDHeader carries the ID of the delivery, the same for every attempt.
+	DeliveryIDHeader = "X-Tonapi-Delivery-Id"
+	// AttemptHeader carries the number of the delivery attempt starting from 1.
+	AttemptHeader = "X-Tonapi-Delivery-Attempt"
+)
+
+// Delivery is an event addressed to a subscriber.
+// Deliveries that could not be made are kept in the dead-letter store.
+type Delivery struct {
+	ID           string    `json:"id"`
+	SubscriberID string    `json:"subscriber_id"`
+	Event        Event     `json:"event"`
+	Attempts     int       `json:"attempts"`
+	LastError    string    `json:"last_error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newDelivery(subscriberID string, event Event, now time.Time) Delivery {
+	return Delivery{ID: randomID(), SubscriberID: subscriberID, Event: event, CreatedAt: now.UTC()}
+}
+
+// Sign returns the value of SignatureHeader for a request body sent at the given time.
+// The signature has the form "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">",
+// including the time in the signed payload prevents replaying old requests.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + signature(secret, t, body)
+}
+
+// Verify checks the value of SignatureHeader against the request body.
+// Requests signed more than tolerance ago are rejected, zero tolerance disables the check.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var t, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			sig = value
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || sig == "" {
+		return errors.New("malformed signature")
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, t, body))) {
+		return errors.New("signature mismatch")
+	}
+	if tolerance > 0 && time.Since(time.Unix(unix, 0)) > tolerance {
+		return errors.New("signature expired")
+	}
+	return nil
+}
+
+func signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// subscription is a registered subscriber with its delivery queue.
+type subscription struct {
+	subscriber Subscriber
+	queue      chan Delivery
+	done       chan struct{}
+	once       sync.Once
+}
+
+func newSubscription(s Subscriber, queueSize int) *subscription {
+	return &subscription{subscriber: s, queue: make(chan Delivery, queueSize), done: make(chan struct{})}
+}
+
+func (s *subscription) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// deliverLoop delivers queued events one by one to keep them in order.
+// When the relay stops, undelivered events are moved to the dead-letter store.
+func (r *Relay) deliverLoop(ctx context.Context, sub *subscription) {
+	for {
+		select {
+		case <-sub.done:
+			return
+		case <-ctx.Done():
+			r.drain(sub)
+			return
+		case d := <-sub.queue:
+			r.metrics.queued(sub.subscriber.ID, -1)
+			r.deliver(ctx, sub, d)
+		}
+	}
+}
+
+func (r *Relay) drain(sub *subscription) {
+	for {
+		select {
+		case d := <-sub.queue:
+			r.metrics.queued(sub.subscriber.ID, -1)
+			d.LastError = "relay stopped"
+			r.deadLetter(context.Background(), d)
+		default:
+			return
+		}
+	}
+}
+
+// deliver makes delivery attempts until one succeeds, the error is permanent or the attempts are exhausted.
+func (r *Relay) deliver(ctx context.Context, sub *subscription, d Delivery) {
+	backoff := r.minBackoff
+	for {
+		d.Attempts++
+		start := time.Now()
+		err := r.post(ctx, sub.subscriber, d)
+		r.metrics.attempt(ctx, sub.subscriber, time.Since(start), err)
+		if err == nil {
+			return
+		}
+		d.LastError = err.Error()
+		r.logger.Warn("webhook delivery failed", "subscriber", sub.subscriber.ID, "delivery", d.ID, "attempt", d.Attempts, "error", err)
+		var statusErr *statusError
+		if d.Attempts >= r.maxAttempts || (errors.As(err, &statusErr) && statusErr.permanent()) {
+			r.deadLetter(ctx, d)
+			return
+		}
+		select {
+		case <-sub.done:
+			return
+		case <-ctx.Done():
+			r.deadLetter(context.Background(), d)
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, r.maxBackoff)
+	}
+}
+
+func (r *Relay) post(ctx context.Context, s Subscriber, d Delivery) error {
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(s.Secret, r.now(), body))
+	req.Header.Set(EventIDHeader, d.Event.ID)
+	req.Header.Set(DeliveryIDHeader, d.ID)
+	req.Header.Set(AttemptHeader, strconv.Itoa(d.Attempts))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// statusError is returned when a subscriber responds with a non-2xx status code.
+type statusError struct {
+	StatusCode int
+}
+
+func (e *statusError) Error() string {
+	return "unexpected status code " + strconv.Itoa(e.StatusCode)
+}
+
+// permanent reports whether retrying the request is pointless.
+// Client errors other than timeouts and rate limiting mean the subscriber rejects the event.
+func (e *statusError) permanent() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 &&
+		e.StatusCode != http.StatusRequestTimeout && e.StatusCode != http.StatusTooManyRequests
+}
+
+func (r *Relay) deadLetter(ctx context.Context, d Delivery) {
+	r.metrics.deadLettered(ctx, d)
+	if err := r.store.PutDeadLetter(ctx, d); err != nil {
+		r.logger.Error("webhook relay failed to store dead letter", "subscriber", d.SubscriberID, "delivery", d.ID, "error", err)
+	}
+}
+
+// DeadLetters returns the deliveries that could not be made.
+func (r *Relay) DeadLetters(ctx context.Context) ([]Delivery, error) {
+	return r.store.DeadLetters(ctx)
+}
+
+// Retry removes a delivery from the dead-letter store and queues it again with the attempts reset.
+func (r *Relay) Retry(ctx context.Context, id string) error {
+	letters, err := r.store.DeadLetters(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range letters {
+		if d.ID != id {
+			continue
+		}
+		r.subsMu.RLock()
+		sub, ok := r.subscribers[d.SubscriberID]
+		r.subsMu.RUnlock()
+		if !ok {
+			return errors.Wrapf(ErrNotFound, "subscriber %v", d.SubscriberID)
+		}
+		if err := r.store.DeleteDeadLetter(ctx, id); err != nil {
+			return err
+		}
+		d.Attempts, d.LastError = 0, ""
+		r.enqueue(sub, d)
+		return nil
+	}
+	return ErrNotFound
+}
+
+// Discard removes a delivery from the dead-letter store.
+func (r *Relay) Discard(ctx context.Context, id string) error {
+	return r.store.DeleteDeadLetter(ctx, id)
+}
@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+const (
+	meterName = "github.com/tonkeeper/tonapi-go/webhook"
+
+	streamKey     = attribute.Key("tonapi.webhook.stream")
+	subscriberKey = attribute.Key("tonapi.webhook.subscriber")
+	resultKey     = attribute.Key("tonapi.webhook.result")
+)
+
+// Metrics is a snapshot of the relay's counters since it was created.
+type Metrics struct {
+	// Events is the number of events received from tonapi per stream.
+	Events      map[Stream]int64             `json:"events"`
+	Subscribers map[string]SubscriberMetrics `json:"subscribers"`
+}
+
+// SubscriberMetrics describes deliveries to a subscriber.
+type SubscriberMetrics struct {
+	Delivered      int64 `json:"delivered"`
+	FailedAttempts int64 `json:"failed_attempts"`
+	DeadLettered   int64 `json:"dead_lettered"`
+	// Queued is the number of events waiting for delivery.
+	Queued          int64      `json:"queued"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+}
+
+// metrics keeps the counters reported by Relay.Metrics and records them with OpenTelemetry.
+type metrics struct {
+	mu          sync.Mutex
+	events      map[Stream]int64
+	subscribers map[string]*SubscriberMetrics
+
+	received   metric.Int64Counter
+	deliveries metric.Int64Counter
+	duration   metric.Float64Histogram
+	queueSize  metric.Int64UpDownCounter
+}
+
+func newMetrics(provider metric.MeterProvider, logger tonapi.StructuredLogger) *metrics {
+	m := &metrics{events: map[Stream]int64{}, subscribers: map[string]*SubscriberMetrics{}}
+	if err := m.createInstruments(provider.Meter(meterName)); err != nil {
+		logger.Error("failed to create webhook metrics", "error", err)
+		m.createInstruments(noop.Meter{})
+	}
+	return m
+}
+
+func (m *metrics) createInstruments(meter metric.Meter) (err error) {
+	if m.received, err = meter.Int64Counter("tonapi.webhook.events",
+		metric.WithDescription("Number of streaming events received by the webhook relay"),
+		metric.WithUnit("{event}")); err != nil {
+		return err
+	}
+	if m.deliveries, err = meter.Int64Counter("tonapi.webhook.deliveries",
+		metric.WithDescription("Number of webhook delivery attempts by result"),
+		metric.WithUnit("{attempt}")); err != nil {
+		return err
+	}
+	if m.duration, err = meter.Float64Histogram("tonapi.webhook.delivery.duration",
+		metric.WithDescription("Duration of webhook delivery attempts"),
+		metric.WithUnit("ms")); err != nil {
+		return err
+	}
+	if m.queueSize, err = meter.Int64UpDownCounter("tonapi.webhook.queue.size",
+		metric.WithDescription("Number of events waiting for delivery"),
+		metric.WithUnit("{event}")); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *metrics) subscriber(id string) *SubscriberMetrics {
+	s, ok := m.subscribers[id]
+	if !ok {
+		s = &SubscriberMetrics{}
+		m.subscribers[id] = s
+	}
+	return s
+}
+
+func (m *metrics) eventReceived(stream Stream) {
+	m.mu.Lock()
+	m.events[stream]++
+	m.mu.Unlock()
+	m.received.Add(context.Background(), 1, metric.WithAttributes(streamKey.String(string(stream))))
+}
+
+func (m *metrics) queued(id string, delta int64) {
+	m.mu.Lock()
+	m.subscriber(id).Queued += delta
+	m.mu.Unlock()
+	m.queueSize.Add(context.Background(), delta, metric.WithAttributes(subscriberKey.String(id)))
+}
+
+func (m *metrics) attempt(ctx context.Context, s Subscriber, elapsed time.Duration, err error) {
+	result := "delivered"
+	m.mu.Lock()
+	sm := m.subscriber(s.ID)
+	if err == nil {
+		now := time.Now().UTC()
+		sm.Delivered++
+		sm.LastDeliveredAt = &now
+	} else {
+		result = "failed"
+		sm.FailedAttempts++
+		sm.LastError = err.Error()
+	}
+	m.mu.Unlock()
+	attrs := metric.WithAttributes(subscriberKey.String(s.ID), streamKey.String(string(s.Stream)), resultKey.String(result))
+	m.deliveries.Add(ctx, 1, attrs)
+	m.duration.Record(ctx, float64(elapsed)/float64(time.Millisecond), attrs)
+}
+
+func (m *metrics) deadLettered(ctx context.Context, d Delivery) {
+	m.mu.Lock()
+	m.subscriber(d.SubscriberID).DeadLettered++
+	m.mu.Unlock()
+	m.deliveries.Add(ctx, 1, metric.WithAttributes(
+		subscriberKey.String(d.SubscriberID), streamKey.String(string(d.Event.Stream)), resultKey.String("dead_letter")))
+}
+
+// forget drops the counters of a removed subscriber along with its discarded queue.
+func (m *metrics) forget(id string) {
+	m.mu.Lock()
+	s, ok := m.subscribers[id]
+	delete(m.subscribers, id)
+	m.mu.Unlock()
+	if ok && s.Queued != 0 {
+		m.queueSize.Add(context.Background(), -s.Queued, metric.WithAttributes(subscriberKey.String(id)))
+	}
+}
+
+func (m *metrics) snapshot() Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := Metrics{Events: make(map[Stream]int64, len(m.events)), Subscribers: make(map[string]SubscriberMetrics, len(m.subscribers))}
+	for stream, n := range m.events {
+		snapshot.Events[stream] = n
+	}
+	for id, s := range m.subscribers {
+		snapshot.Subscribers[id] = *s
+	}
+	return snapshot
+}
+
+// Metrics returns the relay's counters.
+func (r *Relay) Metrics() Metrics {
+	return r.metrics.snapshot()
+}
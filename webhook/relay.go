@@ -0,0 +1,601 @@
+// Package webhook relays tonapi streaming events to HTTP endpoints.
+//
+// A Relay owns a single websocket connection to tonapi and subscribes it to the union of
+// accounts its subscribers are interested in. Every matching event is delivered to the subscriber
+// as a POST request signed with the subscriber's secret, see Sign and Verify.
+// Failed deliveries are retried with exponential backoff and end up in a dead-letter store
+// once the attempts are exhausted.
+//
+// Subscribers are managed with a REST API served by Relay.Handler:
+//
+//	POST   /subscribers                  register a subscriber, 409 if the ID is taken
+//	GET    /subscribers                  list subscribers
+//	GET    /subscribers/{id}             get a subscriber
+//	DELETE /subscribers/{id}             remove a subscriber
+//	GET    /dead-letters                 list undelivered events
+//	POST   /dead-letters/{id}/retry      deliver an event again
+//	DELETE /dead-letters/{id}            discard an event
+//	GET    /metrics                      delivery counters per subscriber
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-faster/errors"
+	"github.com/tonkeeper/tongo/ton"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/tonkeeper/tonapi-go"
+)
+
+// Stream is a kind of streaming events a subscriber receives.
+type Stream string
+
+const (
+	StreamTransactions Stream = "transactions"
+	StreamTraces       Stream = "traces"
+	StreamMempool      Stream = "mempool"
+	StreamBlocks       Stream = "blocks"
+)
+
+// Subscriber is an HTTP endpoint receiving events of a single stream.
+type Subscriber struct {
+	// ID is generated on registration if empty. IDs are unique, registering a taken one fails.
+	ID string `json:"id"`
+	// URL receives events as POST requests.
+	URL string `json:"url"`
+	// Secret signs the requests. It is generated on registration if empty.
+	Secret string `json:"secret"`
+	Stream Stream `json:"stream"`
+	// Accounts limits transactions, traces and mempool messages to the given accounts.
+	// It is required for transactions and traces and optional for mempool.
+	Accounts []string `json:"accounts,omitempty"`
+	// Workchain limits blocks to the given workchain.
+	Workchain *int      `json:"workchain,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate checks the subscriber is complete and normalizes its accounts to the raw form.
+func (s *Subscriber) Validate() error {
+	if s.URL == "" {
+		return errors.New("url is required")
+	}
+	switch s.Stream {
+	case StreamTransactions, StreamTraces:
+		if len(s.Accounts) == 0 {
+			return errors.Errorf("accounts are required for %v", s.Stream)
+		}
+	case StreamMempool, StreamBlocks:
+	default:
+		return errors.Errorf("unknown stream %q", s.Stream)
+	}
+	if s.Workchain != nil && s.Stream != StreamBlocks {
+		return errors.New("workchain is only supported for blocks")
+	}
+	for i, address := range s.Accounts {
+		account, err := ton.ParseAccountID(address)
+		if err != nil {
+			return errors.Wrapf(err, "invalid account %q", address)
+		}
+		s.Accounts[i] = account.ToRaw()
+	}
+	slices.Sort(s.Accounts)
+	s.Accounts = slices.Compact(s.Accounts)
+	return nil
+}
+
+// Event is the body of a webhook request.
+type Event struct {
+	ID        string          `json:"id"`
+	Stream    Stream          `json:"stream"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Relay delivers streaming events to subscribers.
+type Relay struct {
+	streaming   *tonapi.StreamingAPI
+	store       Store
+	client      *http.Client
+	logger      tonapi.StructuredLogger
+	apiToken    string
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	queueSize   int
+	reconnect   time.Duration
+	now         func() time.Time
+	meter       metric.MeterProvider
+	metrics     *metrics
+
+	// registerMu serializes registrations to check subscriber IDs are unique.
+	registerMu sync.Mutex
+
+	// subsMu protects subscribers. Event handlers only take this lock,
+	// they must not wait for connMu because the websocket holds its own lock while calling them.
+	subsMu      sync.RWMutex
+	subscribers map[string]*subscription
+
+	// connMu protects the websocket connection and what it is subscribed to.
+	connMu sync.Mutex
+	ws     tonapi.Websocket
+	active subscriptionSet
+
+	// resync wakes up the sync goroutine to apply subscriber changes.
+	resync chan struct{}
+	// ctx is the context of Run, nil if the relay is not running. It is protected by subsMu.
+	ctx context.Context
+}
+
+// Option configures a Relay.
+type Option func(*Relay)
+
+// WithStore configures a Relay to keep subscribers and dead letters in the given store instead of memory.
+func WithStore(store Store) Option {
+	return func(r *Relay) {
+		r.store = store
+	}
+}
+
+// WithHTTPClient configures a Relay to deliver events with the given client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Relay) {
+		r.client = client
+	}
+}
+
+// WithLogger configures a Relay to report connection and delivery errors to the given logger.
+func WithLogger(logger tonapi.StructuredLogger) Option {
+	return func(r *Relay) {
+		r.logger = logger
+	}
+}
+
+// WithAPIToken protects the REST API with a bearer token.
+func WithAPIToken(token string) Option {
+	return func(r *Relay) {
+		r.apiToken = token
+	}
+}
+
+// WithRetries configures the number of delivery attempts and the backoff between them.
+// The backoff doubles after every failed attempt up to max. By default, an event is tried
+// 8 times starting with a one second backoff capped at 5 minutes.
+func WithRetries(attempts int, min, max time.Duration) Option {
+	return func(r *Relay) {
+		r.maxAttempts = attempts
+		r.minBackoff = min
+		r.maxBackoff = max
+	}
+}
+
+// WithQueueSize configures the number of events buffered for every subscriber.
+// Events that don't fit the queue go straight to the dead-letter store. The default is 1000.
+func WithQueueSize(size int) Option {
+	return func(r *Relay) {
+		r.queueSize = size
+	}
+}
+
+// WithReconnectDelay configures how long a Relay waits before re-establishing a failed connection.
+// The default is 5 seconds.
+func WithReconnectDelay(delay time.Duration) Option {
+	return func(r *Relay) {
+		r.reconnect = delay
+	}
+}
+
+// WithMeterProvider configures a Relay to record delivery metrics with the given provider.
+// By default, the global provider is used.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(r *Relay) {
+		r.meter = provider
+	}
+}
+
+// NewRelay returns a Relay receiving events from the given streaming API.
+func NewRelay(streaming *tonapi.StreamingAPI, opts ...Option) *Relay {
+	r := &Relay{
+		streaming:   streaming,
+		store:       NewMemoryStore(),
+		client:      &http.Client{Timeout: 10 * time.Second},
+		logger:      noopLogger{},
+		maxAttempts: 8,
+		minBackoff:  time.Second,
+		maxBackoff:  5 * time.Minute,
+		queueSize:   1000,
+		reconnect:   5 * time.Second,
+		now:         time.Now,
+		meter:       otel.GetMeterProvider(),
+		subscribers: map[string]*subscription{},
+		resync:      make(chan struct{}, 1),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	r.metrics = newMetrics(r.meter, r.logger)
+	return r
+}
+
+// Run loads subscribers from the store, starts delivering events and keeps the streaming connection open
+// until the context is canceled.
+func (r *Relay) Run(ctx context.Context) error {
+	subscribers, err := r.store.Subscribers(ctx)
+	if err != nil {
+		return errors.Wrap(err, "load subscribers")
+	}
+	r.subsMu.Lock()
+	r.ctx = ctx
+	for _, s := range subscribers {
+		if _, ok := r.subscribers[s.ID]; !ok {
+			r.subscribers[s.ID] = newSubscription(s, r.queueSize)
+		}
+	}
+	for _, sub := range r.subscribers {
+		go r.deliverLoop(ctx, sub)
+	}
+	r.subsMu.Unlock()
+	defer r.stopSubscriptions()
+	go r.syncLoop(ctx)
+
+	for {
+		err := r.streaming.WebsocketHandleRequests(ctx, r.configure)
+		r.connMu.Lock()
+		r.ws = nil
+		r.active = subscriptionSet{}
+		r.connMu.Unlock()
+		if ctx.Err() != nil {
+			return nil
+		}
+		r.logger.Error("webhook relay connection failed", "error", err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.reconnect):
+		}
+	}
+}
+
+// configure installs event handlers on a new connection and subscribes it to the accounts of subscribers.
+// It returns right away, so WebsocketHandleRequests returns as soon as the connection fails.
+func (r *Relay) configure(ws tonapi.Websocket) error {
+	ws.SetTransactionHandler(func(data tonapi.TransactionEventData) {
+		r.dispatch(StreamTransactions, data, data.AccountID.ToRaw())
+	})
+	ws.SetTraceHandler(func(data tonapi.TraceEventData) {
+		r.dispatch(StreamTraces, data, rawAccounts(data.AccountIDs)...)
+	})
+	ws.SetMempoolHandler(func(data tonapi.MempoolEventData) {
+		r.dispatch(StreamMempool, data, rawAccounts(data.InvolvedAccounts)...)
+	})
+	ws.SetBlockHandler(func(data tonapi.BlockEventData) {
+		r.dispatchBlock(data)
+	})
+	r.connMu.Lock()
+	r.ws = ws
+	r.active = subscriptionSet{}
+	r.connMu.Unlock()
+	return r.sync()
+}
+
+// syncLoop applies subscriber changes to the current connection until the context is done.
+// Changes made while there is no connection are applied by configure once the relay reconnects.
+func (r *Relay) syncLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.resync:
+		}
+		if err := r.sync(); err != nil {
+			r.logger.Error("webhook relay failed to update subscriptions", "error", err)
+		}
+	}
+}
+
+// requestSync asks the sync goroutine to apply subscriber changes.
+func (r *Relay) requestSync() {
+	select {
+	case r.resync <- struct{}{}:
+	default:
+	}
+}
+
+// sync subscribes the connection to new accounts and unsubscribes it from accounts nobody is interested in.
+func (r *Relay) sync() error {
+	wanted := r.wantedSubscriptions()
+	r.connMu.Lock()
+	defer r.connMu.Unlock()
+	if r.ws == nil {
+		return nil
+	}
+	active := &r.active
+	if added := missingKeys(wanted.transactions, active.transactions); len(added) > 0 {
+		if err := r.ws.SubscribeToTransactions(added, nil); err != nil {
+			return err
+		}
+	}
+	if removed := missingKeys(active.transactions, wanted.transactions); len(removed) > 0 {
+		if err := r.ws.UnsubscribeFromTransactions(removed); err != nil {
+			return err
+		}
+	}
+	if added := missingKeys(wanted.traces, active.traces); len(added) > 0 {
+		if err := r.ws.SubscribeToTraces(added); err != nil {
+			return err
+		}
+	}
+	if removed := missingKeys(active.traces, wanted.traces); len(removed) > 0 {
+		if err := r.ws.UnsubscribeFromTraces(removed); err != nil {
+			return err
+		}
+	}
+	if !active.mempool.equal(wanted.mempool) {
+		if active.mempool.enabled {
+			if err := r.ws.UnsubscribeFromMempool(); err != nil {
+				return err
+			}
+		}
+		if wanted.mempool.enabled {
+			if err := r.ws.SubscribeToMempool(wanted.mempool.accounts); err != nil {
+				return err
+			}
+		}
+	}
+	if active.blocks != wanted.blocks {
+		if active.blocks {
+			if err := r.ws.UnsubscribeFromBlocks(); err != nil {
+				return err
+			}
+		} else if err := r.ws.SubscribeToBlocks(nil); err != nil {
+			return err
+		}
+	}
+	r.active = wanted
+	return nil
+}
+
+// subscriptionSet describes what the connection is subscribed to.
+type subscriptionSet struct {
+	transactions map[string]struct{}
+	traces       map[string]struct{}
+	mempool      mempoolSubscription
+	blocks       bool
+}
+
+type mempoolSubscription struct {
+	enabled bool
+	// accounts is nil when all messages are received.
+	accounts []string
+}
+
+func (m mempoolSubscription) equal(other mempoolSubscription) bool {
+	return m.enabled == other.enabled && slices.Equal(m.accounts, other.accounts)
+}
+
+// wantedSubscriptions returns the union of the subscribers' interests.
+// Blocks are received from all workchains and filtered locally.
+func (r *Relay) wantedSubscriptions() subscriptionSet {
+	r.subsMu.RLock()
+	defer r.subsMu.RUnlock()
+	set := subscriptionSet{transactions: map[string]struct{}{}, traces: map[string]struct{}{}}
+	mempoolAll := false
+	for _, sub := range r.subscribers {
+		s := sub.subscriber
+		switch s.Stream {
+		case StreamTransactions:
+			for _, account := range s.Accounts {
+				set.transactions[account] = struct{}{}
+			}
+		case StreamTraces:
+			for _, account := range s.Accounts {
+				set.traces[account] = struct{}{}
+			}
+		case StreamMempool:
+			set.mempool.enabled = true
+			if len(s.Accounts) == 0 {
+				mempoolAll = true
+			}
+			set.mempool.accounts = append(set.mempool.accounts, s.Accounts...)
+		case StreamBlocks:
+			set.blocks = true
+		}
+	}
+	if mempoolAll {
+		set.mempool.accounts = nil
+	} else {
+		slices.Sort(set.mempool.accounts)
+		set.mempool.accounts = slices.Compact(set.mempool.accounts)
+	}
+	return set
+}
+
+// missingKeys returns the keys of a that are not in b.
+func missingKeys(a, b map[string]struct{}) []string {
+	var keys []string
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Register validates and stores a subscriber and starts delivering events to it.
+// The subscriber's ID and secret are generated if empty.
+// If a subscriber with the given ID already exists, ErrConflict is returned.
+func (r *Relay) Register(ctx context.Context, s Subscriber) (Subscriber, error) {
+	if err := s.Validate(); err != nil {
+		return Subscriber{}, err
+	}
+	if s.ID == "" {
+		s.ID = randomID()
+	}
+	if s.Secret == "" {
+		s.Secret = randomID()
+	}
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = r.now().UTC()
+	}
+	r.registerMu.Lock()
+	defer r.registerMu.Unlock()
+	subscribers, err := r.store.Subscribers(ctx)
+	if err != nil {
+		return Subscriber{}, err
+	}
+	for _, existing := range subscribers {
+		if existing.ID == s.ID {
+			return Subscriber{}, errors.Wrapf(ErrConflict, "subscriber %v", s.ID)
+		}
+	}
+	if err := r.store.PutSubscriber(ctx, s); err != nil {
+		return Subscriber{}, err
+	}
+	r.subsMu.Lock()
+	r.startSubscription(s)
+	r.subsMu.Unlock()
+	r.requestSync()
+	return s, nil
+}
+
+// Unregister removes a subscriber. Events queued for it are discarded.
+func (r *Relay) Unregister(ctx context.Context, id string) error {
+	if err := r.store.DeleteSubscriber(ctx, id); err != nil {
+		return err
+	}
+	r.subsMu.Lock()
+	if sub, ok := r.subscribers[id]; ok {
+		sub.stop()
+		delete(r.subscribers, id)
+	}
+	r.subsMu.Unlock()
+	r.metrics.forget(id)
+	r.requestSync()
+	return nil
+}
+
+// Subscribers returns the registered subscribers.
+func (r *Relay) Subscribers(ctx context.Context) ([]Subscriber, error) {
+	return r.store.Subscribers(ctx)
+}
+
+// startSubscription adds a subscriber and starts its delivery worker. subsMu must be held.
+func (r *Relay) startSubscription(s Subscriber) {
+	sub := newSubscription(s, r.queueSize)
+	r.subscribers[s.ID] = sub
+	if r.ctx != nil {
+		go r.deliverLoop(r.ctx, sub)
+	}
+}
+
+func (r *Relay) stopSubscriptions() {
+	r.subsMu.Lock()
+	defer r.subsMu.Unlock()
+	for _, sub := range r.subscribers {
+		sub.stop()
+	}
+	r.ctx = nil
+}
+
+// dispatch queues an event for every subscriber of the stream interested in one of the given accounts.
+func (r *Relay) dispatch(stream Stream, data any, accounts ...string) {
+	r.metrics.eventReceived(stream)
+	r.subsMu.RLock()
+	defer r.subsMu.RUnlock()
+	var event *Event
+	for _, sub := range r.subscribers {
+		s := sub.subscriber
+		if s.Stream != stream {
+			continue
+		}
+		if len(s.Accounts) > 0 && !containsAny(s.Accounts, accounts) {
+			continue
+		}
+		if event == nil {
+			if event = r.newEvent(stream, data); event == nil {
+				return
+			}
+		}
+		r.enqueue(sub, newDelivery(s.ID, *event, r.now()))
+	}
+}
+
+func (r *Relay) dispatchBlock(data tonapi.BlockEventData) {
+	r.metrics.eventReceived(StreamBlocks)
+	r.subsMu.RLock()
+	defer r.subsMu.RUnlock()
+	var event *Event
+	for _, sub := range r.subscribers {
+		s := sub.subscriber
+		if s.Stream != StreamBlocks || (s.Workchain != nil && int32(*s.Workchain) != data.Workchain) {
+			continue
+		}
+		if event == nil {
+			if event = r.newEvent(StreamBlocks, data); event == nil {
+				return
+			}
+		}
+		r.enqueue(sub, newDelivery(s.ID, *event, r.now()))
+	}
+}
+
+func (r *Relay) newEvent(stream Stream, data any) *Event {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		r.logger.Error("webhook relay failed to encode event", "stream", stream, "error", err)
+		return nil
+	}
+	return &Event{ID: randomID(), Stream: stream, CreatedAt: r.now().UTC(), Data: payload}
+}
+
+// enqueue queues a delivery without blocking, a full queue sends the delivery to the dead-letter store.
+func (r *Relay) enqueue(sub *subscription, d Delivery) {
+	select {
+	case sub.queue <- d:
+		r.metrics.queued(sub.subscriber.ID, 1)
+	default:
+		d.LastError = "queue is full"
+		r.deadLetter(context.Background(), d)
+	}
+}
+
+func rawAccounts(accounts []ton.AccountID) []string {
+	raw := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		raw = append(raw, account.ToRaw())
+	}
+	return raw
+}
+
+func containsAny(sorted []string, values []string) bool {
+	for _, v := range values {
+		if _, ok := slices.BinarySearch(sorted, v); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func randomID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, args ...any) {}
+func (noopLogger) Info(msg string, args ...any)  {}
+func (noopLogger) Warn(msg string, args ...any)  {}
+func (noopLogger) Error(msg string, args ...any) {}
@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tonkeeper/tongo/ton"
+
+	"github.com/tonkeeper/tonapi-go"
+	"github.com/tonkeeper/tonapi-go/tonapitest"
+)
+
+// receiver records webhook requests and responds with the queued status codes, then with 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, receivedRequest{header: r.Header, body: body})
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) respond(statuses ...int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.statuses = append(rc.statuses, statuses...)
+}
+
+func (rc *receiver) received() []receivedRequest {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]receivedRequest(nil), rc.requests...)
+}
+
+func TestRelay(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	srv := tonapitest.NewServer(t)
+	hooks := &receiver{}
+	hookServer := httptest.NewServer(hooks)
+	defer hookServer.Close()
+
+	relay := NewRelay(srv.Streaming(), WithAPIToken("secret-token"), WithRetries(3, 10*time.Millisecond, 20*time.Millisecond))
+	api := httptest.NewServer(relay.Handler())
+	defer api.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go relay.Run(ctx)
+
+	call := func(method, path string, body any, out any) int {
+		var reader io.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		}
+		req, err := http.NewRequest(method, api.URL+path, reader)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret-token")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if out != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		}
+		return resp.StatusCode
+	}
+
+	resp, err := http.Post(api.URL+"/subscribers", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/subscribers", Subscriber{URL: hookServer.URL, Stream: StreamTraces}, nil))
+
+	var txSubscriber Subscriber
+	require.Equal(t, http.StatusCreated, call(http.MethodPost, "/subscribers",
+		Subscriber{URL: hookServer.URL + "/tx", Stream: StreamTransactions, Accounts: []string{account.ToHuman(true, false)}}, &txSubscriber))
+	require.NotEmpty(t, txSubscriber.Secret)
+	require.Equal(t, []string{account.ToRaw()}, txSubscriber.Accounts)
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 1 }, 5*time.Second, 10*time.Millisecond)
+	// a taken ID doesn't replace the subscriber.
+	require.Equal(t, http.StatusConflict, call(http.MethodPost, "/subscribers",
+		Subscriber{ID: txSubscriber.ID, URL: hookServer.URL + "/other", Stream: StreamBlocks}, nil))
+
+	// the first attempt fails with a retryable status.
+	hooks.respond(http.StatusServiceUnavailable)
+	require.Equal(t, 1, srv.PublishTransaction(tonapi.TransactionEventData{AccountID: account, Lt: 7, TxHash: "ab"}))
+	require.Eventually(t, func() bool { return len(hooks.received()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	delivered := hooks.received()[1]
+	require.Equal(t, "2", delivered.header.Get(AttemptHeader))
+	require.Equal(t, hooks.received()[0].header.Get(DeliveryIDHeader), delivered.header.Get(DeliveryIDHeader))
+	require.NoError(t, Verify(txSubscriber.Secret, delivered.header.Get(SignatureHeader), delivered.body, time.Minute))
+	var event Event
+	require.NoError(t, json.Unmarshal(delivered.body, &event))
+	require.Equal(t, StreamTransactions, event.Stream)
+	require.JSONEq(t, `{"account_id":"`+account.ToRaw()+`","lt":7,"tx_hash":"ab"}`, string(event.Data))
+
+	// a client error is permanent and the delivery goes to the dead-letter store.
+	workchain := 0
+	var blockSubscriber Subscriber
+	require.Equal(t, http.StatusCreated, call(http.MethodPost, "/subscribers",
+		Subscriber{URL: hookServer.URL + "/blocks", Stream: StreamBlocks, Workchain: &workchain}, &blockSubscriber))
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 2 }, 5*time.Second, 10*time.Millisecond)
+	hooks.respond(http.StatusBadRequest)
+	srv.PublishBlock(tonapi.BlockEventData{Workchain: -1, Seqno: 1})
+	srv.PublishBlock(tonapi.BlockEventData{Workchain: 0, Seqno: 2})
+	var letters struct {
+		DeadLetters []Delivery `json:"dead_letters"`
+	}
+	require.Eventually(t, func() bool {
+		call(http.MethodGet, "/dead-letters", nil, &letters)
+		return len(letters.DeadLetters) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	letter := letters.DeadLetters[0]
+	require.Equal(t, blockSubscriber.ID, letter.SubscriberID)
+	require.Equal(t, 1, letter.Attempts)
+	require.Equal(t, "unexpected status code 400", letter.LastError)
+
+	require.Equal(t, http.StatusNoContent, call(http.MethodPost, "/dead-letters/"+letter.ID+"/retry", nil, nil))
+	require.Eventually(t, func() bool { return len(hooks.received()) == 4 }, 5*time.Second, 10*time.Millisecond)
+	require.JSONEq(t, `{"workchain":0,"shard":"","seqno":2,"root_hash":"","file_hash":""}`, string(decodeEvent(t, hooks.received()[3].body).Data))
+	require.Equal(t, http.StatusNotFound, call(http.MethodPost, "/dead-letters/"+letter.ID+"/retry", nil, nil))
+
+	var metrics Metrics
+	require.Equal(t, http.StatusOK, call(http.MethodGet, "/metrics", nil, &metrics))
+	require.Equal(t, int64(1), metrics.Events[StreamTransactions])
+	require.Equal(t, int64(2), metrics.Events[StreamBlocks])
+	require.Equal(t, SubscriberMetrics{Delivered: 1, FailedAttempts: 1, LastError: "unexpected status code 503"},
+		withoutTime(metrics.Subscribers[txSubscriber.ID]))
+	require.Equal(t, SubscriberMetrics{Delivered: 1, FailedAttempts: 1, DeadLettered: 1, LastError: "unexpected status code 400"},
+		withoutTime(metrics.Subscribers[blockSubscriber.ID]))
+
+	var listed struct {
+		Subscribers []Subscriber `json:"subscribers"`
+	}
+	require.Equal(t, http.StatusOK, call(http.MethodGet, "/subscribers", nil, &listed))
+	require.Len(t, listed.Subscribers, 2)
+	require.Empty(t, listed.Subscribers[0].Secret)
+	require.Equal(t, hookServer.URL+"/tx", listed.Subscribers[0].URL)
+
+	require.Equal(t, http.StatusNoContent, call(http.MethodDelete, "/subscribers/"+txSubscriber.ID, nil, nil))
+	require.Equal(t, http.StatusNotFound, call(http.MethodDelete, "/subscribers/"+txSubscriber.ID, nil, nil))
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRelayReconnect(t *testing.T) {
+	account := ton.MustParseAccountID("0:1111111111111111111111111111111111111111111111111111111111111111")
+	srv := tonapitest.NewServer(t)
+	hooks := &receiver{}
+	hookServer := httptest.NewServer(hooks)
+	defer hookServer.Close()
+
+	store := NewMemoryStore()
+	require.NoError(t, store.PutSubscriber(context.Background(), Subscriber{
+		ID: "tx", URL: hookServer.URL, Secret: "secret", Stream: StreamTransactions, Accounts: []string{account.ToRaw()},
+	}))
+	relay := NewRelay(srv.Streaming(), WithStore(store), WithReconnectDelay(10*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, srv.PublishTransaction(tonapi.TransactionEventData{AccountID: account, Lt: 1, TxHash: "aa"}))
+	require.Eventually(t, func() bool { return len(hooks.received()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// the relay notices the dropped connection, reconnects and subscribes again.
+	srv.DropWebsockets()
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 0 }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return srv.Subscriptions() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, srv.PublishTransaction(tonapi.TransactionEventData{AccountID: account, Lt: 2, TxHash: "bb"}))
+	require.Eventually(t, func() bool { return len(hooks.received()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.JSONEq(t, `{"account_id":"`+account.ToRaw()+`","lt":2,"tx_hash":"bb"}`, string(decodeEvent(t, hooks.received()[1].body).Data))
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run didn't return after the context was canceled")
+	}
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	header := Sign("secret", time.Now(), body)
+	require.NoError(t, Verify("secret", header, body, time.Minute))
+	require.EqualError(t, Verify("other", header, body, time.Minute), "signature mismatch")
+	require.EqualError(t, Verify("secret", header, []byte(`{"id":"2"}`), time.Minute), "signature mismatch")
+	require.EqualError(t, Verify("secret", "v1=abc", body, time.Minute), "malformed signature")
+
+	old := Sign("secret", time.Now().Add(-time.Hour), body)
+	require.EqualError(t, Verify("secret", old, body, time.Minute), "signature expired")
+	require.NoError(t, Verify("secret", old, body, 0))
+}
+
+func decodeEvent(t *testing.T, body []byte) Event {
+	var event Event
+	require.NoError(t, json.Unmarshal(body, &event))
+	return event
+}
+
+func withoutTime(m SubscriberMetrics) SubscriberMetrics {
+	m.LastDeliveredAt = nil
+	return m
+}
@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/go-faster/errors"
+)
+
+var (
+	// ErrNotFound is returned by a Store when a subscriber or a dead letter does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned by Relay.Register when a subscriber with the same ID already exists.
+	ErrConflict = errors.New("already exists")
+)
+
+// Store persists subscribers and undeliverable events of a Relay.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// PutSubscriber creates or replaces a subscriber.
+	PutSubscriber(ctx context.Context, s Subscriber) error
+	// DeleteSubscriber removes a subscriber, returning ErrNotFound if it does not exist.
+	DeleteSubscriber(ctx context.Context, id string) error
+	// Subscribers returns all subscribers.
+	Subscribers(ctx context.Context) ([]Subscriber, error)
+
+	// PutDeadLetter stores an event that could not be delivered.
+	PutDeadLetter(ctx context.Context, d Delivery) error
+	// DeleteDeadLetter removes a dead letter, returning ErrNotFound if it does not exist.
+	DeleteDeadLetter(ctx context.Context, id string) error
+	// DeadLetters returns all dead letters ordered by creation time.
+	DeadLetters(ctx context.Context) ([]Delivery, error)
+}
+
+// MemoryStore is an in-memory Store.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	subscribers map[string]Subscriber
+	deadLetters map[string]Delivery
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		subscribers: map[string]Subscriber{},
+		deadLetters: map[string]Delivery{},
+	}
+}
+
+func (s *MemoryStore) PutSubscriber(ctx context.Context, subscriber Subscriber) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[subscriber.ID] = subscriber
+	return nil
+}
+
+func (s *MemoryStore) DeleteSubscriber(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.subscribers, id)
+	return nil
+}
+
+func (s *MemoryStore) Subscribers(ctx context.Context) ([]Subscriber, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subscribers := make([]Subscriber, 0, len(s.subscribers))
+	for _, subscriber := range s.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	sort.Slice(subscribers, func(i, j int) bool { return subscribers[i].CreatedAt.Before(subscribers[j].CreatedAt) })
+	return subscribers, nil
+}
+
+func (s *MemoryStore) PutDeadLetter(ctx context.Context, d Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadLetters[d.ID] = d
+	return nil
+}
+
+func (s *MemoryStore) DeleteDeadLetter(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.deadLetters[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.deadLetters, id)
+	return nil
+}
+
+func (s *MemoryStore) DeadLetters(ctx context.Context) ([]Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	letters := make([]Delivery, 0, len(s.deadLetters))
+	for _, d := range s.deadLetters {
+		letters = append(letters, d)
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].CreatedAt.Before(letters[j].CreatedAt) })
+	return letters, nil
+}
+
+// FileStore is a Store keeping its state in memory and persisting it to a JSON file after every change,
+// so subscribers and dead letters survive restarts.
+type FileStore struct {
+	path string
+	// mu serializes changes with writing the file.
+	mu     sync.Mutex
+	memory *MemoryStore
+}
+
+type fileStoreState struct {
+	Subscribers []Subscriber `json:"subscribers"`
+	DeadLetters []Delivery   `json:"dead_letters"`
+}
+
+// NewFileStore returns a FileStore persisted to the given file, loading its state if the file exists.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, memory: NewMemoryStore()}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state fileStoreState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, errors.Wrapf(err, "decode %v", path)
+	}
+	for _, subscriber := range state.Subscribers {
+		s.memory.subscribers[subscriber.ID] = subscriber
+	}
+	for _, d := range state.DeadLetters {
+		s.memory.deadLetters[d.ID] = d
+	}
+	return s, nil
+}
+
+func (s *FileStore) PutSubscriber(ctx context.Context, subscriber Subscriber) error {
+	return s.update(ctx, func() error { return s.memory.PutSubscriber(ctx, subscriber) })
+}
+
+func (s *FileStore) DeleteSubscriber(ctx context.Context, id string) error {
+	return s.update(ctx, func() error { return s.memory.DeleteSubscriber(ctx, id) })
+}
+
+func (s *FileStore) Subscribers(ctx context.Context) ([]Subscriber, error) {
+	return s.memory.Subscribers(ctx)
+}
+
+func (s *FileStore) PutDeadLetter(ctx context.Context, d Delivery) error {
+	return s.update(ctx, func() error { return s.memory.PutDeadLetter(ctx, d) })
+}
+
+func (s *FileStore) DeleteDeadLetter(ctx context.Context, id string) error {
+	return s.update(ctx, func() error { return s.memory.DeleteDeadLetter(ctx, id) })
+}
+
+func (s *FileStore) DeadLetters(ctx context.Context) ([]Delivery, error) {
+	return s.memory.DeadLetters(ctx)
+}
+
+// update applies a change in memory and writes the state to a temporary file replacing the store file,
+// so the file is never left half-written.
+func (s *FileStore) update(ctx context.Context, change func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := change(); err != nil {
+		return err
+	}
+	var state fileStoreState
+	state.Subscribers, _ = s.memory.Subscribers(ctx)
+	state.DeadLetters, _ = s.memory.DeadLetters(ctx)
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
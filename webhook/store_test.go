@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhook.json")
+	ctx := context.Background()
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+	now := time.Now().UTC().Truncate(time.Second)
+	subscriber := Subscriber{ID: "1", URL: "https://example.com/hook", Secret: "s", Stream: StreamBlocks, CreatedAt: now}
+	require.NoError(t, store.PutSubscriber(ctx, subscriber))
+	require.NoError(t, store.PutSubscriber(ctx, Subscriber{ID: "2", Stream: StreamBlocks, CreatedAt: now.Add(time.Second)}))
+	require.NoError(t, store.DeleteSubscriber(ctx, "2"))
+	require.ErrorIs(t, store.DeleteSubscriber(ctx, "2"), ErrNotFound)
+	letter := Delivery{ID: "d", SubscriberID: "1", Event: Event{ID: "e", Stream: StreamBlocks, CreatedAt: now, Data: []byte(`{"seqno":1}`)}, Attempts: 3, CreatedAt: now}
+	require.NoError(t, store.PutDeadLetter(ctx, letter))
+
+	// the state survives a restart.
+	store, err = NewFileStore(path)
+	require.NoError(t, err)
+	subscribers, err := store.Subscribers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Subscriber{subscriber}, subscribers)
+	letters, err := store.DeadLetters(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Delivery{letter}, letters)
+	require.NoError(t, store.DeleteDeadLetter(ctx, "d"))
+	store, err = NewFileStore(path)
+	require.NoError(t, err)
+	letters, err = store.DeadLetters(ctx)
+	require.NoError(t, err)
+	require.Empty(t, letters)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = NewFileStore(path)
+	require.Error(t, err)
+}
@@ -39,6 +39,8 @@ type websocketConnection struct {
 	transactionHandler TransactionHandler
 	traceHandler       TraceHandler
 	blockHandler       BlockHandler
+	telemetry          *streamingTelemetry
+	logger             StructuredLogger
 }
 
 func (w *websocketConnection) SubscribeToTransactions(accounts []string, operations []string) error {
@@ -51,30 +53,22 @@ func (w *websocketConnection) SubscribeToTransactions(accounts []string, operati
 		}
 	}
 	request := JsonRPCRequest{ID: w.currentRequestID(), JSONRPC: "2.0", Method: "subscribe_account", Params: params}
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.conn.WriteJSON(request)
+	return w.writeRequest(request)
 }
 
 func (w *websocketConnection) UnsubscribeFromTransactions(accounts []string) error {
 	request := JsonRPCRequest{ID: w.currentRequestID(), JSONRPC: "2.0", Method: "unsubscribe_account", Params: accounts}
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.conn.WriteJSON(request)
+	return w.writeRequest(request)
 }
 
 func (w *websocketConnection) SubscribeToTraces(accounts []string) error {
 	request := JsonRPCRequest{ID: w.currentRequestID(), JSONRPC: "2.0", Method: "subscribe_trace", Params: accounts}
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.conn.WriteJSON(request)
+	return w.writeRequest(request)
 }
 
 func (w *websocketConnection) UnsubscribeFromTraces(accounts []string) error {
 	request := JsonRPCRequest{ID: w.currentRequestID(), JSONRPC: "2.0", Method: "unsubscribe_trace", Params: accounts}
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.conn.WriteJSON(request)
+	return w.writeRequest(request)
 }
 
 func (w *websocketConnection) SubscribeToMempool(accounts []string) error {
@@ -84,16 +78,12 @@ func (w *websocketConnection) SubscribeToMempool(accounts []string) error {
 			fmt.Sprintf("accounts=%s", strings.Join(accounts, ",")),
 		}
 	}
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.conn.WriteJSON(request)
+	return w.writeRequest(request)
 }
 
 func (w *websocketConnection) UnsubscribeFromMempool() error {
 	request := JsonRPCRequest{ID: w.currentRequestID(), JSONRPC: "2.0", Method: "unsubscribe_mempool"}
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.conn.WriteJSON(request)
+	return w.writeRequest(request)
 }
 
 func (w *websocketConnection) SubscribeToBlocks(workchain *int) error {
@@ -103,16 +93,12 @@ func (w *websocketConnection) SubscribeToBlocks(workchain *int) error {
 			fmt.Sprintf("workchain=%d", *workchain),
 		}
 	}
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.conn.WriteJSON(request)
+	return w.writeRequest(request)
 }
 
 func (w *websocketConnection) UnsubscribeFromBlocks() error {
 	request := JsonRPCRequest{ID: w.currentRequestID(), JSONRPC: "2.0", Method: "unsubscribe_block"}
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.conn.WriteJSON(request)
+	return w.writeRequest(request)
 }
 
 func (w *websocketConnection) SetMempoolHandler(handler MempoolHandler) {
@@ -164,6 +150,7 @@ func websocketConnect(ctx context.Context, endpoint string, apiKey string) (*web
 		transactionHandler: func(data TransactionEventData) {},
 		traceHandler:       func(data TraceEventData) {},
 		blockHandler:       func(data BlockEventData) {},
+		logger:             noopLogger{},
 	}, nil
 }
 
@@ -174,10 +161,17 @@ func (w *websocketConnection) runJsonRPC(ctx context.Context, fn WebsocketConfig
 	g.Go(func() error {
 		return fn(w)
 	})
+	g.Go(func() error {
+		// unblock the reader once the context is done or the configurator fails.
+		<-ctx.Done()
+		w.conn.Close()
+		return nil
+	})
 	g.Go(func() error {
 		for {
 			_, msg, err := w.conn.ReadMessage()
 			if err != nil {
+				w.logger.Error("websocket connection failed", "error", err)
 				return err
 			}
 			if ctx.Err() != nil {
@@ -185,47 +179,65 @@ func (w *websocketConnection) runJsonRPC(ctx context.Context, fn WebsocketConfig
 			}
 			var response JsonRPCResponse
 			if err := json.Unmarshal(msg, &response); err != nil {
+				w.logger.Error("websocket connection received invalid message", "error", err)
+				w.telemetry.drop(ctx, transportWebsocket, "jsonrpc", dropDecodeError)
 				return err
 			}
 			switch response.Method {
+			case "":
+				w.logger.Debug("websocket response", "id", response.ID, "result", string(response.Result))
 			case "trace":
 				var traceEvent TraceEventData
 				if err := json.Unmarshal(response.Params, &traceEvent); err != nil {
+					w.telemetry.drop(ctx, transportWebsocket, "traces", dropDecodeError)
 					return err
 				}
-				w.processHandler(func() {
+				w.processHandler(ctx, "traces", func() {
 					w.traceHandler(traceEvent)
 				})
 			case "account_transaction":
 				var txEvent TransactionEventData
 				if err := json.Unmarshal(response.Params, &txEvent); err != nil {
+					w.telemetry.drop(ctx, transportWebsocket, "transactions", dropDecodeError)
 					return err
 				}
-				w.processHandler(func() {
+				w.processHandler(ctx, "transactions", func() {
 					w.transactionHandler(txEvent)
 				})
 			case "mempool_message":
 				var mempoolEvent MempoolEventData
 				if err := json.Unmarshal(response.Params, &mempoolEvent); err != nil {
+					w.telemetry.drop(ctx, transportWebsocket, "mempool", dropDecodeError)
 					return err
 				}
-				w.processHandler(func() {
+				w.processHandler(ctx, "mempool", func() {
 					w.mempoolHandler(mempoolEvent)
 				})
 			case "block":
 				var block BlockEventData
 				if err := json.Unmarshal(response.Params, &block); err != nil {
+					w.telemetry.drop(ctx, transportWebsocket, "blocks", dropDecodeError)
 					return err
 				}
-				w.processHandler(func() {
+				w.processHandler(ctx, "blocks", func() {
 					w.blockHandler(block)
 				})
+			default:
+				w.logger.Debug("websocket connection received unknown method", "method", response.Method)
+				w.telemetry.drop(ctx, transportWebsocket, response.Method, dropUnknownEvent)
 			}
 		}
 	})
 	return g.Wait()
 }
 
+func (w *websocketConnection) writeRequest(request JsonRPCRequest) error {
+	w.logger.Debug("websocket request", "id", request.ID, "method", request.Method, "params", request.Params)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn.WriteJSON(request)
+}
+
 func (w *websocketConnection) currentRequestID() uint64 {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -233,8 +245,11 @@ func (w *websocketConnection) currentRequestID() uint64 {
 	return w.requestID
 }
 
-func (w *websocketConnection) processHandler(fn func()) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	fn()
+func (w *websocketConnection) processHandler(ctx context.Context, stream string, fn func()) {
+	w.telemetry.handle(ctx, transportWebsocket, stream, func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		fn()
+		return nil
+	})
 }